@@ -0,0 +1,415 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/currency"
+	"github.com/jondkelley/cicd_golang_calculator/internal/expr"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+// isCommand reports whether line is a REPL meta-command such as :history
+func isCommand(line string) bool {
+	return strings.HasPrefix(line, ":")
+}
+
+// runCommand executes a REPL meta-command, writing its output to w
+func runCommand(s *session, line string, w io.Writer) error {
+	fields := strings.Fields(strings.TrimPrefix(line, ":"))
+	if len(fields) == 0 {
+		return fmt.Errorf("missing command name after ':'")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "history":
+		printHistory(s.env.History(), w)
+		return nil
+	case "functions":
+		printFunctions(s.env, w)
+		return nil
+	case "depth":
+		return depthCommand(s.env, fields[1:], w)
+	case "constants":
+		printConstants(w)
+		return nil
+	case "units":
+		return unitsCommand(fields[1:], w)
+	case "rates":
+		return ratesCommand(s, fields[1:], w)
+	case "tz":
+		return tzCommand(s.env, fields[1:], w)
+	case "mode":
+		return modeCommand(s.calc, fields[1:], w)
+	case "precision":
+		return precisionCommand(s.calc, fields[1:], w)
+	case "rounding":
+		return roundingCommand(s.calc, fields[1:], w)
+	case "rational":
+		return rationalCommand(s, fields[1:], w)
+	case "int":
+		return intCommand(s.calc, fields[1:], w)
+	case "overflow":
+		return overflowCommand(s.calc, fields[1:], w)
+	case "ieee":
+		return ieeeCommand(s.calc, fields[1:], w)
+	case "format":
+		return formatCommand(s, fields[1:], w)
+	case "complex":
+		return complexCommand(s.calc, fields[1:], w)
+	default:
+		return fmt.Errorf("unknown command :%s", fields[0])
+	}
+}
+
+// printHistory lists every recorded result with the index usable as $N
+func printHistory(history *expr.History, w io.Writer) {
+	entries := history.Entries()
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No history yet.")
+		return
+	}
+	for i, entry := range entries {
+		fmt.Fprintf(w, "$%d: %s = %s\n", i+1, entry.Source, entry.Value)
+	}
+}
+
+// printConstants lists the built-in constants with their values, units and descriptions
+func printConstants(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range expr.Constants() {
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%s\n", c.Name, c.Value, c.Unit, c.Description)
+	}
+	tw.Flush()
+}
+
+// unitsCommand lists the built-in units, or describes the units given as arguments
+// in SI base units
+func unitsCommand(args []string, w io.Writer) error {
+	if len(args) > 0 {
+		for _, arg := range args {
+			u, err := units.Parse(arg)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s = %v %s\n", u, u.Factor, u.Dim)
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, info := range units.Units() {
+		prefix := ""
+		if info.Prefixable {
+			prefix = "prefixable"
+		}
+		fmt.Fprintf(tw, "%s\t%v %s\t%s\t%s\n", strings.Join(info.Names, ", "), info.Unit.Factor, info.Unit.Dim, prefix, info.Description)
+	}
+	tw.Flush()
+	return nil
+}
+
+// ratesCommand shows the loaded exchange rates or loads them from a file or URL
+func ratesCommand(s *session, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		if s.rates == nil {
+			fmt.Fprintln(w, "No exchange rates loaded. Use :rates FILE or start with --rates FILE.")
+			return nil
+		}
+		fmt.Fprintf(w, "%d exchange rates against %s as of %s (%s old)\n", len(s.rates.Rates), s.rates.Base, s.rates.Date.Format(currency.DateLayout), s.rateAge())
+		fmt.Fprintf(w, "Currencies: %s\n", strings.Join(units.Currencies(), " "))
+		return nil
+	case 1:
+		return s.loadRates(currency.NewLoader(args[0]), w)
+	default:
+		return fmt.Errorf("usage: :rates [file|url]")
+	}
+}
+
+// tzCommand shows or sets the time zone in which dates and times are read and shown
+func tzCommand(env *expr.Env, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		fmt.Fprintf(w, "Time zone: %s\n", env.Location())
+		return nil
+	case 1:
+		loc, err := time.LoadLocation(args[0])
+		if err != nil {
+			return fmt.Errorf("unknown time zone %q", args[0])
+		}
+		env.SetLocation(loc)
+		fmt.Fprintf(w, "Time zone set to %s\n", loc)
+		return nil
+	default:
+		return fmt.Errorf("usage: :tz [zone]")
+	}
+}
+
+// printFunctions lists the user-defined functions of the session
+func printFunctions(env *expr.Env, w io.Writer) {
+	funcs := env.Funcs()
+	if len(funcs) == 0 {
+		fmt.Fprintln(w, "No functions defined.")
+		return
+	}
+	for _, fn := range funcs {
+		fmt.Fprintln(w, fn)
+	}
+}
+
+// depthCommand shows or sets the maximum nesting depth of user function calls
+func depthCommand(env *expr.Env, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		fmt.Fprintf(w, "Maximum call depth: %d\n", env.MaxDepth())
+		return nil
+	case 1:
+		depth, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid depth %q", args[0])
+		}
+		if err := env.SetMaxDepth(depth); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Maximum call depth set to %d\n", depth)
+		return nil
+	default:
+		return fmt.Errorf("usage: :depth [n]")
+	}
+}
+
+// modeCommand shows or sets the angle mode used by trigonometric functions
+func modeCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		fmt.Fprintf(w, "Angle mode: %s\n", calc.AngleMode())
+		return nil
+	case 1:
+		mode, err := calculator.ParseAngleMode(args[0])
+		if err != nil {
+			return err
+		}
+		calc.SetAngleMode(mode)
+		fmt.Fprintf(w, "Angle mode set to %s\n", mode)
+		return nil
+	default:
+		return fmt.Errorf("usage: :mode deg|rad|grad")
+	}
+}
+
+// precisionCommand shows or sets the number of significant digits used for
+// arbitrary precision arithmetic; "off" returns to float64
+func precisionCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		if calc.Precision() == 0 {
+			fmt.Fprintln(w, "Precision: float64")
+		} else {
+			fmt.Fprintf(w, "Precision: %d digits (%d bits)\n", calculator.DigitsForBits(calc.Precision()), calc.Precision())
+		}
+		return nil
+	case 1:
+		if strings.EqualFold(args[0], "off") {
+			calc.SetPrecision(0)
+			fmt.Fprintln(w, "Precision set to float64")
+			return nil
+		}
+		digits, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid precision %q", args[0])
+		}
+		if err := calculator.ValidatePrecision(digits); err != nil {
+			return err
+		}
+		calc.SetPrecision(calculator.BitsForDigits(digits))
+		fmt.Fprintf(w, "Precision set to %d digits (%d bits)\n", digits, calc.Precision())
+		return nil
+	default:
+		return fmt.Errorf("usage: :precision [digits|off]")
+	}
+}
+
+// roundingCommand shows or sets the rounding mode used for arbitrary precision arithmetic
+func roundingCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		fmt.Fprintf(w, "Rounding mode: %s\n", calculator.RoundingModeName(calc.RoundingMode()))
+		return nil
+	case 1:
+		mode, err := calculator.ParseRoundingMode(args[0])
+		if err != nil {
+			return err
+		}
+		calc.SetRoundingMode(mode)
+		fmt.Fprintf(w, "Rounding mode set to %s\n", calculator.RoundingModeName(mode))
+		return nil
+	default:
+		return fmt.Errorf("usage: :rounding nearest-even|nearest-away|zero|away|down|up")
+	}
+}
+
+// rationalCommand shows or toggles exact rational arithmetic; "decimal" also
+// enables it and prints a decimal approximation after each fraction
+func rationalCommand(s *session, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		state := "off"
+		if s.calc.NumberMode() == calculator.RationalMode {
+			state = "on"
+			if s.showDecimal {
+				state = "on, with decimal approximations"
+			}
+		}
+		fmt.Fprintf(w, "Rational mode: %s\n", state)
+		return nil
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: :rational [on|off|decimal]")
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		s.calc.SetNumberMode(calculator.RationalMode)
+		s.showDecimal = false
+		fmt.Fprintln(w, "Rational mode on")
+	case "decimal":
+		s.calc.SetNumberMode(calculator.RationalMode)
+		s.showDecimal = true
+		fmt.Fprintln(w, "Rational mode on, with decimal approximations")
+	case "off":
+		if s.calc.NumberMode() == calculator.RationalMode {
+			s.calc.SetNumberMode(calculator.FloatMode)
+		}
+		s.showDecimal = false
+		fmt.Fprintln(w, "Rational mode off")
+	default:
+		return fmt.Errorf("usage: :rational [on|off|decimal]")
+	}
+	return nil
+}
+
+// intCommand shows or selects integer (programmer) mode; the argument is a word
+// size such as 32, i16 or u8, or "on" for the current word size, or "off"
+func intCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		if calc.NumberMode() != calculator.IntegerMode {
+			fmt.Fprintf(w, "Integer mode: off (word size %s)\n", calc.WordSize())
+		} else {
+			fmt.Fprintf(w, "Integer mode: %s, overflow %s\n", calc.WordSize(), calc.OverflowMode())
+		}
+		return nil
+	case 1:
+		switch strings.ToLower(args[0]) {
+		case "off":
+			if calc.NumberMode() == calculator.IntegerMode {
+				calc.SetNumberMode(calculator.FloatMode)
+			}
+			fmt.Fprintln(w, "Integer mode off")
+			return nil
+		case "on":
+		default:
+			size, err := calculator.ParseWordSize(args[0])
+			if err != nil {
+				return err
+			}
+			if err := calc.SetWordSize(size); err != nil {
+				return err
+			}
+		}
+		calc.SetNumberMode(calculator.IntegerMode)
+		fmt.Fprintf(w, "Integer mode on (%s)\n", calc.WordSize())
+		return nil
+	default:
+		return fmt.Errorf("usage: :int [on|off|8|16|32|64|u8|u16|u32|u64]")
+	}
+}
+
+// overflowCommand shows or sets whether integer results that do not fit the word
+// size wrap around or are reported as errors
+func overflowCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		fmt.Fprintf(w, "Integer overflow: %s\n", calc.OverflowMode())
+		return nil
+	case 1:
+		mode, err := calculator.ParseOverflowMode(args[0])
+		if err != nil {
+			return err
+		}
+		calc.SetOverflowMode(mode)
+		fmt.Fprintf(w, "Integer overflow set to %s\n", mode)
+		return nil
+	default:
+		return fmt.Errorf("usage: :overflow wrap|error")
+	}
+}
+
+// ieeeCommand shows or sets whether IEEE-754 edge cases in float results, such
+// as overflow to +Inf or NaN, are warnings, errors or ignored
+func ieeeCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	switch len(args) {
+	case 0:
+		fmt.Fprintf(w, "IEEE-754 checks: %s\n", calc.IEEEMode())
+		return nil
+	case 1:
+		mode, err := calculator.ParseIEEEMode(args[0])
+		if err != nil {
+			return err
+		}
+		calc.SetIEEEMode(mode)
+		fmt.Fprintf(w, "IEEE-754 checks set to %s\n", mode)
+		return nil
+	default:
+		return fmt.Errorf("usage: :ieee lenient|strict|quiet")
+	}
+}
+
+// formatCommand shows or changes the display format of results, e.g. :format hex,
+// :format fix 2 group or :format auto
+func formatCommand(s *session, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprintf(w, "Display format: %s\n", s.format)
+		return nil
+	}
+	format, err := expr.ParseFormat(s.format, args)
+	if err != nil {
+		return fmt.Errorf("%v (usage: :format hex|oct|bin|dec|base N|fix N|sci [N]|eng [N]|sig N|group|nogroup|auto)", err)
+	}
+	s.format = format
+	fmt.Fprintf(w, "Display format set to %s\n", format)
+	return nil
+}
+
+// complexCommand shows or toggles complex mode
+func complexCommand(calc *calculator.Calculator, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		state := "off"
+		if calc.NumberMode() == calculator.ComplexMode {
+			state = "on"
+		}
+		fmt.Fprintf(w, "Complex mode: %s\n", state)
+		return nil
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: :complex [on|off]")
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		calc.SetNumberMode(calculator.ComplexMode)
+		fmt.Fprintln(w, "Complex mode on")
+	case "off":
+		if calc.NumberMode() == calculator.ComplexMode {
+			calc.SetNumberMode(calculator.FloatMode)
+		}
+		fmt.Fprintln(w, "Complex mode off")
+	default:
+		return fmt.Errorf("usage: :complex [on|off]")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/expr"
+)
+
+func TestHistoryCommand(t *testing.T) {
+	calc := calculator.New()
+	env := expr.NewEnv()
+	s := &session{calc: calc, env: env}
+	var out bytes.Buffer
+
+	if err := runCommand(s, ":history", &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.String() != "No history yet.\n" {
+		t.Errorf("Expected empty history message, got %q", out.String())
+	}
+
+	for _, line := range []string{"3 + 4", "ans * 2", "$1 + _"} {
+		if _, err := evaluateExpression(calc, env, line); err != nil {
+			t.Fatalf("Unexpected error for %q: %v", line, err)
+		}
+	}
+
+	out.Reset()
+	if err := runCommand(s, ":history", &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "$1: 3 + 4 = 7\n$2: ans * 2 = 14\n$3: $1 + _ = 21\n"
+	if out.String() != expected {
+		t.Errorf("Expected history:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := runCommand(newSession(calculator.New()), ":bogus", &out); err == nil {
+		t.Error("Expected error for unknown command")
+	}
+	if err := runCommand(newSession(calculator.New()), ":", &out); err == nil {
+		t.Error("Expected error for missing command name")
+	}
+}
+
+func TestModeCommand(t *testing.T) {
+	calc := calculator.New()
+	env := expr.NewEnv()
+	s := &session{calc: calc, env: env}
+	var out bytes.Buffer
+
+	if err := runCommand(s, ":mode deg", &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calc.AngleMode() != calculator.Degrees {
+		t.Errorf("Expected degree mode, got %s", calc.AngleMode())
+	}
+	if prompt(calc) != "deg> " {
+		t.Errorf("Expected prompt to show deg, got %q", prompt(calc))
+	}
+	if result, err := evaluateFloat(calc, env, "sin(90) + acos(0)"); err != nil || result != 91 {
+		t.Errorf("Expected sin(90) + acos(0) = 91 in degree mode, got %v (err: %v)", result, err)
+	}
+
+	out.Reset()
+	if err := runCommand(s, ":mode", &out); err != nil || out.String() != "Angle mode: deg\n" {
+		t.Errorf("Expected current mode to be reported, got %q (err: %v)", out.String(), err)
+	}
+
+	if err := runCommand(s, ":mode turns", &out); err == nil {
+		t.Error("Expected error for unknown angle mode")
+	}
+	if calc.AngleMode() != calculator.Degrees {
+		t.Error("Expected a rejected mode to leave the angle mode unchanged")
+	}
+}
+
+func TestConstantsCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := runCommand(newSession(calculator.New()), ":constants", &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expr.Constants()) {
+		t.Errorf("Expected one line per constant, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "pi") || !strings.Contains(lines[0], "3.14159") {
+		t.Errorf("Expected pi to be listed first with its value, got %q", lines[0])
+	}
+	if !strings.Contains(out.String(), "m/s") || !strings.Contains(out.String(), "speed of light") {
+		t.Error("Expected physical constants to be listed with units and descriptions")
+	}
+}
@@ -3,28 +3,79 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/currency"
+	"github.com/jondkelley/cicd_golang_calculator/internal/expr"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
 	"github.com/jondkelley/cicd_golang_calculator/internal/updater"
+	"io"
 	"os"
 	"os/signal"
-	"regexp"
 	"runtime"
-	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 var version = "0.0.0-local"
 var buildTime = "unknown"
 
+// defaultRateMaxAge is how old exchange rates may be before the REPL warns
+const defaultRateMaxAge = 7 * 24 * time.Hour
+
+// options holds the settings chosen on the command line
+type options struct {
+	showVersion bool
+	angleMode   calculator.AngleMode
+	precision   int           // significant digits for arbitrary precision, 0 for float64
+	rates       string        // exchange rates file or URL, if any
+	rateMaxAge  time.Duration // age after which exchange rates are reported as stale
+}
+
+// parseOptions parses the command line arguments (excluding the program name)
+func parseOptions(args []string) (*options, error) {
+	opts := &options{}
+	var angle string
+
+	fs := flag.NewFlagSet("calc", flag.ContinueOnError)
+	fs.BoolVar(&opts.showVersion, "version", false, "print version information and exit")
+	fs.BoolVar(&opts.showVersion, "v", false, "shorthand for --version")
+	fs.StringVar(&angle, "angle", "rad", "angle mode for trigonometric functions: deg, rad or grad")
+	fs.IntVar(&opts.precision, "precision", 0, "significant digits for arbitrary precision arithmetic (0 uses float64)")
+	fs.StringVar(&opts.rates, "rates", "", "JSON or CSV exchange rates file, or http(s) URL, for currency conversion")
+	fs.DurationVar(&opts.rateMaxAge, "rates-max-age", defaultRateMaxAge, "warn when exchange rates are older than this (0 never warns)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.precision != 0 {
+		if err := calculator.ValidatePrecision(opts.precision); err != nil {
+			return nil, err
+		}
+	}
+
+	mode, err := calculator.ParseAngleMode(angle)
+	if err != nil {
+		return nil, err
+	}
+	opts.angleMode = mode
+	return opts, nil
+}
+
 func main() {
-	if len(os.Args) > 1 {
-		arg := os.Args[1]
-		if arg == "--version" || arg == "-v" {
-			printVersion()
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
 			return
 		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if opts.showVersion {
+		printVersion()
+		return
 	}
 
 	fmt.Printf("cicd_golang_calculator %s\n", version)
@@ -34,7 +85,20 @@ func main() {
 
 	printWelcomeMessage()
 	setupSignalHandling()
-	runCalculator()
+
+	calc := calculator.New()
+	calc.SetAngleMode(opts.angleMode)
+	if opts.precision > 0 {
+		calc.SetPrecision(calculator.BitsForDigits(opts.precision))
+	}
+	s := newSession(calc)
+	s.rateMaxAge = opts.rateMaxAge
+	if opts.rates != "" {
+		if err := s.loadRates(currency.NewLoader(opts.rates), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	runCalculator(s)
 }
 
 func printVersion() {
@@ -50,8 +114,28 @@ func printWelcomeMessage() {
 	fmt.Println(`  5 * 6`)
 	fmt.Println(`  10 / 2`)
 	fmt.Println(`  sqrt(16)`)
-	fmt.Println("Supported operators: + - * / % ^ sqrt()")
-	fmt.Println("Type Ctrl+C to exit.")
+	fmt.Println(`  (1 + 2) * 3 ^ 2`)
+	fmt.Println(`  2 * pi * 6371`)
+	fmt.Println(`  rate = 0.07`)
+	fmt.Println(`  ans * 2, $1 + $2`)
+	fmt.Println(`  f(x, y) = x^2 + y`)
+	fmt.Println(`  :rational on, then 1/3 * 3`)
+	fmt.Println(`  :int u8, then 0xf0 | 0b1010`)
+	fmt.Println(`  255 to hex, 1234567.891 to fix 2 group`)
+	fmt.Println(`  :complex on, then sqrt(-4) * (3+4i)`)
+	fmt.Println(`  5 km + 300 m to mi, 60 W * 3 h in kWh`)
+	fmt.Println(`  120 EUR to USD (start with --rates FILE)`)
+	fmt.Println(`  2026-12-25 - today, now + 90d in Asia/Tokyo, 3h20m * 4`)
+	fmt.Println(`  50 + 10%, 20% of 80, 30 as % of 120, pctchange(80, 100)`)
+	fmt.Println(`  xs = [120 ms, 95 ms, 310 ms], then median(xs), percentile(xs, 95)`)
+	fmt.Println(`  A = [[1, 2], [3, 4]], then A @ inv(A), det(A), linsolve(A, [5, 11])`)
+	fmt.Println(`  solve(x^3 - 8, x, 1), roots(sin(x), x, 0, 10)`)
+	fmt.Println(`  integrate(exp(-x^2), x, -inf, inf), deriv(sin(x), x, 1)`)
+	fmt.Println(`  diff(x^3 * sin(x), x), simplify(x * x^2 + 2*x^3)`)
+	fmt.Println(`  6!, nCr(10, 3), factor(600851475143), modpow(4, 13, 497)`)
+	fmt.Println("Supported operators: + - * / // % mod ^ ! @ & | xor ~ << >> of as ( ) [ ]")
+	fmt.Printf("Functions: %s\n", strings.Join(expr.BuiltinNames(), " "))
+	fmt.Println("Commands: :history :functions :constants :depth [n] :mode deg|rad|grad :precision [digits|off] :rounding [mode] :rational [on|off|decimal] :int [size|off] :overflow wrap|error :ieee [lenient|strict|quiet] :format [spec] :complex [on|off] :units [unit] :rates [file|url] :tz [zone]. Type Ctrl+C to exit.")
 }
 
 func setupSignalHandling() {
@@ -64,12 +148,79 @@ func setupSignalHandling() {
 	}()
 }
 
-func runCalculator() {
-	calc := calculator.New()
+// session holds the state of one interactive calculator session
+type session struct {
+	calc *calculator.Calculator
+	env  *expr.Env
+	// showDecimal prints a decimal approximation after exact fractions
+	showDecimal bool
+	// format controls how results are displayed
+	format expr.Format
+	// rates are the exchange rates behind the currency units, if loaded
+	rates *currency.Rates
+	// rateMaxAge is the age after which rates are reported as stale; 0 never
+	rateMaxAge time.Duration
+	// now returns the current time; nil uses time.Now
+	now func() time.Time
+}
+
+// newSession creates a session with an empty symbol table
+func newSession(calc *calculator.Calculator) *session {
+	return &session{calc: calc, env: expr.NewEnv(), rateMaxAge: defaultRateMaxAge}
+}
+
+// clock returns the current time of the session
+func (s *session) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+// loadRates loads exchange rates, registers their currencies as units and
+// reports what was loaded, warning when the rates are stale
+func (s *session) loadRates(loader currency.Loader, w io.Writer) error {
+	r, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	units.SetCurrencies(r.Values())
+	s.rates = r
+	fmt.Fprintf(w, "Loaded %d exchange rates against %s as of %s\n", len(r.Rates), r.Base, r.Date.Format(currency.DateLayout))
+	if s.rates.Stale(s.clock(), s.rateMaxAge) {
+		fmt.Fprintf(w, "Warning: exchange rates are %s old\n", s.rateAge())
+	}
+	return nil
+}
+
+// rateAge describes the age of the loaded rates in whole days
+func (s *session) rateAge() string {
+	days := int(s.rates.Age(s.clock()).Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// rateNote returns the note shown after an amount of money: the date of the
+// exchange rates behind it and, when they are stale, their age
+func (s *session) rateNote(v expr.Value) string {
+	q, ok := v.(expr.Quantity)
+	if !ok || !q.Unit.IsMoney() || s.rates == nil {
+		return ""
+	}
+	note := " (rates as of " + s.rates.Date.Format(currency.DateLayout)
+	if s.rates.Stale(s.clock(), s.rateMaxAge) {
+		note += ", " + s.rateAge() + " old"
+	}
+	return note + ")"
+}
+
+func runCalculator(s *session) {
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
-		fmt.Print("> ")
+		fmt.Print(prompt(s.calc))
 		if !scanner.Scan() {
 			break
 		}
@@ -79,46 +230,122 @@ func runCalculator() {
 			continue
 		}
 
-		result, err := evaluateExpression(calc, line)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+		processLine(s, line, os.Stdout)
+	}
+}
+
+// prompt returns the REPL prompt, which shows the active angle mode and, in
+// integer mode, the word size or, in complex mode, "complex"
+func prompt(calc *calculator.Calculator) string {
+	switch calc.NumberMode() {
+	case calculator.IntegerMode:
+		return fmt.Sprintf("%s %s> ", calc.AngleMode(), calc.WordSize())
+	case calculator.ComplexMode:
+		return fmt.Sprintf("%s complex> ", calc.AngleMode())
+	}
+	return fmt.Sprintf("%s> ", calc.AngleMode())
+}
+
+// processLine handles one line of REPL input: a meta-command, a function
+// definition or an expression whose result is printed and recorded. A trailing
+// display suffix such as "to hex" formats just this result.
+func processLine(s *session, line string, w io.Writer) {
+	if isCommand(line) {
+		if err := runCommand(s, line, w); err != nil {
+			fmt.Fprintf(w, "Error: %v\n", err)
+		}
+		return
+	}
+
+	line, format, suffixed, err := expr.SplitFormat(line, s.format)
+	if err != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+	node, err := expr.Parse(line)
+	if err != nil {
+		printError(w, line, err)
+		return
+	}
+
+	if def, ok := node.(*expr.FuncDef); ok {
+		if err := s.env.Define(def.Func); err != nil {
+			fmt.Fprintf(w, "Error: %v\n", err)
 		} else {
-			fmt.Printf("= %v\n", result)
+			fmt.Fprintf(w, "Defined %s\n", def.Func.Signature())
 		}
+		return
+	}
+
+	result, e, err := evaluateNode(s.calc, s.env, line, node)
+	if err != nil {
+		printError(w, line, err)
+		return
+	}
+	text, err := formatResult(s, result, format)
+	if err != nil {
+		if suffixed {
+			fmt.Fprintf(w, "Error: %v\n", err)
+			return
+		}
+		// The session format does not suit every result, e.g. hex for 0.5
+		text = result.String()
+	}
+	fmt.Fprintf(w, "= %s%s\n", text, s.rateNote(result))
+	for _, warning := range e.Warnings() {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+	for _, note := range e.Notes() {
+		fmt.Fprintf(w, "Note: %s\n", note)
 	}
 }
 
-// Expression evaluation logic
-var (
-	operatorRE = regexp.MustCompile(`^\s*([-+]?[0-9]*\.?[0-9]+)\s*([+\-*/^%])\s*([-+]?[0-9]*\.?[0-9]+)\s*$`)
-	sqrtRE     = regexp.MustCompile(`(?i)^sqrt\(\s*([-+]?[0-9]*\.?[0-9]+)\s*\)$`)
-)
+// printError reports an error in line and, when the error is located in it,
+// shows the line with a caret under the problem like a compiler would
+func printError(w io.Writer, line string, err error) {
+	fmt.Fprintf(w, "Error: %v\n", err)
+	var located *expr.Error
+	if errors.As(err, &located) {
+		fmt.Fprint(w, located.Diagnostic(line))
+	}
+}
 
-func evaluateExpression(calc *calculator.Calculator, expr string) (float64, error) {
-	if matches := operatorRE.FindStringSubmatch(expr); matches != nil {
-		a, _ := strconv.ParseFloat(matches[1], 64)
-		op := matches[2]
-		b, _ := strconv.ParseFloat(matches[3], 64)
-
-		switch op {
-		case "+":
-			return calc.Add(a, b), nil
-		case "-":
-			return calc.Subtract(a, b), nil
-		case "*":
-			return calc.Multiply(a, b), nil
-		case "/":
-			return calc.Divide(a, b)
-		case "^":
-			return calc.Power(a, b), nil
-		case "%":
-			return calc.ModFloat(a, b)
-		default:
-			return 0, fmt.Errorf("unsupported operator: %s", op)
-		}
-	} else if matches := sqrtRE.FindStringSubmatch(expr); matches != nil {
-		a, _ := strconv.ParseFloat(matches[1], 64)
-		return calc.Sqrt(a)
+// formatResult renders a result in the given display format, adding a decimal
+// approximation to non-integer fractions when the session asks for one
+func formatResult(s *session, v expr.Value, format expr.Format) (string, error) {
+	text, err := format.Render(s.calc, v)
+	if err != nil {
+		return "", err
+	}
+	if r, ok := v.(expr.Rat); ok && s.showDecimal && !r.X.IsInt() {
+		f, _ := r.X.Float64()
+		approx, _ := format.Render(s.calc, expr.Float(f))
+		return fmt.Sprintf("%s ≈ %s", text, approx), nil
+	}
+	return text, nil
+}
+
+// evaluateExpression parses and evaluates a single line of input, resolving and
+// assigning variables in the session symbol table env. Successful results are
+// recorded in the session history so later lines can recall them.
+func evaluateExpression(calc *calculator.Calculator, env *expr.Env, line string) (expr.Value, error) {
+	node, err := expr.Parse(line)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := evaluateNode(calc, env, line, node)
+	return result, err
+}
+
+// evaluateNode evaluates the parsed form of line and records the result in the
+// session history. It also returns the evaluator for its diagnostics, such as
+// how a root was found or a result that overflowed.
+func evaluateNode(calc *calculator.Calculator, env *expr.Env, line string, node expr.Node) (expr.Value, *expr.Evaluator, error) {
+	e := expr.NewEvaluator(calc, env)
+	result, err := e.Eval(node)
+	if err != nil {
+		return nil, nil, err
 	}
-	return 0, fmt.Errorf("invalid expression format")
+	env.History().Add(line, result)
+	return result, e, nil
 }
@@ -2,12 +2,28 @@
 package main
 
 import (
+	"bytes"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/expr"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
 )
 
+// evaluateFloat evaluates line like the REPL and converts the result to float64
+func evaluateFloat(calc *calculator.Calculator, env *expr.Env, line string) (float64, error) {
+	result, err := evaluateExpression(calc, env, line)
+	if err != nil {
+		return 0, err
+	}
+	return expr.ToFloat(result)
+}
+
 func TestEvaluateExpression(t *testing.T) {
 	calc := calculator.New()
 
@@ -23,6 +39,9 @@ func TestEvaluateExpression(t *testing.T) {
 		{"sqrt(16)", 4, false},
 		{"10 % 3", 1, false},
 		{"2 ^ 3", 8, false},
+		{"3 + 4 * 2", 11, false},
+		{"(1+2)/3", 1, false},
+		{"-sqrt(16) ^ 2", -16, false},
 		{"sqrt(-1)", 0, true},
 		{"10 / 0", 0, true},
 		{"abc", 0, true},
@@ -30,7 +49,7 @@ func TestEvaluateExpression(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result, err := evaluateExpression(calc, test.expr)
+		result, err := evaluateFloat(calc, expr.NewEnv(), test.expr)
 		if test.err && err == nil {
 			t.Errorf("Expected error for %q, got none", test.expr)
 		}
@@ -39,3 +58,604 @@ func TestEvaluateExpression(t *testing.T) {
 		}
 	}
 }
+
+func TestEvaluateExpressionVariables(t *testing.T) {
+	calc := calculator.New()
+	env := expr.NewEnv()
+
+	steps := []struct {
+		line     string
+		expected float64
+	}{
+		{"rate = 0.07", 0.07},
+		{"total = 1200 * (1 + rate)", 1284},
+		{"total / 2", 642},
+		{"rate = rate * 2", 0.14},
+		{"sqrt(rate * 0 + 16) + rate", 4.14},
+	}
+
+	for _, step := range steps {
+		result, err := evaluateFloat(calc, env, step.line)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", step.line, err)
+		}
+		if math.Abs(result-step.expected) > 1e-9 {
+			t.Errorf("Expected %v for %q, got %v", step.expected, step.line, result)
+		}
+	}
+
+	if _, err := evaluateExpression(calc, env, "missing + 1"); err == nil {
+		t.Error("Expected error for undefined identifier")
+	}
+}
+
+func TestProcessLine(t *testing.T) {
+	calc := calculator.New()
+	env := expr.NewEnv()
+	s := &session{calc: calc, env: env}
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"f(x, y) = x^2 + y", "Defined f(x, y)\n"},
+		{"f(3, 1)", "= 10\n"},
+		{"ans + 1", "= 11\n"},
+		{"f(1)", "Error: f(x, y) expects 2 argument(s), got 1\n  f(1)\n  ^\n"},
+		{"sqrt(x) = 1", "Error: cannot redefine built-in function \"sqrt\"\n"},
+		{":functions", "f(x, y) = ((x ^ 2) + y)\n"},
+		{":depth 3", "Maximum call depth set to 3\n"},
+		{":depth 100000000", "Error: maximum depth must be at most 10000, got 100000000\n"},
+		{":depth", "Maximum call depth: 3\n"},
+		{"5 /", "Error: unexpected end of input at position 3\n  5 /\n     ^\nHint: the expression is incomplete\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+
+	// Definitions are not results, so only the two evaluations are recorded
+	if n := env.History().Len(); n != 2 {
+		t.Errorf("Expected 2 history entries, got %d", n)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := parseOptions([]string{"--angle", "grad", "--precision", "40"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.angleMode != calculator.Gradians || opts.precision != 40 || opts.showVersion {
+		t.Errorf("Expected gradian mode with 40 digits and no version, got %+v", opts)
+	}
+
+	opts, err = parseOptions([]string{"--version"})
+	if err != nil || !opts.showVersion || opts.angleMode != calculator.Radians {
+		t.Errorf("Expected --version with default radian mode, got %+v (err: %v)", opts, err)
+	}
+	if opts, err := parseOptions([]string{"-v"}); err != nil || !opts.showVersion {
+		t.Errorf("Expected -v to request the version, got %+v (err: %v)", opts, err)
+	}
+
+	if _, err := parseOptions([]string{"--angle", "turns"}); err == nil {
+		t.Error("Expected error for unknown angle mode")
+	}
+	if _, err := parseOptions([]string{"--precision", "-5"}); err == nil {
+		t.Error("Expected error for negative precision")
+	}
+	if _, err := parseOptions([]string{"--precision", "100000000"}); err == nil {
+		t.Error("Expected error for a precision beyond the maximum")
+	}
+
+	opts, err = parseOptions([]string{"--rates", "rates.json", "--rates-max-age", "48h"})
+	if err != nil || opts.rates != "rates.json" || opts.rateMaxAge != 48*time.Hour {
+		t.Errorf("Expected rates.json with a 48h maximum age, got %+v (err: %v)", opts, err)
+	}
+}
+
+func TestProcessLinePrecision(t *testing.T) {
+	calc := calculator.New()
+	env := expr.NewEnv()
+	s := &session{calc: calc, env: env}
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"0.1 + 0.2", "= 0.30000000000000004\n"},
+		{"2^64 + 1", "= 1.8446744073709552e+19\nWarning: 18446744073709551616 + 1 is 18446744073709551617, rounded to 18446744073709551616: integers above 2^53 are not exact in float64\n"},
+		{":precision 100000000", "Error: precision must be 1 to 1000 digits, got 100000000\n"},
+		{":precision 0", "Error: precision must be 1 to 1000 digits, got 0\n"},
+		{":precision 30", "Precision set to 30 digits (100 bits)\n"},
+		{"0.1 + 0.2", "= 0.3\n"},
+		{"2^64 + 1", "= 18446744073709551617\n"},
+		{"1 / 3", "= 0.333333333333333333333333333333\n"},
+		{"x = sqrt(2)", "= 1.41421356237309504880168872421\n"},
+		{"pi", "= 3.14159265358979323846264338328\n"},
+		{"sin(x) + sin(x)", "= 1.975531891985471\nNote: sin has no arbitrary precision implementation, so it is computed in float64\n"},
+		{"-10.5 % 3", "= -1.5\n"},
+		{"2 ^ -2", "= 0.25\n"},
+		{":rounding zero", "Rounding mode set to zero\n"},
+		{"2 / 3", "= 0.666666666666666666666666666666\n"},
+		{":precision off", "Precision set to float64\n"},
+		{"x", "= 1.4142135623730951\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineRational(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"0.1 * 3", "= 0.30000000000000004\n"},
+		{":rational on", "Rational mode on\n"},
+		{"0.1 * 3", "= 3/10\n"},
+		{"1/3 * 3", "= 1\n"},
+		{"1/3 * 3 - 1", "= 0\n"},
+		{"0.1 + 0.2", "= 3/10\n"},
+		{"-(2/6)", "= -1/3\n"},
+		{"(2/3) ^ -2", "= 9/4\n"},
+		{"7.5 % 2", "= 3/2\n"},
+		{"1 / 0", "Error: division by zero\n  1 / 0\n    ^\n"},
+		{"2 ^ 0.5", "= 1.4142135623730951\n"},
+		{":rational decimal", "Rational mode on, with decimal approximations\n"},
+		{"r = 1/3", "= 1/3 ≈ 0.3333333333333333\n"},
+		{"r * 6", "= 2\n"},
+		{":rational", "Rational mode: on, with decimal approximations\n"},
+		{":rational off", "Rational mode off\n"},
+		{"r", "= 0.3333333333333333\n"},
+		// Turning rational mode off leaves other modes alone
+		{":int 32", "Integer mode on (i32)\n"},
+		{":rational off", "Rational mode off\n"},
+		{"7 / 2", "= 3\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineInteger(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"0xff & 0x0f", "= 15\n"},
+		{":int u8", "Integer mode on (u8)\n"},
+		{"0xf0 | 0b1010", "= 250\n"},
+		{"255 + 1", "= 0\n"},
+		{"~0 >> 4", "= 15\n"},
+		{"7 / 2", "= 3\n"},
+		{"x = 200", "= 200\n"},
+		{":overflow error", "Integer overflow set to error\n"},
+		{"x + 100", "Error: integer overflow: 300 does not fit in u8\n  x + 100\n    ^\n"},
+		{":int", "Integer mode: u8, overflow error\n"},
+		{"1.5", "Error: 1.5 is not an integer\n  1.5\n  ^^^\n"},
+		{":int off", "Integer mode off\n"},
+		{"x / 3", "= 66.66666666666667\n"},
+		{":int 128", "Error: unknown word size \"128\" (expected 8, 16, 32 or 64 with an optional i/u prefix)\n"},
+		{":overflow saturate", "Error: unknown overflow mode \"saturate\" (expected wrap or error)\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+
+	processLine(s, ":int on", &bytes.Buffer{})
+	if prompt(s.calc) != "rad u8> " {
+		t.Errorf("Expected prompt to show the word size, got %q", prompt(s.calc))
+	}
+}
+
+func TestProcessLineFormat(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"255 to hex", "= 0xff\n"},
+		{"ans + 1", "= 256\n"},
+		{"0.5 to bin", "Error: cannot display 0.5 in base 2: not an integer\n"},
+		{"10% to hex", "Error: cannot display the percentage 10% in base 16\n"},
+		{"1e6 / 3 to fix 2 group", "= 333,333.33\n"},
+		{"35 TO base 36", "= z (base 36)\n"},
+		{"255 to base 37", "Error: base must be 2 to 36, got 37\n"},
+		{":format eng 3", "Display format set to eng 3\n"},
+		{"47e-6", "= 47.0e-06\n"},
+		{":format hex group", "Display format set to hex group\n"},
+		{"0xdeadbeef", "= 0xdead_beef\n"},
+		{"0.5", "= 0.5\n"},
+		{"10% * 2", "= 20%\n"},
+		{"65535 to dec", "= 65,535\n"},
+		{":format", "Display format: hex group\n"},
+		{":format auto", "Display format set to auto\n"},
+		{":format roman", "Error: unknown display format \"roman\" (usage: :format hex|oct|bin|dec|base N|fix N|sci [N]|eng [N]|sig N|group|nogroup|auto)\n"},
+		{":int i8", "Integer mode on (i8)\n"},
+		{"-1 to bin", "= 0b11111111\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+
+	if last, _ := s.env.History().Last(); last.String() != "-1" {
+		t.Errorf("Expected the display suffix to leave the stored result unchanged, got %s", last)
+	}
+}
+
+func TestProcessLineComplex(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"sqrt(-4)", "Error: square root of negative number\n  sqrt(-4)\n  ^^^^\n"},
+		{"3+4i", "Error: imaginary number 4i requires complex mode\n  3+4i\n    ^^\n"},
+		{":complex on", "Complex mode on\n"},
+		{"sqrt(-4)", "= 2i\n"},
+		{"z = 3+4i", "= 3+4i\n"},
+		{"abs(z)", "= 5\n"},
+		{"conj(z) * z", "= 25\n"},
+		{"re(z) - im(z)", "= -1\n"},
+		{"i^2", "= -1\n"},
+		{"z to polar", "= 5∠0.9272952180016122 rad\n"},
+		{":mode deg", "Angle mode set to deg\n"},
+		{"z to polar fix 2", "= 5.00∠53.13°\n"},
+		{"arg(-1)", "= 180\n"},
+		{"sin(180)", "= 0\n"},
+		{"(1+2i) % 2", "Error: operator % is not defined for complex numbers\n  (1+2i) % 2\n         ^\n"},
+		{":complex", "Complex mode: on\n"},
+		{":complex off", "Complex mode off\n"},
+		{"re(z)", "= 3\n"},
+		{"z + 1", "Error: expected a real number, got 3+4i\n  z + 1\n    ^\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineUnits(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"5 km + 300 m to mi", "= 3.29326731885787 mi\n"},
+		{"60 W * 3 h in kWh", "= 0.18 kWh\n"},
+		{"speed = 100 km / 2 h", "= 50 km/h\n"},
+		{"speed to m/s to fix 2", "= 13.89 m/s\n"},
+		{"20 degC to degF", "= 68 degF\n"},
+		{"5 km + 3 s", "Error: cannot apply + to 5 km and 3 s: incompatible dimensions m and s\n  5 km + 3 s\n       ^\n"},
+		{"5 km to kg", "Error: cannot convert 5 km to kg: incompatible dimensions m and kg\n  5 km to kg\n       ^^\n"},
+		{"5 km to parsec", "Error: unknown unit \"parsec\" at position 8\n  5 km to parsec\n          ^^^^^^\n"},
+		{":units kWh", "kWh = 3.6e+06 kg*m^2/s^2\n"},
+		{":units furlong", "Error: unknown unit \"furlong\"\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	processLine(s, ":units", &out)
+	if !strings.Contains(out.String(), "mi, mile") || !strings.Contains(out.String(), "international mile") {
+		t.Errorf("Expected :units to list the mile, got %q", out.String())
+	}
+}
+
+func TestProcessLineCurrency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"base": "USD", "date": "2026-10-01", "rates": {"EUR": 0.8, "GBP": 0.5}}`))
+	}))
+	defer server.Close()
+	defer units.SetCurrencies(nil)
+
+	s := newSession(calculator.New())
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"120 EUR to USD", "Error: unexpected \"EUR\" at position 4\n  120 EUR to USD\n      ^^^\nHint: \"EUR\" is not a known unit; write * to multiply\n"},
+		{":rates", "No exchange rates loaded. Use :rates FILE or start with --rates FILE.\n"},
+		{":rates " + server.URL, "Loaded 2 exchange rates against USD as of 2026-10-01\nWarning: exchange rates are 15 days old\n"},
+		{"120 EUR to USD", "= 150 USD (rates as of 2026-10-01, 15 days old)\n"},
+		{"10 GBP + 5 USD to EUR", "= 20 EUR (rates as of 2026-10-01, 15 days old)\n"},
+		{"100 USD / 8 h", "= 12.5 USD/h (rates as of 2026-10-01, 15 days old)\n"},
+		{"100 USD / 50 EUR", "= 1.6\n"},
+		{"5 EUR to m", "Error: cannot convert 5 EUR to m: incompatible dimensions ¤ and m\n  5 EUR to m\n        ^^\n"},
+		{":rates " + server.URL + " extra", "Error: usage: :rates [file|url]\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+
+	// Fresh rates carry just their date
+	s.rateMaxAge = 30 * 24 * time.Hour
+	var out bytes.Buffer
+	processLine(s, "1 GBP to USD", &out)
+	if out.String() != "= 2 USD (rates as of 2026-10-01)\n" {
+		t.Errorf("Expected fresh rates without an age, got %q", out.String())
+	}
+	out.Reset()
+	processLine(s, ":rates", &out)
+	if !strings.Contains(out.String(), "2 exchange rates against USD as of 2026-10-01 (15 days old)") || !strings.Contains(out.String(), "EUR GBP USD") {
+		t.Errorf("Unexpected :rates output %q", out.String())
+	}
+	out.Reset()
+	processLine(s, ":rates /nonexistent/rates.json", &out)
+	if !strings.HasPrefix(out.String(), "Error: reading rates") {
+		t.Errorf("Expected error loading a missing file, got %q", out.String())
+	}
+}
+
+func TestProcessLineDates(t *testing.T) {
+	s := newSession(calculator.New())
+	s.env.SetClock(func() time.Time { return time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC) })
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{":tz UTC", "Time zone set to UTC\n"},
+		{":tz", "Time zone: UTC\n"},
+		{"2026-12-25 - today", "= 70d\n"},
+		{"now + 90d", "= 2027-01-14 09:15:00 UTC\n"},
+		{"now in Asia/Tokyo", "= 2026-10-16 18:15:00 JST\n"},
+		{"3h20m * 4", "= 13h20m\n"},
+		{"3h20m * 4 to h", "= 13.3333333333333 h\n"},
+		{"today * 2", "Error: cannot apply * to 2026-10-16 and 2\n  today * 2\n        ^\n"},
+		{":tz Mars/Olympus_Mons", "Error: unknown time zone \"Mars/Olympus_Mons\"\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLinePercentages(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"50 + 10%", "= 55\n"},
+		{"20% of 80", "= 16\n"},
+		{"30 as % of 120", "= 25%\n"},
+		{"ans + 5%", "= 30%\n"},
+		{"pctchange(80, 100)", "= 25%\n"},
+		{"7 % 4", "= 3\n"},
+		{"7 mod 4", "= 3\n"},
+		{":format fix 1", "Display format set to fix 1\n"},
+		{"2 as % of 3", "= 66.7%\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineStatistics(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"xs = [120 ms, 95 ms, 310 ms, 101 ms]", "= [120 ms, 95 ms, 310 ms, 101 ms]\n"},
+		{"median(xs)", "= 110.5 ms\n"},
+		{"percentile(xs, 50)", "= 110.5 ms\n"},
+		{"max(xs) to s", "= 0.31 s\n"},
+		{"count(xs)", "= 4\n"},
+		{"mean([])", "Error: mean requires at least one value\n  mean([])\n  ^^^^\n"},
+		{":format fix 1", "Display format set to fix 1\n"},
+		{"[1, 2.26]", "= [1.0, 2.3]\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineMatrices(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"A = [[4, 7], [2, 6]]", "= [[4, 7], [2, 6]]\n"},
+		{"det(A)", "= 10\n"},
+		{"inv(A)", "= [[0.6, -0.7], [-0.2, 0.4]]\n"},
+		{"A @ [1, 1]", "= [11, 8]\n"},
+		{"linsolve(A, ans)", "= [1, 1]\n"},
+		{"A @ [1, 2, 3]", "Error: cannot multiply 2x2 and 3x1 matrices\n  A @ [1, 2, 3]\n    ^\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineSolve(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"lat(load) = 20 + 0.5 * load", "Defined lat(load)\n"},
+		{"solve(lat(n) - 200, n, 10)", "= 360\nNote: solve: Newton's method converged in 2 iterations, |f(n)| = 0\n"},
+		{"roots(x^2 - 4, x, -5, 5)", "= [-2, 2]\nNote: roots: 2 root(s) from sign changes over 200 subintervals, refined by Brent's method, largest |f(x)| = 0\n"},
+		{"solve(x^2 + 1, x, 0)", "Error: solve: no root found near 0: Newton's method met a zero derivative at x = 0, and there is no sign change within 1e+08 of 0\n  solve(x^2 + 1, x, 0)\n  ^^^^^\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineCalculus(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"integrate(60 W, t, 0 h, 2 h)", "= 120 W*h\nNote: integrate: 15-point Gauss-Kronrod over 1 subintervals, estimated error 0\n"},
+		{"deriv(x^3, x, 2)", "= 12\nNote: deriv: Richardson extrapolation of central differences, estimated error 6.4e-14\n"},
+		{"integrate(1, x, 0, inf)", "Error: integrate: integrand does not decay fast enough at infinity\n  integrate(1, x, 0, inf)\n  ^^^^^^^^^\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineSymbolic(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"diff(x^3 * sin(x), x)", "= 3 * x^2 * sin(x) + x^3 * cos(x)\n"},
+		{"diff(ans, x)", "= 6 * x * sin(x) + 6 * x^2 * cos(x) - x^3 * sin(x)\n"},
+		{"simplify(x * x^2 + 2*x^3)", "= 3 * x^3\n"},
+		{"df(x) = diff(x^3 * sin(x), x)", "Defined df(x)\n"},
+		{"df(0)", "= 0\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineNumberTheory(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"6!", "= 720\n"},
+		{"factor(600851475143)", "= [71, 839, 1471, 6857]\n"},
+		{"gcd(ans)", "= 1\n"},
+		{"modinv(6, 9)", "Error: 6 has no inverse modulo 9\n  modinv(6, 9)\n  ^^^^^^\n"},
+		{":rational on", "Rational mode on\n"},
+		{"30! / 28!", "= 870\n"},
+		{"nCr(100, 50)", "= 100891344545564193334812497256\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
+
+func TestProcessLineIEEE(t *testing.T) {
+	s := newSession(calculator.New())
+
+	steps := []struct {
+		line     string
+		expected string
+	}{
+		{"0 ^ -1", "= +Inf\nWarning: 0 ^ -1 divides by zero, giving +Inf\n"},
+		{"(-8) ^ (1/3)", "= NaN\nWarning: -8 ^ 0.3333333333333333 is NaN: a negative number has no real fractional power\n"},
+		{"2^53 + 1", "= 9.007199254740992e+15\nWarning: 9007199254740992 + 1 is 9007199254740993, rounded to 9007199254740992: integers above 2^53 are not exact in float64\n"},
+		{"0.1 + 0.2 - 0.3", "= 5.551115123125783e-17\nWarning: 0.30000000000000004 - 0.3 loses 52 of 53 significant bits to cancellation\n"},
+		{":ieee strict", "IEEE-754 checks set to strict\n"},
+		{"exp(1000)", "Error: exp(1000) overflows to +Inf\n  exp(1000)\n  ^^^\n"},
+		{"1 / 3", "= 0.3333333333333333\n"},
+		{":ieee quiet", "IEEE-754 checks set to quiet\n"},
+		{"0 ^ -1", "= +Inf\n"},
+		{":ieee", "IEEE-754 checks: quiet\n"},
+		{":ieee loud", "Error: unknown IEEE mode \"loud\" (expected lenient, strict or quiet)\n"},
+	}
+
+	for _, step := range steps {
+		var out bytes.Buffer
+		processLine(s, step.line, &out)
+		if out.String() != step.expected {
+			t.Errorf("For %q expected %q, got %q", step.line, step.expected, out.String())
+		}
+	}
+}
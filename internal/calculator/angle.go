@@ -0,0 +1,150 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AngleMode selects the unit in which trigonometric functions take and return angles
+type AngleMode int
+
+const (
+	// Radians is the default angle mode
+	Radians AngleMode = iota
+	// Degrees divides a full turn into 360 units
+	Degrees
+	// Gradians divides a full turn into 400 units
+	Gradians
+)
+
+// String returns the short name of the angle mode as accepted by ParseAngleMode
+func (m AngleMode) String() string {
+	switch m {
+	case Degrees:
+		return "deg"
+	case Gradians:
+		return "grad"
+	default:
+		return "rad"
+	}
+}
+
+// ParseAngleMode converts a name such as "deg", "degrees" or "rad" into an AngleMode
+func ParseAngleMode(name string) (AngleMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "rad", "radian", "radians":
+		return Radians, nil
+	case "deg", "degree", "degrees":
+		return Degrees, nil
+	case "grad", "gradian", "gradians", "gon":
+		return Gradians, nil
+	default:
+		return Radians, fmt.Errorf("unknown angle mode %q (expected deg, rad or grad)", name)
+	}
+}
+
+// fullTurn returns the size of a complete revolution in the given mode
+func (m AngleMode) fullTurn() float64 {
+	switch m {
+	case Degrees:
+		return 360
+	case Gradians:
+		return 400
+	default:
+		return 2 * math.Pi
+	}
+}
+
+// AngleMode returns the angle mode used by trigonometric functions
+func (c *Calculator) AngleMode() AngleMode {
+	return c.angleMode
+}
+
+// SetAngleMode changes the angle mode used by trigonometric functions
+func (c *Calculator) SetAngleMode(mode AngleMode) {
+	c.angleMode = mode
+}
+
+// toRadians converts an angle in the current mode to radians. Degrees and
+// gradians are first reduced modulo a turn, which is exact, so that sin(1e20)
+// is the sine of 280 degrees rather than of a rounded product.
+func (c *Calculator) toRadians(x float64) float64 {
+	if c.angleMode == Radians {
+		return x
+	}
+	return math.Mod(x, c.angleMode.fullTurn()) * 2 * math.Pi / c.angleMode.fullTurn()
+}
+
+// fromRadians converts an angle in radians to the current mode
+func (c *Calculator) fromRadians(x float64) float64 {
+	if c.angleMode == Radians {
+		return x
+	}
+	return x * c.angleMode.fullTurn() / (2 * math.Pi)
+}
+
+// twelfthSines and eighthSines are the sines of the multiples of 30 and of 45
+// degrees, indexed by twelfths and eighths of a turn
+var (
+	twelfthSines = [12]float64{0, 0.5, math.Sqrt(3) / 2, 1, math.Sqrt(3) / 2, 0.5, 0, -0.5, -math.Sqrt(3) / 2, -1, -math.Sqrt(3) / 2, -0.5}
+	eighthSines  = [8]float64{0, math.Sqrt2 / 2, 1, math.Sqrt2 / 2, 0, -math.Sqrt2 / 2, -1, -math.Sqrt2 / 2}
+)
+
+// partsOfTurn reports how many parts of a turn x is, modulo a turn, when it is
+// an exact multiple of one in degree or gradian mode
+func (c *Calculator) partsOfTurn(x float64, parts int) (int, bool) {
+	if c.angleMode == Radians {
+		return 0, false
+	}
+	k := math.Mod(x, c.angleMode.fullTurn()) / (c.angleMode.fullTurn() / float64(parts))
+	if k != math.Trunc(k) {
+		return 0, false
+	}
+	return (int(k) + parts) % parts, true
+}
+
+// exactSin returns the sine of x plus the given number of quarter turns when x
+// is a multiple of 30 or 45 degrees in degree or gradian mode, so that sin(180)
+// is exactly 0 rather than 1.2e-16 and sin(30) exactly 0.5
+func (c *Calculator) exactSin(x float64, quarters int) (float64, bool) {
+	if k, ok := c.partsOfTurn(x, 12); ok {
+		return twelfthSines[(k+3*quarters)%12], true
+	}
+	if k, ok := c.partsOfTurn(x, 8); ok {
+		return eighthSines[(k+2*quarters)%8], true
+	}
+	return 0, false
+}
+
+// exactAsin returns the arcsine of x when x is the sine of a multiple of 30 or
+// 45 degrees in degree or gradian mode, so that asin(0.5) is exactly 30
+func (c *Calculator) exactAsin(x float64) (float64, bool) {
+	if c.angleMode == Radians {
+		return 0, false
+	}
+	turn := c.angleMode.fullTurn()
+	for k := -3; k <= 3; k++ {
+		if twelfthSines[(k+12)%12] == x {
+			return float64(k) * turn / 12, true
+		}
+	}
+	for k := -2; k <= 2; k++ {
+		if eighthSines[(k+8)%8] == x {
+			return float64(k) * turn / 8, true
+		}
+	}
+	return 0, false
+}
+
+// isPole reports whether the angle x is an odd multiple of a quarter turn,
+// where the tangent is undefined. In radians x must be the float64 nearest
+// to such a multiple of pi/2.
+func (c *Calculator) isPole(x float64) bool {
+	if c.angleMode != Radians {
+		cos, ok := c.exactSin(x, 1)
+		return ok && cos == 0
+	}
+	k := math.Round(x / (math.Pi / 2))
+	return math.Mod(k, 2) != 0 && k*(math.Pi/2) == x
+}
@@ -0,0 +1,148 @@
+// angle_test.go
+package calculator
+
+import (
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// ANGLE MODE TESTS
+// These tests verify that trigonometric functions honour the degree, radian and
+// gradian angle modes for both their inputs and their results
+// =============================================================================
+
+// TestParseAngleMode verifies short and long angle mode names and rejection of unknown names
+func TestParseAngleMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected AngleMode
+	}{
+		{"deg", Degrees},
+		{"Degrees", Degrees},
+		{"rad", Radians},
+		{"radians", Radians},
+		{"grad", Gradians},
+		{"gon", Gradians},
+	}
+
+	for _, test := range tests {
+		mode, err := ParseAngleMode(test.name)
+		if err != nil || mode != test.expected {
+			t.Errorf("ParseAngleMode(%q): expected %s, got %s (err: %v)", test.name, test.expected, mode, err)
+		}
+	}
+
+	if _, err := ParseAngleMode("turns"); err == nil {
+		t.Error("Expected error for unknown angle mode")
+	}
+}
+
+// TestDefaultAngleMode verifies a new Calculator works in radians
+func TestDefaultAngleMode(t *testing.T) {
+	calc := New()
+	if calc.AngleMode() != Radians {
+		t.Errorf("Expected default angle mode rad, got %s", calc.AngleMode())
+	}
+}
+
+// TestDegreeMode verifies trigonometric inputs and inverse results in degrees,
+// including exact results at multiples of 90 degrees
+func TestDegreeMode(t *testing.T) {
+	calc := New()
+	calc.SetAngleMode(Degrees)
+
+	if result := calc.Sin(180); result != 0 {
+		t.Errorf("Expected sin(180) = 0 exactly, got %g", result)
+	}
+	if result := calc.Cos(-90); result != 0 {
+		t.Errorf("Expected cos(-90) = 0 exactly, got %g", result)
+	}
+	if result := calc.Sin(-450); result != -1 {
+		t.Errorf("Expected sin(-450) = -1, got %g", result)
+	}
+	if result := calc.Sin(30); result != 0.5 {
+		t.Errorf("Expected sin(30) = 0.5 exactly, got %g", result)
+	}
+	if result := calc.Cos(420); result != 0.5 {
+		t.Errorf("Expected cos(420) = 0.5 exactly, got %g", result)
+	}
+	if result := calc.Sin(-135); result != -math.Sqrt2/2 {
+		t.Errorf("Expected sin(-135) = -sqrt(2)/2, got %g", result)
+	}
+	if result, err := calc.Tan(45); err != nil || result != 1 {
+		t.Errorf("Expected tan(45) = 1 exactly, got %g (err: %v)", result, err)
+	}
+	for _, pole := range []float64{90, -90, 270, 450} {
+		if _, err := calc.Tan(pole); err == nil {
+			t.Errorf("Expected tan(%g) to be undefined", pole)
+		}
+	}
+	if result, err := calc.Asin(1); err != nil || result != 90 {
+		t.Errorf("Expected asin(1) = 90, got %g (err: %v)", result, err)
+	}
+	if result, err := calc.Asin(0.5); err != nil || result != 30 {
+		t.Errorf("Expected asin(0.5) = 30 exactly, got %g (err: %v)", result, err)
+	}
+	if result, err := calc.Acos(0.5); err != nil || result != 60 {
+		t.Errorf("Expected acos(0.5) = 60 exactly, got %g (err: %v)", result, err)
+	}
+	if result, err := calc.Asin(math.Sqrt(3) / 2); err != nil || result != 60 {
+		t.Errorf("Expected asin(sqrt(3)/2) = 60 exactly, got %g (err: %v)", result, err)
+	}
+	if result := calc.Atan(-1); result != -45 {
+		t.Errorf("Expected atan(-1) = -45, got %g", result)
+	}
+	if result := calc.Atan2(1, -1); !floatEquals(result, 135, 1e-12) {
+		t.Errorf("Expected atan2(1, -1) = 135, got %g", result)
+	}
+}
+
+// TestGradianMode verifies trigonometric inputs and inverse results in gradians
+func TestGradianMode(t *testing.T) {
+	calc := New()
+	calc.SetAngleMode(Gradians)
+
+	if result := calc.Sin(100); result != 1 {
+		t.Errorf("Expected sin(100 grad) = 1, got %g", result)
+	}
+	if result := calc.Cos(50); !floatEquals(result, math.Sqrt2/2, 1e-12) {
+		t.Errorf("Expected cos(50 grad) = sqrt(2)/2, got %g", result)
+	}
+	if result, err := calc.Acos(0); err != nil || !floatEquals(result, 100, 1e-12) {
+		t.Errorf("Expected acos(0) = 100 grad, got %g (err: %v)", result, err)
+	}
+}
+
+// TestLargeAngles verifies large degree and gradian inputs are reduced modulo a turn
+func TestLargeAngles(t *testing.T) {
+	calc := New()
+	calc.SetAngleMode(Degrees)
+	// 1e20 is exactly 280 modulo 360
+	if result := calc.Sin(1e20); !floatEquals(result, math.Sin(280*math.Pi/180), 1e-12) {
+		t.Errorf("Expected sin(1e20 deg) = sin(280 deg), got %g", result)
+	}
+	if result := calc.Cos(-1e20); !floatEquals(result, math.Cos(280*math.Pi/180), 1e-12) {
+		t.Errorf("Expected cos(-1e20 deg) = cos(280 deg), got %g", result)
+	}
+
+	calc.SetAngleMode(Gradians)
+	// 1e15 + 7 is exactly 7 modulo 400
+	if result := calc.Sin(1e15 + 7); !floatEquals(result, math.Sin(7*math.Pi/200), 1e-12) {
+		t.Errorf("Expected sin(1e15 + 7 grad) = sin(7 grad), got %g", result)
+	}
+	if result, err := calc.Tan(-1e15 - 7); err != nil || !floatEquals(result, math.Tan(-7*math.Pi/200), 1e-12) {
+		t.Errorf("Expected tan(-1e15 - 7 grad) = tan(-7 grad), got %g (err: %v)", result, err)
+	}
+}
+
+// TestRadianModeUnchanged verifies radian mode does not snap values near quarter turns
+func TestRadianModeUnchanged(t *testing.T) {
+	calc := New()
+	if result := calc.Sin(math.Pi); result != math.Sin(math.Pi) {
+		t.Errorf("Expected radian sin to match math.Sin, got %g", result)
+	}
+	if result := calc.Atan(1); !floatEquals(result, math.Pi/4, 1e-12) {
+		t.Errorf("Expected atan(1) = pi/4, got %g", result)
+	}
+}
@@ -0,0 +1,401 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// guardBits is the extra working precision used inside multi-step big.Float
+// algorithms so that the final rounding to the requested precision is accurate
+const guardBits = 64
+
+// MaxPrecisionDigits is the largest number of significant digits accepted for
+// arbitrary precision arithmetic; series such as exp and ln slow down sharply
+// beyond it
+const MaxPrecisionDigits = 1000
+
+// Precision returns the mantissa size in bits used for arbitrary precision
+// arithmetic; zero means the calculator works in float64
+func (c *Calculator) Precision() uint {
+	return c.precision
+}
+
+// SetPrecision sets the mantissa size in bits used for arbitrary precision
+// arithmetic; zero switches back to float64. Sizes beyond MaxPrecisionDigits are
+// reduced to it.
+func (c *Calculator) SetPrecision(bits uint) {
+	c.precision = min(bits, BitsForDigits(MaxPrecisionDigits))
+}
+
+// ValidatePrecision checks a number of significant digits requested for
+// arbitrary precision arithmetic
+func ValidatePrecision(digits int) error {
+	if digits < 1 || digits > MaxPrecisionDigits {
+		return fmt.Errorf("precision must be 1 to %d digits, got %d", MaxPrecisionDigits, digits)
+	}
+	return nil
+}
+
+// RoundingMode returns the rounding mode used for arbitrary precision arithmetic
+func (c *Calculator) RoundingMode() big.RoundingMode {
+	return c.rounding
+}
+
+// SetRoundingMode sets the rounding mode used for arbitrary precision arithmetic
+func (c *Calculator) SetRoundingMode(mode big.RoundingMode) {
+	c.rounding = mode
+}
+
+// roundingModes maps the names accepted by ParseRoundingMode to big.Float rounding modes
+var roundingModes = map[string]big.RoundingMode{
+	"nearest-even": big.ToNearestEven,
+	"nearest-away": big.ToNearestAway,
+	"zero":         big.ToZero,
+	"away":         big.AwayFromZero,
+	"down":         big.ToNegativeInf,
+	"up":           big.ToPositiveInf,
+}
+
+// ParseRoundingMode converts a name such as "nearest-even", "zero" or "up" into a big.RoundingMode
+func ParseRoundingMode(name string) (big.RoundingMode, error) {
+	mode, ok := roundingModes[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return big.ToNearestEven, fmt.Errorf("unknown rounding mode %q (expected nearest-even, nearest-away, zero, away, down or up)", name)
+	}
+	return mode, nil
+}
+
+// RoundingModeName returns the name of a rounding mode as accepted by ParseRoundingMode
+func RoundingModeName(mode big.RoundingMode) string {
+	for name, m := range roundingModes {
+		if m == mode {
+			return name
+		}
+	}
+	return mode.String()
+}
+
+// BitsForDigits returns the mantissa size needed to hold the given number of significant decimal digits
+func BitsForDigits(digits int) uint {
+	return uint(math.Ceil(float64(digits) * math.Log2(10)))
+}
+
+// DigitsForBits returns the number of significant decimal digits a mantissa of the given size can hold
+func DigitsForBits(bits uint) int {
+	return int(float64(bits) * math.Log10(2))
+}
+
+// newBig returns a zero big.Float configured with the calculator's precision and rounding mode
+func (c *Calculator) newBig() *big.Float {
+	return new(big.Float).SetPrec(c.workingPrecision()).SetMode(c.rounding)
+}
+
+// workingPrecision returns the configured precision, or the float64 mantissa size when
+// arbitrary precision is disabled so that Big* methods remain usable
+func (c *Calculator) workingPrecision() uint {
+	if c.precision == 0 {
+		return 53
+	}
+	return c.precision
+}
+
+// ParseBig parses a decimal literal directly into a big.Float, so that values
+// such as 0.1 are not first rounded to float64
+func (c *Calculator) ParseBig(s string) (*big.Float, error) {
+	f, _, err := big.ParseFloat(s, 10, c.workingPrecision(), c.rounding)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// BigFromFloat converts a float64 using its shortest decimal representation, so
+// 0.1 becomes the decimal 0.1 rather than its binary approximation, with error
+// handling for NaN which big.Float cannot represent
+func (c *Calculator) BigFromFloat(x float64) (*big.Float, error) {
+	if math.IsNaN(x) {
+		return nil, errors.New("NaN has no arbitrary precision representation")
+	}
+	if math.IsInf(x, 0) {
+		return c.newBig().SetInf(x < 0), nil
+	}
+	return c.ParseBig(strconv.FormatFloat(x, 'g', -1, 64))
+}
+
+// BigAdd performs arbitrary precision addition
+func (c *Calculator) BigAdd(a, b *big.Float) (*big.Float, error) {
+	if a.IsInf() && b.IsInf() && a.Signbit() != b.Signbit() {
+		return nil, errors.New("infinity minus infinity is undefined")
+	}
+	return c.newBig().Add(a, b), nil
+}
+
+// BigSubtract performs arbitrary precision subtraction
+func (c *Calculator) BigSubtract(a, b *big.Float) (*big.Float, error) {
+	if a.IsInf() && b.IsInf() && a.Signbit() == b.Signbit() {
+		return nil, errors.New("infinity minus infinity is undefined")
+	}
+	return c.newBig().Sub(a, b), nil
+}
+
+// BigMultiply performs arbitrary precision multiplication
+func (c *Calculator) BigMultiply(a, b *big.Float) (*big.Float, error) {
+	if (a.IsInf() && b.Sign() == 0) || (b.IsInf() && a.Sign() == 0) {
+		return nil, errors.New("zero times infinity is undefined")
+	}
+	return c.newBig().Mul(a, b), nil
+}
+
+// BigDivide performs arbitrary precision division with error handling for division by zero
+func (c *Calculator) BigDivide(a, b *big.Float) (*big.Float, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	if a.IsInf() && b.IsInf() {
+		return nil, errors.New("infinity divided by infinity is undefined")
+	}
+	return c.newBig().Quo(a, b), nil
+}
+
+// BigQuotient performs arbitrary precision division truncated toward zero, the
+// counterpart of BigModFloat, with error handling for division by zero
+func (c *Calculator) BigQuotient(a, b *big.Float) (*big.Float, error) {
+	q, err := c.BigDivide(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if q.IsInf() {
+		return q, nil
+	}
+	qi, _ := q.Int(nil)
+	return c.newBig().SetInt(qi), nil
+}
+
+// BigModFloat performs arbitrary precision modulus with the sign of the dividend,
+// matching ModFloat, with error handling for modulus by zero
+func (c *Calculator) BigModFloat(a, b *big.Float) (*big.Float, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("modulus by zero")
+	}
+	if a.IsInf() || b.IsInf() {
+		return nil, errors.New("modulus with infinite operands is undefined")
+	}
+
+	// The quotient is truncated to an integer exactly, then the remainder is computed
+	// with enough precision to be exact before the final rounding
+	prec := a.MinPrec() + b.MinPrec() + guardBits
+	exp := a.MantExp(nil) - b.MantExp(nil)
+	if exp > 0 {
+		prec += uint(exp)
+	}
+	q := new(big.Float).SetPrec(prec).Quo(a, b)
+	qi, _ := q.Int(nil)
+	qf := new(big.Float).SetPrec(prec).SetInt(qi)
+	r := new(big.Float).SetPrec(prec).Mul(qf, b)
+	r.Sub(a, r)
+
+	// Correct for rounding of the quotient so that |r| < |b| and r has a's sign
+	absB := new(big.Float).Abs(b)
+	for r.Sign() != 0 && r.Signbit() != a.Signbit() {
+		if a.Signbit() {
+			r.Sub(r, absB)
+		} else {
+			r.Add(r, absB)
+		}
+	}
+	for new(big.Float).Abs(r).Cmp(absB) >= 0 {
+		if a.Signbit() {
+			r.Add(r, absB)
+		} else {
+			r.Sub(r, absB)
+		}
+	}
+	return c.newBig().Set(r), nil
+}
+
+// BigSqrt performs arbitrary precision square root with error handling for negative numbers
+func (c *Calculator) BigSqrt(a *big.Float) (*big.Float, error) {
+	if a.Sign() < 0 {
+		return nil, errors.New("square root of negative number")
+	}
+	if a.IsInf() {
+		return c.newBig().SetInf(false), nil
+	}
+	return c.newBig().Sqrt(a), nil
+}
+
+// BigPower raises base to exponent in arbitrary precision. Integer exponents are
+// computed exactly by repeated squaring (up to the final rounding); other exponents
+// use exp(exponent * ln(base)) and require a positive base.
+func (c *Calculator) BigPower(base, exponent *big.Float) (*big.Float, error) {
+	if base.IsInf() || exponent.IsInf() {
+		return nil, errors.New("power with infinite operands is not supported in arbitrary precision")
+	}
+	if exponent.IsInt() {
+		if n, acc := exponent.Int64(); acc == big.Exact {
+			return c.bigIntPower(base, n)
+		}
+	}
+	if base.Sign() == 0 {
+		if exponent.Sign() < 0 {
+			return nil, errors.New("zero raised to a negative power")
+		}
+		return c.newBig(), nil
+	}
+	if base.Sign() < 0 {
+		return nil, errors.New("negative base with non-integer exponent")
+	}
+
+	prec := c.workingPrecision() + guardBits
+	ln := bigLn(base, prec)
+	y := new(big.Float).SetPrec(prec).Mul(exponent, ln)
+	return c.newBig().Set(bigExp(y, prec)), nil
+}
+
+// bigIntPower computes base^n by binary exponentiation
+func (c *Calculator) bigIntPower(base *big.Float, n int64) (*big.Float, error) {
+	if base.Sign() == 0 && n < 0 {
+		return nil, errors.New("zero raised to a negative power")
+	}
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+
+	prec := c.workingPrecision() + guardBits
+	result := new(big.Float).SetPrec(prec).SetInt64(1)
+	square := new(big.Float).SetPrec(prec).Set(base)
+	for n > 0 {
+		if n&1 == 1 {
+			result.Mul(result, square)
+		}
+		n >>= 1
+		if n > 0 {
+			square.Mul(square, square)
+		}
+	}
+	if negative {
+		result.Quo(new(big.Float).SetPrec(prec).SetInt64(1), result)
+	}
+	return c.newBig().Set(result), nil
+}
+
+// BigExp returns e raised to the power of x in arbitrary precision
+func (c *Calculator) BigExp(x *big.Float) *big.Float {
+	return c.newBig().Set(bigExp(x, c.workingPrecision()+guardBits))
+}
+
+// BigLn returns the natural logarithm of x in arbitrary precision with error handling for non-positive inputs
+func (c *Calculator) BigLn(x *big.Float) (*big.Float, error) {
+	if x.Sign() <= 0 {
+		return nil, errors.New("logarithm of non-positive number")
+	}
+	return c.newBig().Set(bigLn(x, c.workingPrecision()+guardBits)), nil
+}
+
+// BigPi returns pi to the calculator's precision
+func (c *Calculator) BigPi() *big.Float {
+	return c.newBig().Set(bigPi(c.workingPrecision() + guardBits))
+}
+
+// bigExp computes e^x by halving the argument until it is small, summing the
+// Taylor series, and squaring the result back up
+func bigExp(x *big.Float, prec uint) *big.Float {
+	if x.Sign() == 0 {
+		return new(big.Float).SetPrec(prec).SetInt64(1)
+	}
+	if x.Sign() < 0 {
+		pos := bigExp(new(big.Float).SetPrec(prec).Neg(x), prec)
+		return new(big.Float).SetPrec(prec).Quo(new(big.Float).SetPrec(prec).SetInt64(1), pos)
+	}
+
+	// Reduce to r = x / 2^k with r < 2^-8
+	k := x.MantExp(nil) + 8
+	if k < 0 {
+		k = 0
+	}
+	work := prec + uint(k)
+	r := new(big.Float).SetPrec(work).SetMantExp(x, -k)
+
+	sum := new(big.Float).SetPrec(work).SetInt64(1)
+	term := new(big.Float).SetPrec(work).SetInt64(1)
+	epsilon := new(big.Float).SetPrec(work).SetMantExp(big.NewFloat(1), -int(work))
+	for i := int64(1); ; i++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetPrec(work).SetInt64(i))
+		sum.Add(sum, term)
+		if term.Cmp(epsilon) < 0 {
+			break
+		}
+	}
+	for i := 0; i < k; i++ {
+		sum.Mul(sum, sum)
+	}
+	return sum.SetPrec(prec)
+}
+
+// bigLn computes ln(x) for x > 0 by Newton iteration on exp, starting from the
+// float64 logarithm of the mantissa and adding the binary exponent times ln 2
+func bigLn(x *big.Float, prec uint) *big.Float {
+	mant := new(big.Float).SetPrec(prec)
+	exp := x.MantExp(mant) // x = mant * 2^exp with mant in [0.5, 1)
+
+	mf, _ := mant.Float64()
+	y := new(big.Float).SetPrec(prec).SetFloat64(math.Log(mf))
+	two := new(big.Float).SetPrec(prec).SetInt64(2)
+	// y' = y + 2 (m - e^y) / (m + e^y) converges cubically from a 53 bit start
+	for i := 0; i < 64; i++ {
+		ey := bigExp(y, prec)
+		num := new(big.Float).SetPrec(prec).Sub(mant, ey)
+		den := new(big.Float).SetPrec(prec).Add(mant, ey)
+		delta := new(big.Float).SetPrec(prec).Quo(num, den)
+		delta.Mul(delta, two)
+		y.Add(y, delta)
+		if delta.Sign() == 0 || delta.MantExp(nil) < y.MantExp(nil)-int(prec) {
+			break
+		}
+	}
+	if exp != 0 {
+		// ln 2 = -ln 0.5, and 0.5 has a zero binary exponent so this does not recurse further
+		half := new(big.Float).SetPrec(prec).SetFloat64(0.5)
+		ln2 := bigLn(half, prec)
+		ln2.Mul(ln2, new(big.Float).SetPrec(prec).SetInt64(int64(-exp)))
+		y.Add(y, ln2)
+	}
+	return y
+}
+
+// bigPi computes pi with Machin's formula, pi = 16 atan(1/5) - 4 atan(1/239)
+func bigPi(prec uint) *big.Float {
+	a := bigAtanInv(5, prec)
+	a.Mul(a, new(big.Float).SetPrec(prec).SetInt64(16))
+	b := bigAtanInv(239, prec)
+	b.Mul(b, new(big.Float).SetPrec(prec).SetInt64(4))
+	return a.Sub(a, b)
+}
+
+// bigAtanInv computes atan(1/n) from its Taylor series
+func bigAtanInv(n int64, prec uint) *big.Float {
+	x := new(big.Float).SetPrec(prec).Quo(big.NewFloat(1), new(big.Float).SetPrec(prec).SetInt64(n))
+	x2 := new(big.Float).SetPrec(prec).Mul(x, x)
+	power := new(big.Float).SetPrec(prec).Set(x)
+	sum := new(big.Float).SetPrec(prec).Set(x)
+	epsilon := new(big.Float).SetPrec(prec).SetMantExp(big.NewFloat(1), -int(prec))
+	for k := int64(1); ; k++ {
+		power.Mul(power, x2)
+		term := new(big.Float).SetPrec(prec).Quo(power, new(big.Float).SetPrec(prec).SetInt64(2*k+1))
+		if k%2 == 1 {
+			sum.Sub(sum, term)
+		} else {
+			sum.Add(sum, term)
+		}
+		if term.Cmp(epsilon) < 0 {
+			break
+		}
+	}
+	return sum
+}
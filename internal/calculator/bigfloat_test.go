@@ -0,0 +1,228 @@
+// bigfloat_test.go
+package calculator
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// bigText formats a big.Float with the number of digits its precision holds
+func bigText(x *big.Float) string {
+	return x.Text('g', DigitsForBits(x.Prec()))
+}
+
+// mustParseBig parses a decimal literal at the calculator's precision or fails the test
+func mustParseBig(t *testing.T, calc *Calculator, s string) *big.Float {
+	t.Helper()
+	x, err := calc.ParseBig(s)
+	if err != nil {
+		t.Fatalf("ParseBig(%q) failed: %v", s, err)
+	}
+	return x
+}
+
+// =============================================================================
+// PRECISION CONFIGURATION TESTS
+// These tests verify conversion between decimal digits and mantissa bits and the
+// parsing of rounding mode names
+// =============================================================================
+
+// TestDigitsBitsRoundTrip verifies that converting digits to bits and back never loses digits
+func TestDigitsBitsRoundTrip(t *testing.T) {
+	for digits := 1; digits <= 500; digits++ {
+		if got := DigitsForBits(BitsForDigits(digits)); got < digits {
+			t.Errorf("Expected at least %d digits from %d bits, got %d", digits, BitsForDigits(digits), got)
+		}
+	}
+}
+
+// TestValidatePrecision verifies the accepted range of significant digits
+func TestValidatePrecision(t *testing.T) {
+	for _, digits := range []int{1, 50, MaxPrecisionDigits} {
+		if err := ValidatePrecision(digits); err != nil {
+			t.Errorf("ValidatePrecision(%d): unexpected error %v", digits, err)
+		}
+	}
+	for _, digits := range []int{0, -5, MaxPrecisionDigits + 1, 100000000} {
+		if err := ValidatePrecision(digits); err == nil {
+			t.Errorf("ValidatePrecision(%d): expected an error", digits)
+		}
+	}
+
+	calc := New()
+	calc.SetPrecision(1 << 30)
+	if calc.Precision() != BitsForDigits(MaxPrecisionDigits) {
+		t.Errorf("SetPrecision: expected the maximum to cap at %d bits, got %d", BitsForDigits(MaxPrecisionDigits), calc.Precision())
+	}
+}
+
+// TestParseRoundingMode verifies rounding mode names and rejection of unknown names
+func TestParseRoundingMode(t *testing.T) {
+	if mode, err := ParseRoundingMode("Zero"); err != nil || mode != big.ToZero {
+		t.Errorf("Expected ToZero, got %v (err: %v)", mode, err)
+	}
+	if name := RoundingModeName(big.ToPositiveInf); name != "up" {
+		t.Errorf("Expected name up for ToPositiveInf, got %q", name)
+	}
+	if _, err := ParseRoundingMode("sideways"); err == nil {
+		t.Error("Expected error for unknown rounding mode")
+	}
+}
+
+// =============================================================================
+// ARBITRARY PRECISION ARITHMETIC TESTS
+// These tests verify that the Big* operations avoid float64 artifacts and report
+// the same errors as their float64 counterparts
+// =============================================================================
+
+// TestBigArithmetic verifies 0.1 + 0.2 and 2^64 + 1 are exact at 50 digits
+func TestBigArithmetic(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(BitsForDigits(50))
+
+	sum, err := calc.BigAdd(mustParseBig(t, calc, "0.1"), mustParseBig(t, calc, "0.2"))
+	if err != nil || bigText(sum) != "0.3" {
+		t.Errorf("Expected 0.1 + 0.2 = 0.3, got %s (err: %v)", bigText(sum), err)
+	}
+
+	power, err := calc.BigPower(mustParseBig(t, calc, "2"), mustParseBig(t, calc, "64"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	total, _ := calc.BigAdd(power, mustParseBig(t, calc, "1"))
+	if bigText(total) != "18446744073709551617" {
+		t.Errorf("Expected 2^64 + 1 = 18446744073709551617, got %s", bigText(total))
+	}
+
+	quotient, err := calc.BigDivide(mustParseBig(t, calc, "1"), mustParseBig(t, calc, "3"))
+	if err != nil || bigText(quotient) != "0.33333333333333333333333333333333333333333333333333" {
+		t.Errorf("Expected 1/3 to 50 digits, got %s (err: %v)", bigText(quotient), err)
+	}
+}
+
+// TestBigDivideAndModByZero verifies division and modulus by zero are errors
+func TestBigDivideAndModByZero(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(100)
+
+	if _, err := calc.BigDivide(mustParseBig(t, calc, "1"), mustParseBig(t, calc, "0")); err == nil {
+		t.Error("Expected division by zero error")
+	}
+	if _, err := calc.BigModFloat(mustParseBig(t, calc, "1"), mustParseBig(t, calc, "0")); err == nil {
+		t.Error("Expected modulus by zero error")
+	}
+}
+
+// TestBigModFloat verifies the remainder takes the sign of the dividend and stays exact for large dividends
+func TestBigModFloat(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(BitsForDigits(40))
+
+	tests := []struct {
+		a, b, expected string
+	}{
+		{"10.5", "3", "1.5"},
+		{"-10.5", "3", "-1.5"},
+		{"10.5", "-3", "1.5"},
+		{"1e30", "7", "1"},
+		{"7.25", "0.25", "0"},
+	}
+
+	for _, test := range tests {
+		r, err := calc.BigModFloat(mustParseBig(t, calc, test.a), mustParseBig(t, calc, test.b))
+		if err != nil {
+			t.Errorf("%s %% %s failed: %v", test.a, test.b, err)
+			continue
+		}
+		if got := bigText(r); got != test.expected {
+			t.Errorf("Expected %s %% %s = %s, got %s", test.a, test.b, test.expected, got)
+		}
+	}
+}
+
+// =============================================================================
+// ARBITRARY PRECISION FUNCTION TESTS
+// These tests verify square roots, powers, logarithms and pi against known digits
+// =============================================================================
+
+// TestBigSqrt verifies sqrt(2) at 50 digits and the error for negative numbers. The last
+// digit of a 167 bit mantissa is rounded, so the expected value ends ...875377 rather
+// than the true ...8753769.
+func TestBigSqrt(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(BitsForDigits(50))
+
+	root, err := calc.BigSqrt(mustParseBig(t, calc, "2"))
+	if err != nil || bigText(root) != "1.414213562373095048801688724209698078569671875377" {
+		t.Errorf("Expected sqrt(2) to 50 digits, got %s (err: %v)", bigText(root), err)
+	}
+	if _, err := calc.BigSqrt(mustParseBig(t, calc, "-1")); err == nil {
+		t.Error("Expected square root of negative number error")
+	}
+}
+
+// TestBigPower verifies integer, negative and fractional exponents and their error cases
+func TestBigPower(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(BitsForDigits(50))
+
+	tests := []struct {
+		base, exponent, expected string
+	}{
+		{"3", "40", "12157665459056928801"},
+		{"2", "-3", "0.125"},
+		{"-2", "5", "-32"},
+		{"2", "0.5", "1.414213562373095048801688724209698078569671875377"},
+		{"10", "0", "1"},
+	}
+
+	for _, test := range tests {
+		result, err := calc.BigPower(mustParseBig(t, calc, test.base), mustParseBig(t, calc, test.exponent))
+		if err != nil || bigText(result) != test.expected {
+			t.Errorf("Expected %s^%s = %s, got %s (err: %v)", test.base, test.exponent, test.expected, bigText(result), err)
+		}
+	}
+
+	if _, err := calc.BigPower(mustParseBig(t, calc, "0"), mustParseBig(t, calc, "-1")); err == nil {
+		t.Error("Expected error for zero raised to a negative power")
+	}
+	if _, err := calc.BigPower(mustParseBig(t, calc, "-8"), mustParseBig(t, calc, "0.5")); err == nil {
+		t.Error("Expected error for negative base with non-integer exponent")
+	}
+}
+
+// TestBigTranscendental verifies pi, e and ln 2 against their known first 50 digits
+func TestBigTranscendental(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(BitsForDigits(50))
+
+	if got := bigText(calc.BigPi()); got != "3.1415926535897932384626433832795028841971693993751" {
+		t.Errorf("Unexpected digits of pi: %s", got)
+	}
+	// The 50th digit of e rounds to 0, which 'g' formatting trims
+	if got := bigText(calc.BigExp(mustParseBig(t, calc, "1"))); got != "2.7182818284590452353602874713526624977572470937" {
+		t.Errorf("Unexpected digits of e: %s", got)
+	}
+	ln2, err := calc.BigLn(mustParseBig(t, calc, "2"))
+	if err != nil || bigText(ln2) != "0.69314718055994530941723212145817656807550013436025" {
+		t.Errorf("Unexpected digits of ln 2: %s (err: %v)", bigText(ln2), err)
+	}
+	if _, err := calc.BigLn(mustParseBig(t, calc, "0")); err == nil {
+		t.Error("Expected logarithm of non-positive number error")
+	}
+}
+
+// TestBigFromFloat verifies float64 values convert through their shortest decimal form
+func TestBigFromFloat(t *testing.T) {
+	calc := New()
+	calc.SetPrecision(BitsForDigits(30))
+
+	x, err := calc.BigFromFloat(0.1)
+	if err != nil || bigText(x) != "0.1" {
+		t.Errorf("Expected 0.1, got %s (err: %v)", bigText(x), err)
+	}
+	if _, err := calc.BigFromFloat(math.NaN()); err == nil {
+		t.Error("Expected error converting NaN")
+	}
+}
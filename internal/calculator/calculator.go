@@ -1,14 +1,28 @@
 // Package calculator provides basic arithmetic operations with proper error handling.
-// It supports addition, subtraction, multiplication, division, modulus, power, and square root operations.
+// It supports addition, subtraction, multiplication, division, modulus, power, and square root operations,
+// along with a standard library of trigonometric, hyperbolic, logarithmic and rounding functions,
+// arbitrary precision and exact rational variants of the core operations backed by math/big,
+// fixed-width integer and bitwise operations for programmer mode, complex128
+// operations for complex mode, statistics over lists of values and dense matrix
+// linear algebra.
 package calculator
 
 import (
 	"errors"
 	"math"
+	"math/big"
 )
 
 // Calculator represents a calculator that can perform basic arithmetic operations
-type Calculator struct{}
+type Calculator struct {
+	angleMode  AngleMode
+	numberMode NumberMode
+	precision  uint             // mantissa bits for Big* operations, 0 for float64 mode
+	rounding   big.RoundingMode // rounding mode for Big* operations
+	wordSize   WordSize         // integer width for Int* and bitwise operations
+	overflow   OverflowMode     // handling of Int* results that do not fit wordSize
+	ieee       IEEEMode         // handling of IEEE-754 edge cases in float64 results
+}
 
 // New creates and returns a new Calculator instance
 func New() *Calculator {
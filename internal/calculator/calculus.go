@@ -0,0 +1,290 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// IntegralResult describes a definite integral computed numerically
+type IntegralResult struct {
+	Value       float64
+	Error       float64 // estimate of the absolute error in Value
+	Intervals   int     // number of subintervals in the final subdivision
+	Evaluations int
+	Converged   bool // whether Error met the tolerance
+}
+
+// DerivativeResult describes a derivative computed numerically
+type DerivativeResult struct {
+	Value     float64
+	Error     float64 // estimate of the absolute error in Value
+	Step      float64 // initial step of the difference quotients
+	Converged bool    // whether Error met the tolerance
+}
+
+const (
+	// integralTolerance is the relative error at which integration stops. For
+	// integrals that cancel to nearly zero the error is measured against the
+	// integral of |f| instead, scaled by cancellationTolerance.
+	integralTolerance     = 1e-10
+	cancellationTolerance = 1e-14
+	// maxIntervals bounds the subdivision of the adaptive integration
+	maxIntervals = 500
+	// derivativeTolerance is the error at which a derivative is accepted,
+	// relative to the derivative or absolute when it is smaller than 1
+	derivativeTolerance = 1e-8
+	// richardsonSteps is the number of step sizes in the extrapolation tableau,
+	// each 1.4 times smaller than the last
+	richardsonSteps = 10
+)
+
+// Nodes and weights of the 7-point Gauss and 15-point Kronrod rules on [-1, 1].
+// The odd Kronrod nodes are the Gauss nodes; gaussWeights pairs with them and
+// the centre.
+var (
+	kronrodNodes = [8]float64{
+		0.991455371120812639206854697526329,
+		0.949107912342758524526189684047851,
+		0.864864423359769072789712788640926,
+		0.741531185599394439863864773280788,
+		0.586087235467691130294144845693013,
+		0.405845151377397166906606412076961,
+		0.207784955007898467600689403773245,
+		0,
+	}
+	kronrodWeights = [8]float64{
+		0.022935322010529224963732008058970,
+		0.063092092629978553290700663189204,
+		0.104790010322250183839876322541518,
+		0.140653259715525918745189590510238,
+		0.169004726639267902826583426598550,
+		0.190350578064785409913256402421014,
+		0.204432940075298892414161999234649,
+		0.209482141084727828012999174891714,
+	}
+	gaussWeights = [4]float64{
+		0.129484966168869693270611432679082,
+		0.279705391489276667901467771423780,
+		0.381830050505118944950369775488975,
+		0.417959183673469387755102040816327,
+	}
+)
+
+// interval is a piece of the subdivision with its Kronrod estimate, the
+// estimated error and the integral of |f| over it
+type interval struct {
+	a, b            float64
+	value, err, abs float64
+}
+
+// gaussKronrod applies the 15-point Gauss-Kronrod rule to f over [a, b],
+// estimating the error by the difference from the embedded 7-point Gauss rule
+func gaussKronrod(f Func, a, b float64) (interval, error) {
+	centre := (a + b) / 2
+	half := (b - a) / 2
+	at := func(x float64) (float64, error) {
+		fx, err := f(x)
+		if err != nil {
+			return 0, err
+		}
+		if math.IsNaN(fx) || math.IsInf(fx, 0) {
+			return 0, fmt.Errorf("integrand is not finite at %g", x)
+		}
+		return fx, nil
+	}
+
+	fc, err := at(centre)
+	if err != nil {
+		return interval{}, err
+	}
+	kronrod := kronrodWeights[7] * fc
+	gauss := gaussWeights[3] * fc
+	abs := kronrodWeights[7] * math.Abs(fc)
+	for i := 0; i < 7; i++ {
+		dx := half * kronrodNodes[i]
+		f1, err := at(centre - dx)
+		if err != nil {
+			return interval{}, err
+		}
+		f2, err := at(centre + dx)
+		if err != nil {
+			return interval{}, err
+		}
+		kronrod += kronrodWeights[i] * (f1 + f2)
+		abs += kronrodWeights[i] * (math.Abs(f1) + math.Abs(f2))
+		if i%2 == 1 {
+			gauss += gaussWeights[i/2] * (f1 + f2)
+		}
+	}
+	return interval{
+		a: a, b: b,
+		value: kronrod * half,
+		err:   math.Abs((kronrod - gauss) * half),
+		abs:   abs * math.Abs(half),
+	}, nil
+}
+
+// Integrate computes the integral of f from a to b by adaptive 15-point
+// Gauss-Kronrod quadrature, repeatedly bisecting the subinterval with the
+// largest error estimate. Infinite bounds are mapped onto a finite interval.
+// A result whose error estimate misses the tolerance is returned with
+// Converged false rather than as an error, so that the caller can report it.
+func (c *Calculator) Integrate(f Func, a, b float64) (IntegralResult, error) {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return IntegralResult{}, fmt.Errorf("invalid bounds %g and %g", a, b)
+	}
+	if a == b {
+		return IntegralResult{Converged: true}, nil
+	}
+	if a > b {
+		r, err := c.Integrate(f, b, a)
+		r.Value = -r.Value
+		return r, err
+	}
+	g, a, b := finiteInterval(f, a, b)
+
+	first, err := gaussKronrod(g, a, b)
+	if err != nil {
+		return IntegralResult{}, err
+	}
+	pieces := []interval{first}
+	result := IntegralResult{Evaluations: 15}
+	for {
+		var value, errSum, abs float64
+		worst := 0
+		for i, p := range pieces {
+			value += p.value
+			errSum += p.err
+			abs += p.abs
+			if p.err > pieces[worst].err {
+				worst = i
+			}
+		}
+		result.Value, result.Error, result.Intervals = value, errSum, len(pieces)
+		tolerance := math.Max(integralTolerance*math.Abs(value), cancellationTolerance*abs)
+		if errSum <= tolerance {
+			result.Converged = true
+			return result, nil
+		}
+		p := pieces[worst]
+		mid := (p.a + p.b) / 2
+		if len(pieces) >= maxIntervals || !(p.a < mid && mid < p.b) {
+			// Out of subintervals, or the worst one cannot be split any further
+			return result, nil
+		}
+		left, err := gaussKronrod(g, p.a, mid)
+		if err != nil {
+			return IntegralResult{}, err
+		}
+		right, err := gaussKronrod(g, mid, p.b)
+		if err != nil {
+			return IntegralResult{}, err
+		}
+		pieces[worst] = left
+		pieces = append(pieces, right)
+		result.Evaluations += 30
+	}
+}
+
+// finiteInterval maps an integral over an infinite interval onto one over a
+// finite interval by a change of variable, returning the new integrand and
+// bounds. Finite intervals are returned unchanged.
+func finiteInterval(f Func, a, b float64) (Func, float64, float64) {
+	// Subdivision only reaches the ends of the new interval when the integrand
+	// decays too slowly for the integral to converge
+	diverges := errors.New("integrand does not decay fast enough at infinity")
+	switch {
+	case math.IsInf(a, -1) && math.IsInf(b, 1):
+		// x = t / (1 - t^2) for t in (-1, 1)
+		return func(t float64) (float64, error) {
+			d := 1 - t*t
+			if d <= 0 {
+				return 0, diverges
+			}
+			fx, err := f(t / d)
+			return fx * (1 + t*t) / (d * d), err
+		}, -1, 1
+	case math.IsInf(b, 1):
+		// x = a + t / (1 - t) for t in [0, 1)
+		return func(t float64) (float64, error) {
+			d := 1 - t
+			if d <= 0 {
+				return 0, diverges
+			}
+			fx, err := f(a + t/d)
+			return fx / (d * d), err
+		}, 0, 1
+	case math.IsInf(a, -1):
+		// x = b - (1 - t) / t for t in (0, 1]
+		return func(t float64) (float64, error) {
+			if t <= 0 {
+				return 0, diverges
+			}
+			fx, err := f(b - (1-t)/t)
+			return fx / (t * t), err
+		}, 0, 1
+	}
+	return f, a, b
+}
+
+// Derivative computes the derivative of f at x by Ridders' method: central
+// differences with shrinking steps, combined by Richardson extrapolation, which
+// also yields an error estimate. The initial step shrinks until f is defined on
+// both sides of x. A result whose error estimate misses the tolerance is
+// returned with Converged false rather than as an error.
+func (c *Calculator) Derivative(f Func, x float64) (DerivativeResult, error) {
+	const shrink, shrink2 = 1.4, 1.4 * 1.4
+	scale := math.Max(1, math.Abs(x))
+	h := 0.1 * scale
+	central := func(h float64) (float64, error) {
+		above, err := f(x + h)
+		if err != nil {
+			return 0, err
+		}
+		below, err := f(x - h)
+		if err != nil {
+			return 0, err
+		}
+		return (above - below) / (2 * h), nil
+	}
+
+	d, err := central(h)
+	for err != nil && h > 1e-8*scale {
+		h /= 10
+		d, err = central(h)
+	}
+	if err != nil {
+		return DerivativeResult{}, fmt.Errorf("f is not defined on both sides of %g: %v", x, err)
+	}
+
+	// tableau[j][i] is the extrapolation of order j from step i
+	var tableau [richardsonSteps][richardsonSteps]float64
+	tableau[0][0] = d
+	result := DerivativeResult{Value: d, Error: math.Inf(1), Step: h}
+	step := h
+	for i := 1; i < richardsonSteps; i++ {
+		step /= shrink
+		if tableau[0][i], err = central(step); err != nil {
+			return DerivativeResult{}, err
+		}
+		factor := shrink2
+		for j := 1; j <= i; j++ {
+			tableau[j][i] = (tableau[j-1][i]*factor - tableau[j-1][i-1]) / (factor - 1)
+			factor *= shrink2
+			e := math.Max(math.Abs(tableau[j][i]-tableau[j-1][i]), math.Abs(tableau[j][i]-tableau[j-1][i-1]))
+			if e <= result.Error {
+				result.Error, result.Value = e, tableau[j][i]
+			}
+		}
+		// Stop once higher orders get worse, as rounding error takes over
+		if math.Abs(tableau[i][i]-tableau[i-1][i-1]) >= 2*result.Error {
+			break
+		}
+	}
+	if math.IsNaN(result.Value) || math.IsInf(result.Value, 0) {
+		return DerivativeResult{}, fmt.Errorf("derivative is not finite at %g", x)
+	}
+	result.Converged = result.Error <= derivativeTolerance*math.Max(1, math.Abs(result.Value))
+	return result, nil
+}
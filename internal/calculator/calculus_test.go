@@ -0,0 +1,114 @@
+// calculus_test.go
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// CALCULUS TESTS
+// These tests verify numerical integration and differentiation, their error
+// estimates and the reporting of results that miss the tolerance
+// =============================================================================
+
+// TestIntegrate verifies integrals over finite, reversed and infinite intervals
+func TestIntegrate(t *testing.T) {
+	calc := New()
+	plain := func(g func(float64) float64) Func {
+		return func(x float64) (float64, error) { return g(x), nil }
+	}
+
+	tests := []struct {
+		name     string
+		f        Func
+		a, b     float64
+		expected float64
+	}{
+		{"x^2 over [0, 1]", plain(func(x float64) float64 { return x * x }), 0, 1, 1.0 / 3},
+		{"sin over [0, pi]", plain(math.Sin), 0, math.Pi, 2},
+		{"sin over [pi, 0]", plain(math.Sin), math.Pi, 0, -2},
+		{"exp over [0, 1]", plain(math.Exp), 0, 1, math.E - 1},
+		{"1/sqrt(x) over [0, 1]", plain(func(x float64) float64 { return 1 / math.Sqrt(x) }), 0, 1, 2},
+		{"exp(-x) over [0, inf)", plain(func(x float64) float64 { return math.Exp(-x) }), 0, math.Inf(1), 1},
+		{"exp(-x^2) over the real line", plain(func(x float64) float64 { return math.Exp(-x * x) }), math.Inf(-1), math.Inf(1), math.Sqrt(math.Pi)},
+		{"1/x^2 over (-inf, -1]", plain(func(x float64) float64 { return 1 / (x * x) }), math.Inf(-1), -1, 1},
+		{"empty interval", plain(math.Exp), 2, 2, 0},
+	}
+	for _, test := range tests {
+		r, err := calc.Integrate(test.f, test.a, test.b)
+		if err != nil || !r.Converged || !floatEquals(r.Value, test.expected, 1e-9) {
+			t.Errorf("%s: expected %g, got %+v (err: %v)", test.name, test.expected, r, err)
+		}
+		if math.Abs(r.Value-test.expected) > r.Error+1e-15 {
+			t.Errorf("%s: error estimate %g is below the actual error %g", test.name, r.Error, math.Abs(r.Value-test.expected))
+		}
+	}
+
+	// sin(1/x) oscillates without bound near 0, so the tolerance cannot be met
+	r, err := calc.Integrate(plain(func(x float64) float64 { return math.Sin(1 / x) }), 0, 1)
+	if err != nil || r.Converged || r.Intervals != maxIntervals {
+		t.Errorf("Expected an unconverged result over %d subintervals, got %+v (err: %v)", maxIntervals, r, err)
+	}
+	if _, err := calc.Integrate(plain(func(x float64) float64 { return 1 }), 0, math.Inf(1)); err == nil {
+		t.Error("Expected error for an integrand that does not decay at infinity")
+	}
+	if _, err := calc.Integrate(plain(func(x float64) float64 { return 1 / x }), -1, 1); err == nil {
+		t.Error("Expected error for an integrand that is infinite at a node")
+	}
+	undefined := errors.New("undefined")
+	if _, err := calc.Integrate(func(x float64) (float64, error) { return 0, undefined }, 0, 1); !errors.Is(err, undefined) {
+		t.Errorf("Expected the integrand's error, got %v", err)
+	}
+}
+
+// TestDerivative verifies derivatives, their error estimates and points at the
+// edge of the domain
+func TestDerivative(t *testing.T) {
+	calc := New()
+	plain := func(g func(float64) float64) Func {
+		return func(x float64) (float64, error) { return g(x), nil }
+	}
+
+	tests := []struct {
+		name     string
+		f        Func
+		x        float64
+		expected float64
+	}{
+		{"x^2 at 3", plain(func(x float64) float64 { return x * x }), 3, 6},
+		{"sin at 1", plain(math.Sin), 1, math.Cos(1)},
+		{"cos at 0", plain(math.Cos), 0, 0},
+		{"exp at 10", plain(math.Exp), 10, math.Exp(10)},
+		{"x^3 at -2", plain(func(x float64) float64 { return x * x * x }), -2, 12},
+	}
+	for _, test := range tests {
+		r, err := calc.Derivative(test.f, test.x)
+		if err != nil || !r.Converged || !floatEquals(r.Value, test.expected, 1e-9*math.Max(1, math.Abs(test.expected))) {
+			t.Errorf("%s: expected %g, got %+v (err: %v)", test.name, test.expected, r, err)
+		}
+	}
+
+	// ln is undefined below 0, so the step shrinks to stay inside the domain
+	ln := func(x float64) (float64, error) {
+		if x <= 0 {
+			return 0, errors.New("logarithm of non-positive number")
+		}
+		return math.Log(x), nil
+	}
+	if r, err := calc.Derivative(ln, 0.01); err != nil || !floatEquals(r.Value, 100, 1e-6) {
+		t.Errorf("Expected d/dx ln(x) at 0.01 = 100, got %+v (err: %v)", r, err)
+	}
+	if _, err := calc.Derivative(ln, 0); err == nil {
+		t.Error("Expected error differentiating at the edge of the domain")
+	}
+	// |x| has no derivative at 0, but the central differences agree on 0
+	if r, err := calc.Derivative(plain(math.Abs), 0); err != nil || r.Value != 0 {
+		t.Errorf("Expected d/dx |x| at 0 = 0, got %+v (err: %v)", r, err)
+	}
+	// sqrt(|x|) is steep at 0 and the estimates do not settle
+	if r, err := calc.Derivative(plain(func(x float64) float64 { return math.Sqrt(math.Abs(x - 1e-3)) }), 0); err != nil || r.Converged {
+		t.Errorf("Expected an unconverged derivative, got %+v (err: %v)", r, err)
+	}
+}
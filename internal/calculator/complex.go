@@ -0,0 +1,191 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+)
+
+// The C* methods are the complex128 counterparts of the basic operations and
+// functions, used in complex mode. They return principal values, so CSqrt(-4) is
+// 2i and CLn(-1) is πi. Angles taken or returned by the trigonometric functions
+// and CArg follow the angle mode like their real counterparts.
+
+// CAdd performs complex addition
+func (c *Calculator) CAdd(a, b complex128) complex128 {
+	return a + b
+}
+
+// CSubtract performs complex subtraction
+func (c *Calculator) CSubtract(a, b complex128) complex128 {
+	return a - b
+}
+
+// CMultiply performs complex multiplication
+func (c *Calculator) CMultiply(a, b complex128) complex128 {
+	return a * b
+}
+
+// CDivide performs complex division with error handling for division by zero
+func (c *Calculator) CDivide(a, b complex128) (complex128, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+// CPower raises base to a complex exponent with error handling for zero raised to
+// a power with a negative or non-real part
+func (c *Calculator) CPower(base, exponent complex128) (complex128, error) {
+	if base == 0 && (real(exponent) < 0 || imag(exponent) != 0) {
+		return 0, errors.New("zero raised to a negative or complex power is undefined")
+	}
+	if imag(exponent) == 0 {
+		n := real(exponent)
+		if imag(base) == 0 && (real(base) >= 0 || n == math.Trunc(n)) {
+			// Keep real powers such as 2^3 and (-2)^3 exact
+			return complex(math.Pow(real(base), n), 0), nil
+		}
+		if n == math.Trunc(n) && math.Abs(n) <= maxRatExponent {
+			// Repeated squaring keeps powers such as i^2 free of rounding in the zero part
+			result := complexIntPower(base, int(math.Abs(n)))
+			if n < 0 {
+				result = 1 / result
+			}
+			return result, nil
+		}
+	}
+	return cmplx.Pow(base, exponent), nil
+}
+
+// complexIntPower raises z to a non-negative integer power by repeated squaring
+func complexIntPower(z complex128, n int) complex128 {
+	result := complex(1, 0)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result *= z
+		}
+		z *= z
+	}
+	return result
+}
+
+// CSqrt returns the principal square root of z
+func (c *Calculator) CSqrt(z complex128) complex128 {
+	return cmplx.Sqrt(z)
+}
+
+// CExp returns e raised to the power z
+func (c *Calculator) CExp(z complex128) complex128 {
+	return cmplx.Exp(z)
+}
+
+// CLn returns the principal natural logarithm of z with error handling for zero
+func (c *Calculator) CLn(z complex128) (complex128, error) {
+	if z == 0 {
+		return 0, errors.New("logarithm of zero is undefined")
+	}
+	return cmplx.Log(z), nil
+}
+
+// CLog10 returns the principal base 10 logarithm of z with error handling for zero
+func (c *Calculator) CLog10(z complex128) (complex128, error) {
+	if z == 0 {
+		return 0, errors.New("logarithm of zero is undefined")
+	}
+	return cmplx.Log10(z), nil
+}
+
+// CLog returns the principal logarithm of z in the given base with error handling
+// for zero arguments and a base of zero or one
+func (c *Calculator) CLog(z, base complex128) (complex128, error) {
+	if base == 0 || base == 1 {
+		return 0, errors.New("logarithm base must not be 0 or 1")
+	}
+	ln, err := c.CLn(z)
+	if err != nil {
+		return 0, err
+	}
+	return ln / cmplx.Log(base), nil
+}
+
+// angleScale converts an angle in the current mode to radians
+func (c *Calculator) angleScale() complex128 {
+	return complex(c.toRadians(1), 0)
+}
+
+// CSin returns the sine of z given in the current angle mode
+func (c *Calculator) CSin(z complex128) complex128 {
+	return cmplx.Sin(z * c.angleScale())
+}
+
+// CCos returns the cosine of z given in the current angle mode
+func (c *Calculator) CCos(z complex128) complex128 {
+	return cmplx.Cos(z * c.angleScale())
+}
+
+// CTan returns the tangent of z given in the current angle mode with error
+// handling for the real poles, as for Tan
+func (c *Calculator) CTan(z complex128) (complex128, error) {
+	if imag(z) == 0 {
+		if _, err := c.Tan(real(z)); err != nil {
+			return 0, err
+		}
+	}
+	return cmplx.Tan(z * c.angleScale()), nil
+}
+
+// CAsin returns the principal arcsine of z in the current angle mode
+func (c *Calculator) CAsin(z complex128) complex128 {
+	return cmplx.Asin(z) / c.angleScale()
+}
+
+// CAcos returns the principal arccosine of z in the current angle mode
+func (c *Calculator) CAcos(z complex128) complex128 {
+	return cmplx.Acos(z) / c.angleScale()
+}
+
+// CAtan returns the principal arctangent of z in the current angle mode
+func (c *Calculator) CAtan(z complex128) complex128 {
+	return cmplx.Atan(z) / c.angleScale()
+}
+
+// CSinh returns the hyperbolic sine of z
+func (c *Calculator) CSinh(z complex128) complex128 {
+	return cmplx.Sinh(z)
+}
+
+// CCosh returns the hyperbolic cosine of z
+func (c *Calculator) CCosh(z complex128) complex128 {
+	return cmplx.Cosh(z)
+}
+
+// CTanh returns the hyperbolic tangent of z
+func (c *Calculator) CTanh(z complex128) complex128 {
+	return cmplx.Tanh(z)
+}
+
+// CAbs returns the modulus |z|
+func (c *Calculator) CAbs(z complex128) float64 {
+	return cmplx.Abs(z)
+}
+
+// CArg returns the argument (phase) of z in the current angle mode, in (-π, π] radians
+func (c *Calculator) CArg(z complex128) float64 {
+	return c.fromRadians(cmplx.Phase(z))
+}
+
+// CConj returns the complex conjugate of z
+func (c *Calculator) CConj(z complex128) complex128 {
+	return cmplx.Conj(z)
+}
+
+// Polar converts z to its modulus and argument, with the argument in the current angle mode
+func (c *Calculator) Polar(z complex128) (r, theta float64) {
+	return c.CAbs(z), c.CArg(z)
+}
+
+// Rect converts a modulus and an argument in the current angle mode to a complex number
+func (c *Calculator) Rect(r, theta float64) complex128 {
+	return cmplx.Rect(r, c.toRadians(theta))
+}
@@ -0,0 +1,119 @@
+// complex_test.go
+package calculator
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// complexClose reports whether two complex numbers agree to within tolerance
+func complexClose(a, b complex128) bool {
+	return cmplx.Abs(a-b) < 1e-12
+}
+
+// =============================================================================
+// COMPLEX ARITHMETIC TESTS
+// These tests verify complex mode operations and their principal values
+// =============================================================================
+
+// TestComplexArithmetic verifies the basic operations on complex numbers
+func TestComplexArithmetic(t *testing.T) {
+	calc := New()
+	a, b := complex(3, 4), complex(1, -2)
+
+	if r := calc.CAdd(a, b); r != complex(4, 2) {
+		t.Errorf("Expected (3+4i) + (1-2i) = 4+2i, got %v", r)
+	}
+	if r := calc.CSubtract(a, b); r != complex(2, 6) {
+		t.Errorf("Expected (3+4i) - (1-2i) = 2+6i, got %v", r)
+	}
+	if r := calc.CMultiply(a, b); r != complex(11, -2) {
+		t.Errorf("Expected (3+4i) * (1-2i) = 11-2i, got %v", r)
+	}
+	if r, err := calc.CDivide(a, b); err != nil || !complexClose(r, complex(-1, 2)) {
+		t.Errorf("Expected (3+4i) / (1-2i) = -1+2i, got %v (err: %v)", r, err)
+	}
+	if _, err := calc.CDivide(a, 0); err == nil {
+		t.Error("Expected error for complex division by zero")
+	}
+}
+
+// TestComplexPower verifies exact integer powers and principal complex powers
+func TestComplexPower(t *testing.T) {
+	calc := New()
+	tests := []struct {
+		base, exponent, expected complex128
+	}{
+		{1i, 2, -1},
+		{1i, -1, -1i},
+		{complex(1, 1), 4, -4},
+		{-8, 3, -512},
+		{-4, 0.5, 2i},
+		{math.E, complex(0, math.Pi), -1},
+	}
+	for _, test := range tests {
+		r, err := calc.CPower(test.base, test.exponent)
+		if err != nil || !complexClose(r, test.expected) {
+			t.Errorf("CPower(%v, %v): expected %v, got %v (err: %v)", test.base, test.exponent, test.expected, r, err)
+		}
+	}
+	if r, _ := calc.CPower(1i, 2); r != -1 {
+		t.Errorf("Expected i^2 to be exactly -1, got %v", r)
+	}
+	if _, err := calc.CPower(0, -1); err == nil {
+		t.Error("Expected error for zero raised to a negative power")
+	}
+}
+
+// TestComplexFunctions verifies principal values of roots, logarithms and trig functions
+func TestComplexFunctions(t *testing.T) {
+	calc := New()
+
+	if r := calc.CSqrt(-4); !complexClose(r, 2i) {
+		t.Errorf("Expected sqrt(-4) = 2i, got %v", r)
+	}
+	if r, err := calc.CLn(-1); err != nil || !complexClose(r, complex(0, math.Pi)) {
+		t.Errorf("Expected ln(-1) = πi, got %v (err: %v)", r, err)
+	}
+	if r, err := calc.CLog10(-100); err != nil || !complexClose(r, complex(2, math.Pi/math.Ln10)) {
+		t.Errorf("Expected log10(-100) = 2+1.364i, got %v (err: %v)", r, err)
+	}
+	if r, err := calc.CLog(-8, 2); err != nil || !complexClose(r, complex(3, math.Pi/math.Ln2)) {
+		t.Errorf("Expected log(-8, 2) = 3+4.532i, got %v (err: %v)", r, err)
+	}
+	if _, err := calc.CLn(0); err == nil {
+		t.Error("Expected error for ln(0)")
+	}
+	if _, err := calc.CLog(2, 1); err == nil {
+		t.Error("Expected error for logarithm base 1")
+	}
+	if r := calc.CAsin(2); !complexClose(calc.CSin(r), 2) {
+		t.Errorf("Expected sin(asin(2)) = 2, got %v", calc.CSin(r))
+	}
+	if r := calc.CCosh(1i * math.Pi); !complexClose(r, -1) {
+		t.Errorf("Expected cosh(πi) = -1, got %v", r)
+	}
+}
+
+// TestComplexAngleMode verifies that arguments and trig functions follow the angle mode
+func TestComplexAngleMode(t *testing.T) {
+	calc := New()
+	calc.SetAngleMode(Degrees)
+
+	if r, theta := calc.Polar(complex(0, 2)); r != 2 || math.Abs(theta-90) > 1e-12 {
+		t.Errorf("Expected 2i to be 2∠90°, got %v∠%v", r, theta)
+	}
+	if z := calc.Rect(2, 90); !complexClose(z, 2i) {
+		t.Errorf("Expected 2∠90° to be 2i, got %v", z)
+	}
+	if r := calc.CSin(90); !complexClose(r, 1) {
+		t.Errorf("Expected sin(90°) = 1, got %v", r)
+	}
+	if r := calc.CAcos(-1); !complexClose(r, 180) {
+		t.Errorf("Expected acos(-1) = 180°, got %v", r)
+	}
+	if r := calc.CConj(complex(1, 2)); r != complex(1, -2) {
+		t.Errorf("Expected conj(1+2i) = 1-2i, got %v", r)
+	}
+}
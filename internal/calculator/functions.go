@@ -0,0 +1,178 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Sin returns the sine of the angle x given in the current angle mode
+func (c *Calculator) Sin(x float64) float64 {
+	if sin, ok := c.exactSin(x, 0); ok {
+		return sin
+	}
+	return math.Sin(c.toRadians(x))
+}
+
+// Cos returns the cosine of the angle x given in the current angle mode
+func (c *Calculator) Cos(x float64) float64 {
+	if cos, ok := c.exactSin(x, 1); ok {
+		return cos
+	}
+	return math.Cos(c.toRadians(x))
+}
+
+// Tan returns the tangent of the angle x given in the current angle mode with
+// error handling for the poles at odd multiples of 90 degrees or pi/2
+func (c *Calculator) Tan(x float64) (float64, error) {
+	if c.isPole(x) {
+		quarter := map[AngleMode]string{Radians: "pi/2", Degrees: "90 degrees", Gradians: "100 gradians"}[c.angleMode]
+		return 0.0, fmt.Errorf("tangent undefined at odd multiples of %s", quarter)
+	}
+	if sin, ok := c.exactSin(x, 0); ok {
+		return sin / c.Cos(x), nil
+	}
+	return math.Tan(c.toRadians(x)), nil
+}
+
+// Asin returns the arcsine of x in the current angle mode with error handling for inputs outside [-1, 1]
+func (c *Calculator) Asin(x float64) (float64, error) {
+	if x < -1 || x > 1 {
+		return 0.0, errors.New("arcsine argument out of range [-1, 1]")
+	}
+	if angle, ok := c.exactAsin(x); ok {
+		return angle, nil
+	}
+	return c.fromRadians(math.Asin(x)), nil
+}
+
+// Acos returns the arccosine of x in the current angle mode with error handling for inputs outside [-1, 1]
+func (c *Calculator) Acos(x float64) (float64, error) {
+	if x < -1 || x > 1 {
+		return 0.0, errors.New("arccosine argument out of range [-1, 1]")
+	}
+	if angle, ok := c.exactAsin(x); ok {
+		return c.angleMode.fullTurn()/4 - angle, nil
+	}
+	return c.fromRadians(math.Acos(x)), nil
+}
+
+// Atan returns the arctangent of x in the current angle mode
+func (c *Calculator) Atan(x float64) float64 {
+	if c.angleMode != Radians && (x == 1 || x == -1) {
+		return x * c.angleMode.fullTurn() / 8
+	}
+	return c.fromRadians(math.Atan(x))
+}
+
+// Atan2 returns the arctangent of y/x in the current angle mode, using the signs of both to determine the quadrant
+func (c *Calculator) Atan2(y, x float64) float64 {
+	return c.fromRadians(math.Atan2(y, x))
+}
+
+// Sinh returns the hyperbolic sine of x
+func (c *Calculator) Sinh(x float64) float64 {
+	return math.Sinh(x)
+}
+
+// Cosh returns the hyperbolic cosine of x
+func (c *Calculator) Cosh(x float64) float64 {
+	return math.Cosh(x)
+}
+
+// Tanh returns the hyperbolic tangent of x
+func (c *Calculator) Tanh(x float64) float64 {
+	return math.Tanh(x)
+}
+
+// Ln returns the natural logarithm of x with error handling for non-positive inputs
+func (c *Calculator) Ln(x float64) (float64, error) {
+	if x <= 0 {
+		return 0.0, errors.New("logarithm of non-positive number")
+	}
+	return math.Log(x), nil
+}
+
+// Log10 returns the base 10 logarithm of x with error handling for non-positive inputs
+func (c *Calculator) Log10(x float64) (float64, error) {
+	if x <= 0 {
+		return 0.0, errors.New("logarithm of non-positive number")
+	}
+	return math.Log10(x), nil
+}
+
+// Log returns the logarithm of x in the given base with error handling for
+// non-positive inputs and invalid bases
+func (c *Calculator) Log(x, base float64) (float64, error) {
+	if x <= 0 {
+		return 0.0, errors.New("logarithm of non-positive number")
+	}
+	if base <= 0 || base == 1 {
+		return 0.0, errors.New("logarithm base must be positive and not equal to 1")
+	}
+	return math.Log(x) / math.Log(base), nil
+}
+
+// Exp returns e raised to the power of x
+func (c *Calculator) Exp(x float64) float64 {
+	return math.Exp(x)
+}
+
+// Abs returns the absolute value of x
+func (c *Calculator) Abs(x float64) float64 {
+	return math.Abs(x)
+}
+
+// Floor returns the greatest integer value less than or equal to x
+func (c *Calculator) Floor(x float64) float64 {
+	return math.Floor(x)
+}
+
+// Ceil returns the least integer value greater than or equal to x
+func (c *Calculator) Ceil(x float64) float64 {
+	return math.Ceil(x)
+}
+
+// Round returns the nearest integer to x, rounding half away from zero
+func (c *Calculator) Round(x float64) float64 {
+	return math.Round(x)
+}
+
+// Trunc returns the integer part of x
+func (c *Calculator) Trunc(x float64) float64 {
+	return math.Trunc(x)
+}
+
+// Min returns the smallest of its arguments with error handling for an empty argument list
+func (c *Calculator) Min(values ...float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("min requires at least one value")
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		result = math.Min(result, v)
+	}
+	return result, nil
+}
+
+// Max returns the largest of its arguments with error handling for an empty argument list
+func (c *Calculator) Max(values ...float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("max requires at least one value")
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		result = math.Max(result, v)
+	}
+	return result, nil
+}
+
+// Cbrt returns the cube root of x, which unlike Sqrt is defined for negative numbers
+func (c *Calculator) Cbrt(x float64) float64 {
+	return math.Cbrt(x)
+}
+
+// Hypot returns sqrt(a*a + b*b) while avoiding unnecessary overflow and underflow
+func (c *Calculator) Hypot(a, b float64) float64 {
+	return math.Hypot(a, b)
+}
@@ -0,0 +1,178 @@
+// functions_test.go
+package calculator
+
+import (
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// TRIGONOMETRIC FUNCTION TESTS
+// These tests verify trigonometric and inverse trigonometric functions, including
+// domain errors for inverse functions
+// =============================================================================
+
+// TestTrigonometric verifies sin, cos, tan and atan at well-known angles
+func TestTrigonometric(t *testing.T) {
+	calc := New()
+
+	if result := calc.Sin(math.Pi / 2); !floatEquals(result, 1, 1e-12) {
+		t.Errorf("Expected sin(pi/2) = 1, got %f", result)
+	}
+	if result := calc.Cos(math.Pi); !floatEquals(result, -1, 1e-12) {
+		t.Errorf("Expected cos(pi) = -1, got %f", result)
+	}
+	if result, err := calc.Tan(math.Pi / 4); err != nil || !floatEquals(result, 1, 1e-12) {
+		t.Errorf("Expected tan(pi/4) = 1, got %f", result)
+	}
+	for _, pole := range []float64{math.Pi / 2, -math.Pi / 2, 3 * math.Pi / 2} {
+		if _, err := calc.Tan(pole); err == nil {
+			t.Errorf("Expected tan(%g) to be undefined", pole)
+		}
+	}
+	if result := calc.Atan(1); !floatEquals(result, math.Pi/4, 1e-12) {
+		t.Errorf("Expected atan(1) = pi/4, got %f", result)
+	}
+}
+
+// TestInverseTrigonometricDomain verifies asin and acos reject inputs outside [-1, 1]
+func TestInverseTrigonometricDomain(t *testing.T) {
+	calc := New()
+
+	if result, err := calc.Asin(1); err != nil || !floatEquals(result, math.Pi/2, 1e-12) {
+		t.Errorf("Expected asin(1) = pi/2, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Acos(-1); err != nil || !floatEquals(result, math.Pi, 1e-12) {
+		t.Errorf("Expected acos(-1) = pi, got %f (err: %v)", result, err)
+	}
+	if _, err := calc.Asin(1.5); err == nil {
+		t.Error("Expected asin(1.5) domain error")
+	}
+	if _, err := calc.Acos(-2); err == nil {
+		t.Error("Expected acos(-2) domain error")
+	}
+}
+
+// TestAtan2 verifies the quadrant is derived from the signs of both arguments
+func TestAtan2(t *testing.T) {
+	calc := New()
+
+	if result := calc.Atan2(1, -1); !floatEquals(result, 3*math.Pi/4, 1e-12) {
+		t.Errorf("Expected atan2(1, -1) = 3pi/4, got %f", result)
+	}
+	if result := calc.Atan2(-1, -1); !floatEquals(result, -3*math.Pi/4, 1e-12) {
+		t.Errorf("Expected atan2(-1, -1) = -3pi/4, got %f", result)
+	}
+}
+
+// TestHyperbolic verifies sinh, cosh and tanh at zero and one
+func TestHyperbolic(t *testing.T) {
+	calc := New()
+
+	if result := calc.Sinh(0); result != 0 {
+		t.Errorf("Expected sinh(0) = 0, got %f", result)
+	}
+	if result := calc.Cosh(0); result != 1 {
+		t.Errorf("Expected cosh(0) = 1, got %f", result)
+	}
+	if result := calc.Tanh(1); !floatEquals(result, 0.7615941559557649, 1e-12) {
+		t.Errorf("Expected tanh(1) = 0.76159..., got %f", result)
+	}
+}
+
+// =============================================================================
+// LOGARITHM AND EXPONENTIAL TESTS
+// These tests verify logarithms in various bases and their domain errors
+// =============================================================================
+
+// TestLogarithms verifies ln, log10 and log with an explicit base
+func TestLogarithms(t *testing.T) {
+	calc := New()
+
+	if result, err := calc.Ln(math.E); err != nil || !floatEquals(result, 1, 1e-12) {
+		t.Errorf("Expected ln(e) = 1, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Log10(1000); err != nil || !floatEquals(result, 3, 1e-12) {
+		t.Errorf("Expected log10(1000) = 3, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Log(8, 2); err != nil || !floatEquals(result, 3, 1e-12) {
+		t.Errorf("Expected log(8, 2) = 3, got %f (err: %v)", result, err)
+	}
+	if result := calc.Exp(0); result != 1 {
+		t.Errorf("Expected exp(0) = 1, got %f", result)
+	}
+}
+
+// TestLogarithmDomain verifies logarithms reject non-positive inputs and invalid bases
+func TestLogarithmDomain(t *testing.T) {
+	calc := New()
+
+	if _, err := calc.Ln(0); err == nil {
+		t.Error("Expected ln(0) domain error")
+	}
+	if _, err := calc.Log10(-10); err == nil {
+		t.Error("Expected log10(-10) domain error")
+	}
+	if _, err := calc.Log(8, 1); err == nil {
+		t.Error("Expected log base 1 error")
+	}
+	if _, err := calc.Log(8, -2); err == nil {
+		t.Error("Expected negative log base error")
+	}
+}
+
+// =============================================================================
+// ROUNDING AND MISCELLANEOUS FUNCTION TESTS
+// These tests verify rounding behaviour on negative and half-way values
+// =============================================================================
+
+// TestRounding verifies floor, ceil, round and trunc with negative half-way values
+func TestRounding(t *testing.T) {
+	calc := New()
+
+	if result := calc.Floor(-2.5); result != -3 {
+		t.Errorf("Expected floor(-2.5) = -3, got %f", result)
+	}
+	if result := calc.Ceil(-2.5); result != -2 {
+		t.Errorf("Expected ceil(-2.5) = -2, got %f", result)
+	}
+	if result := calc.Round(-2.5); result != -3 {
+		t.Errorf("Expected round(-2.5) = -3 (half away from zero), got %f", result)
+	}
+	if result := calc.Trunc(-2.5); result != -2 {
+		t.Errorf("Expected trunc(-2.5) = -2, got %f", result)
+	}
+	if result := calc.Abs(-4); result != 4 {
+		t.Errorf("Expected abs(-4) = 4, got %f", result)
+	}
+}
+
+// TestMinMax verifies variadic min and max and their error on no arguments
+func TestMinMax(t *testing.T) {
+	calc := New()
+
+	if result, err := calc.Min(3, -1, 2); err != nil || result != -1 {
+		t.Errorf("Expected min(3, -1, 2) = -1, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Max(3, -1, 2); err != nil || result != 3 {
+		t.Errorf("Expected max(3, -1, 2) = 3, got %f (err: %v)", result, err)
+	}
+	if _, err := calc.Min(); err == nil {
+		t.Error("Expected error for min of no values")
+	}
+	if _, err := calc.Max(); err == nil {
+		t.Error("Expected error for max of no values")
+	}
+}
+
+// TestCbrtHypot verifies cube roots of negative numbers and hypotenuse calculation
+func TestCbrtHypot(t *testing.T) {
+	calc := New()
+
+	if result := calc.Cbrt(-27); !floatEquals(result, -3, 1e-12) {
+		t.Errorf("Expected cbrt(-27) = -3, got %f", result)
+	}
+	if result := calc.Hypot(3, 4); result != 5 {
+		t.Errorf("Expected hypot(3, 4) = 5, got %f", result)
+	}
+}
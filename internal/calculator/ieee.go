@@ -0,0 +1,371 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxExactInteger is 2^53, above which float64 cannot represent every integer
+	maxExactInteger = 1 << 53
+	// significandBits is the precision of a float64, including the implicit bit
+	significandBits = 53
+	// cancellationBits is the number of leading bits a sum must lose to
+	// cancellation, more than half of the significand, before it is reported
+	cancellationBits = 27
+)
+
+// IEEEMode selects what happens when a float64 operation meets an IEEE-754 edge
+// case such as overflow to infinity or a NaN result
+type IEEEMode int
+
+const (
+	// IEEELenient keeps the IEEE-754 result, such as +Inf, and warns about it
+	IEEELenient IEEEMode = iota
+	// IEEEStrict reports edge cases as errors
+	IEEEStrict
+	// IEEEQuiet keeps the IEEE-754 result without comment
+	IEEEQuiet
+)
+
+// String returns the name of the IEEE mode as accepted by ParseIEEEMode
+func (m IEEEMode) String() string {
+	switch m {
+	case IEEEStrict:
+		return "strict"
+	case IEEEQuiet:
+		return "quiet"
+	default:
+		return "lenient"
+	}
+}
+
+// ParseIEEEMode converts "lenient", "strict" or "quiet" into an IEEEMode
+func ParseIEEEMode(name string) (IEEEMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "lenient", "warn":
+		return IEEELenient, nil
+	case "strict", "error":
+		return IEEEStrict, nil
+	case "quiet", "off":
+		return IEEEQuiet, nil
+	default:
+		return IEEELenient, fmt.Errorf("unknown IEEE mode %q (expected lenient, strict or quiet)", name)
+	}
+}
+
+// IEEEMode returns how float64 operations handle IEEE-754 edge cases
+func (c *Calculator) IEEEMode() IEEEMode {
+	return c.ieee
+}
+
+// SetIEEEMode changes how float64 operations handle IEEE-754 edge cases
+func (c *Calculator) SetIEEEMode(mode IEEEMode) {
+	c.ieee = mode
+}
+
+// Issue is a set of IEEE-754 edge cases met by a float64 operation
+type Issue uint8
+
+const (
+	// IssueOverflow is an infinite result from finite operands
+	IssueOverflow Issue = 1 << iota
+	// IssueUnderflow is a result too small to be represented at full precision,
+	// either rounded to zero or subnormal
+	IssueUnderflow
+	// IssueNaN is a NaN result from operands that are numbers
+	IssueNaN
+	// IssuePrecisionLoss is an integer result above 2^53 that was rounded
+	IssuePrecisionLoss
+	// IssueCancellation is a sum or difference of nearly equal and opposite
+	// operands, whose leading digits cancel and leave mostly rounding error
+	IssueCancellation
+)
+
+// issueNames names each Issue in the order of its bits
+var issueNames = []string{"overflow", "underflow", "NaN", "precision loss", "cancellation"}
+
+// String returns the names of the issues in the set, separated by commas
+func (i Issue) String() string {
+	var names []string
+	for bit, name := range issueNames {
+		if i&(1<<bit) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// Result is the value of a float64 operation together with the IEEE-754 edge
+// cases met in computing it
+type Result struct {
+	Value  float64
+	Issues Issue
+	// Op describes the operation, such as "0 ^ -1" or "exp(1000)"
+	Op string
+	// LostBits is the number of significand bits lost to cancellation
+	LostBits int
+	// exact is the exact value of a rounded integer result or literal
+	exact string
+	// negativeBase marks a NaN from a negative number raised to a fraction
+	negativeBase bool
+	// pole marks an infinite power of zero, which is a division by zero
+	pole bool
+}
+
+// Warnings describes each issue of the result in a sentence, or returns nil
+// when there are none
+func (r Result) Warnings() []string {
+	var warnings []string
+	value := formatFloat(r.Value)
+	if r.Issues&IssueOverflow != 0 {
+		if r.pole {
+			warnings = append(warnings, fmt.Sprintf("%s divides by zero, giving %s", r.Op, value))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s overflows to %s", r.Op, value))
+		}
+	}
+	if r.Issues&IssueUnderflow != 0 {
+		if r.Value == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s underflows to 0", r.Op))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s underflows to the subnormal %s, with reduced precision", r.Op, value))
+		}
+	}
+	if r.Issues&IssueNaN != 0 {
+		if r.negativeBase {
+			warnings = append(warnings, fmt.Sprintf("%s is NaN: a negative number has no real fractional power", r.Op))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s is NaN", r.Op))
+		}
+	}
+	if r.Issues&IssuePrecisionLoss != 0 {
+		exact := ""
+		if r.exact != r.Op {
+			exact = " " + r.exact + ","
+		}
+		warnings = append(warnings, fmt.Sprintf("%s is%s rounded to %s: integers above 2^53 are not exact in float64", r.Op, exact, value))
+	}
+	if r.Issues&IssueCancellation != 0 {
+		warnings = append(warnings, fmt.Sprintf("%s loses %d of %d significant bits to cancellation", r.Op, r.LostBits, significandBits))
+	}
+	return warnings
+}
+
+// Err returns the issues of the result as an error, or nil when there are none
+func (r Result) Err() error {
+	if r.Issues == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(r.Warnings(), "; "))
+}
+
+// Check applies the IEEE mode to a diagnosed result: it returns the value with
+// an error for any issue in strict mode, with warnings in lenient mode, and
+// alone in quiet mode
+func (c *Calculator) Check(r Result) (float64, []string, error) {
+	switch c.ieee {
+	case IEEEStrict:
+		if err := r.Err(); err != nil {
+			return 0.0, nil, err
+		}
+	case IEEELenient:
+		return r.Value, r.Warnings(), nil
+	}
+	return r.Value, nil, nil
+}
+
+// Diagnose finds the IEEE-754 edge cases met by an operation that gave result
+// from the operands. The op is one of + - * / ^ for arithmetic, which is also
+// checked for rounded integers and cancellation, or the name of a function.
+func (c *Calculator) Diagnose(op string, result float64, operands ...float64) Result {
+	r := Result{Value: result, Op: describeOp(op, operands)}
+	numbers, finite := true, true
+	for _, x := range operands {
+		numbers = numbers && !math.IsNaN(x)
+		finite = finite && !math.IsNaN(x) && !math.IsInf(x, 0)
+	}
+	switch {
+	case math.IsNaN(result):
+		if numbers {
+			r.Issues |= IssueNaN
+			r.negativeBase = op == "^" && operands[0] < 0 && operands[1] != math.Trunc(operands[1])
+		}
+		return r
+	case math.IsInf(result, 0):
+		if finite {
+			r.Issues |= IssueOverflow
+			r.pole = op == "^" && operands[0] == 0
+		}
+		return r
+	case result != 0 && math.Abs(result) < minNormal:
+		r.Issues |= IssueUnderflow
+	}
+	if len(operands) != 2 || !finite {
+		return r
+	}
+
+	a, b := operands[0], operands[1]
+	switch op {
+	case "*", "/", "^":
+		if result == 0 && a != 0 && (op != "*" || b != 0) {
+			r.Issues |= IssueUnderflow
+		}
+	}
+	if math.Abs(result) >= maxExactInteger {
+		if exact, ok := exactInteger(op, a, b); ok && exact.Cmp(floatInt(result)) != 0 {
+			r.Issues |= IssuePrecisionLoss
+			r.exact = exact.String()
+		}
+	}
+	if bits := cancelledBits(op, a, b, result); bits >= cancellationBits {
+		r.Issues |= IssueCancellation
+		r.LostBits = bits
+	}
+	return r
+}
+
+// DiagnoseLiteral finds the IEEE-754 edge cases met in reading a numeric
+// literal as the float64 value: a literal beyond the float64 range such as
+// 1e309 overflows, a non-zero one such as 1e-400 underflows, and an integer
+// written out above 2^53 such as 9007199254740993 may be rounded. Literals in
+// scientific notation such as 1e300 stand for the nearest float64 and are
+// not reported as rounded.
+func (c *Calculator) DiagnoseLiteral(text string, value float64) Result {
+	exact, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return Result{Value: value, Op: text}
+	}
+	r := c.DiagnoseExact(text, exact, value)
+	if _, integer := new(big.Int).SetString(text, 10); !integer {
+		r.Issues &^= IssuePrecisionLoss
+	}
+	return r
+}
+
+// DiagnoseExact finds the IEEE-754 edge cases met in rounding the exact value of
+// op to the float64 value, such as a big integer or fraction converted for a
+// float64 operation
+func (c *Calculator) DiagnoseExact(op string, exact *big.Rat, value float64) Result {
+	r := Result{Value: value, Op: op}
+	switch {
+	case math.IsInf(value, 0):
+		r.Issues |= IssueOverflow
+	case exact.Sign() != 0 && math.Abs(value) < minNormal:
+		r.Issues |= IssueUnderflow
+	case exact.IsInt() && math.Abs(value) >= maxExactInteger && exact.Num().Cmp(floatInt(value)) != 0:
+		r.Issues |= IssuePrecisionLoss
+		r.exact = exact.Num().String()
+	}
+	return r
+}
+
+// DescribeExact renders an exact value for a warning: integers of up to 40
+// digits in full and other values to 10 significant digits
+func DescribeExact(x *big.Rat) string {
+	if x.IsInt() && len(x.Num().String()) <= 40 {
+		return x.Num().String()
+	}
+	return new(big.Float).SetPrec(64).SetRat(x).Text('g', 10)
+}
+
+// minNormal is the smallest positive float64 with full precision
+var minNormal = math.Float64frombits(1 << 52)
+
+// exactInteger returns the exact result of an operation on integer operands
+// when it is an integer, to compare with the rounded float64 result
+func exactInteger(op string, a, b float64) (*big.Int, bool) {
+	if a != math.Trunc(a) || b != math.Trunc(b) {
+		return nil, false
+	}
+	x, y := floatInt(a), floatInt(b)
+	switch op {
+	case "+":
+		return x.Add(x, y), true
+	case "-":
+		return x.Sub(x, y), true
+	case "*":
+		return x.Mul(x, y), true
+	case "/":
+		if y.Sign() == 0 {
+			return nil, false
+		}
+		q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+		return q, m.Sign() == 0
+	case "^":
+		if y.Sign() < 0 || y.BitLen() > 11 {
+			// A result above 2^53 from a larger exponent would be infinite
+			return nil, false
+		}
+		return x.Exp(x, y, nil), true
+	}
+	return nil, false
+}
+
+// floatInt converts an integer-valued float64 to a big.Int
+func floatInt(f float64) *big.Int {
+	i, _ := new(big.Float).SetFloat64(f).Int(nil)
+	return i
+}
+
+// cancelledBits returns how many leading bits of the larger operand cancel in
+// a sum or difference of operands of opposite effective sign, or 0 when they
+// do not. Integers are exempt, as their differences are exact.
+func cancelledBits(op string, a, b, result float64) int {
+	if op == "-" {
+		b = -b
+	} else if op != "+" {
+		return 0
+	}
+	if a == 0 || b == 0 || result == 0 || (a > 0) == (b > 0) {
+		return 0
+	}
+	if a == math.Trunc(a) && b == math.Trunc(b) {
+		return 0
+	}
+	bits := math.Ilogb(math.Max(math.Abs(a), math.Abs(b))) - math.Ilogb(result)
+	return min(bits, significandBits)
+}
+
+// describeOp renders an operation for a warning, such as "0 ^ -1" for an
+// operator, "171.5!" for a factorial or "exp(1000)" for a function
+func describeOp(op string, operands []float64) string {
+	args := make([]string, len(operands))
+	for i, x := range operands {
+		args[i] = formatFloat(x)
+	}
+	switch {
+	case len(args) == 2 && isOperator(op):
+		return args[0] + " " + op + " " + args[1]
+	case len(args) == 1 && op == "!":
+		return args[0] + "!"
+	case len(args) == 0:
+		return op
+	}
+	return op + "(" + strings.Join(args, ", ") + ")"
+}
+
+// isOperator reports whether op is a binary operator rather than a function name
+func isOperator(op string) bool {
+	switch op {
+	case "+", "-", "*", "/", "^", "%", "//", "@", "&", "|", "xor", "<<", ">>":
+		return true
+	}
+	return false
+}
+
+// formatFloat formats a float64 in the shortest form that reads back exactly,
+// writing the exact digits of integers below 10^21 so that rounding shows
+func formatFloat(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		return floatInt(f).String()
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
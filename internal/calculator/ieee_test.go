@@ -0,0 +1,169 @@
+// ieee_test.go
+package calculator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// IEEE-754 DIAGNOSTICS TESTS
+// These tests verify the detection of overflow, underflow, NaN, rounded
+// integers and cancellation in float64 results, and the strict and lenient modes
+// =============================================================================
+
+// TestParseIEEEMode verifies IEEE mode names and their aliases
+func TestParseIEEEMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected IEEEMode
+	}{
+		{"lenient", IEEELenient},
+		{"warn", IEEELenient},
+		{"Strict", IEEEStrict},
+		{"error", IEEEStrict},
+		{" quiet ", IEEEQuiet},
+		{"off", IEEEQuiet},
+	}
+	for _, test := range tests {
+		mode, err := ParseIEEEMode(test.name)
+		if err != nil || mode != test.expected {
+			t.Errorf("ParseIEEEMode(%q): expected %s, got %s (%v)", test.name, test.expected, mode, err)
+		}
+	}
+	if _, err := ParseIEEEMode("loud"); err == nil {
+		t.Error("ParseIEEEMode(\"loud\"): expected an error")
+	}
+}
+
+// TestDiagnose verifies which edge cases are found for arithmetic and functions
+func TestDiagnose(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		op       string
+		result   float64
+		operands []float64
+		expected Issue
+	}{
+		{"^", calc.Power(0, -1), []float64{0, -1}, IssueOverflow},
+		{"^", calc.Power(-8, 1.0/3), []float64{-8, 1.0 / 3}, IssueNaN},
+		{"*", calc.Multiply(1e300, 1e300), []float64{1e300, 1e300}, IssueOverflow},
+		{"*", calc.Multiply(1e-200, 1e-200), []float64{1e-200, 1e-200}, IssueUnderflow},
+		{"^", calc.Power(2, -1074), []float64{2, -1074}, IssueUnderflow},
+		{"+", calc.Add(1<<53, 1), []float64{1 << 53, 1}, IssuePrecisionLoss},
+		{"*", calc.Multiply(1<<40+1, 1<<40+1), []float64{1<<40 + 1, 1<<40 + 1}, IssuePrecisionLoss},
+		{"^", calc.Power(3, 40), []float64{3, 40}, IssuePrecisionLoss},
+		{"-", calc.Subtract(1.000000001, 1), []float64{1.000000001, 1}, IssueCancellation},
+		{"+", calc.Add(0.30000000000000004, -0.3), []float64{0.30000000000000004, -0.3}, IssueCancellation},
+		{"exp", math.Exp(1000), []float64{1000}, IssueOverflow},
+		{"sqrt", math.NaN(), []float64{-1}, IssueNaN},
+
+		// Ordinary results and edge cases that were there from the start
+		{"+", calc.Add(0.1, 0.2), []float64{0.1, 0.2}, 0},
+		{"-", calc.Subtract(1.0001, 1), []float64{1.0001, 1}, 0},
+		{"-", calc.Subtract(100000000001, 100000000000), []float64{100000000001, 100000000000}, 0},
+		{"*", calc.Multiply(1<<40, 1<<20), []float64{1 << 40, 1 << 20}, 0},
+		{"*", calc.Multiply(0, 5), []float64{0, 5}, 0},
+		{"+", calc.Add(math.Inf(1), 1), []float64{math.Inf(1), 1}, 0},
+		{"*", calc.Multiply(math.NaN(), 2), []float64{math.NaN(), 2}, 0},
+		{"sin", math.Sin(0), []float64{0}, 0},
+	}
+	for _, test := range tests {
+		r := calc.Diagnose(test.op, test.result, test.operands...)
+		if r.Issues != test.expected {
+			t.Errorf("Diagnose(%s): expected %s, got %s", r.Op, test.expected, r.Issues)
+		}
+	}
+}
+
+// TestResultWarnings verifies the sentences describing each edge case
+func TestResultWarnings(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		r        Result
+		expected string
+	}{
+		{calc.Diagnose("^", calc.Power(0, -1), 0, -1), "0 ^ -1 divides by zero, giving +Inf"},
+		{calc.Diagnose("^", calc.Power(-8, 0.5), -8, 0.5), "-8 ^ 0.5 is NaN: a negative number has no real fractional power"},
+		{calc.Diagnose("exp", math.Exp(1000), 1000), "exp(1000) overflows to +Inf"},
+		{calc.Diagnose("/", 1e-310, 1e-310, 1), "1e-310 / 1 underflows to the subnormal 1e-310, with reduced precision"},
+		{calc.Diagnose("+", calc.Add(1<<53, 1), 1<<53, 1), "9007199254740992 + 1 is 9007199254740993, rounded to 9007199254740992: integers above 2^53 are not exact in float64"},
+		{calc.Diagnose("-", calc.Subtract(1.000000001, 1), 1.000000001, 1), "1.000000001 - 1 loses 30 of 53 significant bits to cancellation"},
+		{calc.DiagnoseLiteral("9007199254740993", 9007199254740993), "9007199254740993 is rounded to 9007199254740992: integers above 2^53 are not exact in float64"},
+	}
+	for _, test := range tests {
+		warnings := test.r.Warnings()
+		if len(warnings) != 1 || warnings[0] != test.expected {
+			t.Errorf("Warnings(%s): expected %q, got %q", test.r.Op, test.expected, warnings)
+		}
+	}
+
+	if r := calc.DiagnoseLiteral("9007199254740992", 9007199254740992); r.Issues != 0 {
+		t.Errorf("DiagnoseLiteral(2^53): expected no issues, got %s", r.Issues)
+	}
+}
+
+// TestDiagnoseLiteral verifies literals beyond the float64 range or below its
+// precision, and those that float64 holds well enough
+func TestDiagnoseLiteral(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		text     string
+		value    float64
+		expected Issue
+	}{
+		{"1e309", math.Inf(1), IssueOverflow},
+		{"1e-400", 0, IssueUnderflow},
+		{"1e-310", 1e-310, IssueUnderflow},
+		{"9007199254740993", 9007199254740993, IssuePrecisionLoss},
+		{"1e300", 1e300, 0},
+		{"0", 0, 0},
+		{"0.000", 0, 0},
+		{"0.1", 0.1, 0},
+		{"2.5e-308", 2.5e-308, 0},
+	}
+	for _, test := range tests {
+		if r := calc.DiagnoseLiteral(test.text, test.value); r.Issues != test.expected {
+			t.Errorf("DiagnoseLiteral(%s): expected %s, got %s", test.text, test.expected, r.Issues)
+		}
+	}
+
+	warnings := calc.DiagnoseLiteral("1e309", math.Inf(1)).Warnings()
+	if len(warnings) != 1 || warnings[0] != "1e309 overflows to +Inf" {
+		t.Errorf("DiagnoseLiteral(1e309): expected an overflow warning, got %q", warnings)
+	}
+}
+
+// TestCheck verifies that strict mode turns edge cases into errors, lenient mode
+// into warnings and quiet mode into nothing
+func TestCheck(t *testing.T) {
+	calc := New()
+	overflow := calc.Diagnose("^", calc.Power(0, -1), 0, -1)
+	ok := calc.Diagnose("+", 3, 1, 2)
+
+	if calc.IEEEMode() != IEEELenient {
+		t.Errorf("IEEEMode: expected lenient by default, got %s", calc.IEEEMode())
+	}
+	f, warnings, err := calc.Check(overflow)
+	if err != nil || !math.IsInf(f, 1) || len(warnings) != 1 {
+		t.Errorf("Check in lenient mode: expected +Inf with a warning, got %g %q (%v)", f, warnings, err)
+	}
+
+	calc.SetIEEEMode(IEEEStrict)
+	if _, _, err := calc.Check(overflow); err == nil || !strings.Contains(err.Error(), "divides by zero") {
+		t.Errorf("Check in strict mode: expected a division by zero error, got %v", err)
+	}
+	if f, _, err := calc.Check(ok); err != nil || f != 3 {
+		t.Errorf("Check in strict mode: expected 3, got %g (%v)", f, err)
+	}
+
+	calc.SetIEEEMode(IEEEQuiet)
+	f, warnings, err = calc.Check(overflow)
+	if err != nil || !math.IsInf(f, 1) || len(warnings) != 0 {
+		t.Errorf("Check in quiet mode: expected +Inf alone, got %g %q (%v)", f, warnings, err)
+	}
+}
@@ -0,0 +1,286 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// WordSize describes the fixed-width integers used in integer (programmer) mode
+type WordSize struct {
+	Bits   uint // 8, 16, 32 or 64
+	Signed bool
+}
+
+// DefaultWordSize is a signed 64 bit integer
+var DefaultWordSize = WordSize{Bits: 64, Signed: true}
+
+// String returns the short name of the word size, e.g. i32 or u8
+func (w WordSize) String() string {
+	if w.Signed {
+		return "i" + strconv.Itoa(int(w.Bits))
+	}
+	return "u" + strconv.Itoa(int(w.Bits))
+}
+
+// min returns the smallest value representable in the word
+func (w WordSize) min() *big.Int {
+	if !w.Signed {
+		return new(big.Int)
+	}
+	return new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), w.Bits-1))
+}
+
+// max returns the largest value representable in the word
+func (w WordSize) max() *big.Int {
+	bits := w.Bits
+	if w.Signed {
+		bits--
+	}
+	return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+}
+
+// ParseWordSize converts a name such as "32", "i32", "u8" or "16u" into a WordSize;
+// a bare bit count is signed
+func ParseWordSize(name string) (WordSize, error) {
+	s := strings.ToLower(strings.TrimSpace(name))
+	signed := true
+	switch {
+	case strings.HasPrefix(s, "u"):
+		signed, s = false, s[1:]
+	case strings.HasSuffix(s, "u"):
+		signed, s = false, s[:len(s)-1]
+	case strings.HasPrefix(s, "i"), strings.HasPrefix(s, "s"):
+		s = s[1:]
+	case strings.HasSuffix(s, "s"):
+		s = s[:len(s)-1]
+	}
+	switch s {
+	case "8", "16", "32", "64":
+		bits, _ := strconv.Atoi(s)
+		return WordSize{Bits: uint(bits), Signed: signed}, nil
+	default:
+		return DefaultWordSize, fmt.Errorf("unknown word size %q (expected 8, 16, 32 or 64 with an optional i/u prefix)", name)
+	}
+}
+
+// OverflowMode selects what happens when an integer result does not fit the word size
+type OverflowMode int
+
+const (
+	// OverflowWrap reduces results modulo 2^bits, as two's complement hardware does
+	OverflowWrap OverflowMode = iota
+	// OverflowError reports results that do not fit as errors
+	OverflowError
+)
+
+// String returns the name of the overflow mode as accepted by ParseOverflowMode
+func (m OverflowMode) String() string {
+	if m == OverflowError {
+		return "error"
+	}
+	return "wrap"
+}
+
+// ParseOverflowMode converts "wrap" or "error" into an OverflowMode
+func ParseOverflowMode(name string) (OverflowMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "wrap":
+		return OverflowWrap, nil
+	case "error", "trap":
+		return OverflowError, nil
+	default:
+		return OverflowWrap, fmt.Errorf("unknown overflow mode %q (expected wrap or error)", name)
+	}
+}
+
+// WordSize returns the word size used by integer operations
+func (c *Calculator) WordSize() WordSize {
+	if c.wordSize.Bits == 0 {
+		return DefaultWordSize
+	}
+	return c.wordSize
+}
+
+// SetWordSize changes the word size used by integer operations with error handling for unsupported sizes
+func (c *Calculator) SetWordSize(w WordSize) error {
+	switch w.Bits {
+	case 8, 16, 32, 64:
+		c.wordSize = w
+		return nil
+	default:
+		return fmt.Errorf("unsupported word size of %d bits", w.Bits)
+	}
+}
+
+// OverflowMode returns how integer operations handle results that do not fit the word size
+func (c *Calculator) OverflowMode() OverflowMode {
+	return c.overflow
+}
+
+// SetOverflowMode changes how integer operations handle results that do not fit the word size
+func (c *Calculator) SetOverflowMode(mode OverflowMode) {
+	c.overflow = mode
+}
+
+// Fit converts x to the word size, wrapping or reporting an overflow error according to the overflow mode
+func (c *Calculator) Fit(x *big.Int) (*big.Int, error) {
+	w := c.WordSize()
+	if x.Cmp(w.min()) >= 0 && x.Cmp(w.max()) <= 0 {
+		return x, nil
+	}
+	if c.overflow == OverflowError {
+		return nil, fmt.Errorf("integer overflow: %s does not fit in %s", x, w)
+	}
+	return c.wrap(x), nil
+}
+
+// wrap reduces x modulo 2^bits into the range of the word size
+func (c *Calculator) wrap(x *big.Int) *big.Int {
+	w := c.WordSize()
+	modulus := new(big.Int).Lsh(big.NewInt(1), w.Bits)
+	r := new(big.Int).Mod(x, modulus) // Euclidean, so 0 <= r < modulus
+	if w.Signed && r.Cmp(w.max()) > 0 {
+		r.Sub(r, modulus)
+	}
+	return r
+}
+
+// ParseInt parses a decimal, 0x hexadecimal, 0o octal or 0b binary integer literal
+// and fits it to the word size. Radix literals give the bit pattern, so 0xff is -1
+// as a signed 8 bit integer.
+func (c *Calculator) ParseInt(s string) (*big.Int, error) {
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "0o") || strings.HasPrefix(lower, "0b") {
+		x, ok := new(big.Int).SetString(lower, 0)
+		if !ok {
+			return nil, errors.New("invalid integer literal " + s)
+		}
+		if x.BitLen() > int(c.WordSize().Bits) {
+			return c.Fit(x)
+		}
+		return c.wrap(x), nil
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errors.New("invalid integer literal " + s)
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%s is not an integer", s)
+	}
+	return c.Fit(new(big.Int).Set(r.Num()))
+}
+
+// IntAdd performs integer addition within the word size
+func (c *Calculator) IntAdd(a, b *big.Int) (*big.Int, error) {
+	return c.Fit(new(big.Int).Add(a, b))
+}
+
+// IntSubtract performs integer subtraction within the word size
+func (c *Calculator) IntSubtract(a, b *big.Int) (*big.Int, error) {
+	return c.Fit(new(big.Int).Sub(a, b))
+}
+
+// IntMultiply performs integer multiplication within the word size
+func (c *Calculator) IntMultiply(a, b *big.Int) (*big.Int, error) {
+	return c.Fit(new(big.Int).Mul(a, b))
+}
+
+// IntDivide performs integer division truncated toward zero with error handling for division by zero
+func (c *Calculator) IntDivide(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	// Quo truncates like Go's / operator; -128 / -1 overflows a signed byte
+	return c.Fit(new(big.Int).Quo(a, b))
+}
+
+// Quotient performs float64 division truncated toward zero, the counterpart of ModFloat,
+// with error handling for division by zero
+func (c *Calculator) Quotient(a, b float64) (float64, error) {
+	if b == 0.0 {
+		return 0.0, errors.New("division by zero")
+	}
+	return math.Trunc(a / b), nil
+}
+
+// IntMod performs integer modulus with the sign of the dividend, matching Mod,
+// with error handling for modulus by zero
+func (c *Calculator) IntMod(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("modulus by zero")
+	}
+	return new(big.Int).Rem(a, b), nil
+}
+
+// IntPower raises base to a non-negative integer exponent within the word size
+func (c *Calculator) IntPower(base, exponent *big.Int) (*big.Int, error) {
+	if exponent.Sign() < 0 {
+		return nil, errors.New("negative exponent in integer mode")
+	}
+	if c.overflow == OverflowWrap {
+		// Exponentiation modulo 2^bits gives the wrapped result without huge intermediates
+		modulus := new(big.Int).Lsh(big.NewInt(1), c.WordSize().Bits)
+		return c.wrap(new(big.Int).Exp(base, exponent, modulus)), nil
+	}
+	if base.CmpAbs(big.NewInt(1)) > 0 && exponent.Cmp(big.NewInt(int64(c.WordSize().Bits))) >= 0 {
+		return nil, fmt.Errorf("integer overflow: %s^%s does not fit in %s", base, exponent, c.WordSize())
+	}
+	return c.Fit(new(big.Int).Exp(base, exponent, nil))
+}
+
+// And performs bitwise AND
+func (c *Calculator) And(a, b *big.Int) *big.Int {
+	return c.wrap(new(big.Int).And(a, b))
+}
+
+// Or performs bitwise OR
+func (c *Calculator) Or(a, b *big.Int) *big.Int {
+	return c.wrap(new(big.Int).Or(a, b))
+}
+
+// Xor performs bitwise exclusive OR
+func (c *Calculator) Xor(a, b *big.Int) *big.Int {
+	return c.wrap(new(big.Int).Xor(a, b))
+}
+
+// Not performs bitwise complement within the word size; it never overflows
+func (c *Calculator) Not(a *big.Int) *big.Int {
+	return c.wrap(new(big.Int).Not(a))
+}
+
+// ShiftLeft shifts a left by n bits within the word size with error handling for negative shift counts
+func (c *Calculator) ShiftLeft(a, n *big.Int) (*big.Int, error) {
+	count, err := c.shiftCount(n)
+	if err != nil {
+		return nil, err
+	}
+	return c.Fit(new(big.Int).Lsh(a, count))
+}
+
+// ShiftRight shifts a right by n bits, arithmetically for signed words, with error
+// handling for negative shift counts
+func (c *Calculator) ShiftRight(a, n *big.Int) (*big.Int, error) {
+	count, err := c.shiftCount(n)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Rsh(a, count), nil
+}
+
+// shiftCount validates a shift count, clamping it to the word size since larger
+// shifts give the same result
+func (c *Calculator) shiftCount(n *big.Int) (uint, error) {
+	if n.Sign() < 0 {
+		return 0, errors.New("negative shift count")
+	}
+	bits := c.WordSize().Bits
+	if n.Cmp(big.NewInt(int64(bits))) > 0 {
+		return bits, nil
+	}
+	return uint(n.Int64()), nil
+}
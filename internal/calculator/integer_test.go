@@ -0,0 +1,212 @@
+// integer_test.go
+package calculator
+
+import (
+	"math/big"
+	"testing"
+)
+
+// newWordCalculator returns a calculator using the named word size and overflow mode
+func newWordCalculator(t *testing.T, word string, overflow OverflowMode) *Calculator {
+	t.Helper()
+	w, err := ParseWordSize(word)
+	if err != nil {
+		t.Fatalf("ParseWordSize(%q) failed: %v", word, err)
+	}
+	calc := New()
+	if err := calc.SetWordSize(w); err != nil {
+		t.Fatalf("SetWordSize(%s) failed: %v", w, err)
+	}
+	calc.SetOverflowMode(overflow)
+	return calc
+}
+
+// =============================================================================
+// FIXED-WIDTH INTEGER TESTS
+// These tests verify programmer mode integers, word sizes and overflow handling
+// =============================================================================
+
+// TestParseWordSize verifies word size names with and without signedness markers
+func TestParseWordSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected WordSize
+	}{
+		{"32", WordSize{32, true}},
+		{"i16", WordSize{16, true}},
+		{"u8", WordSize{8, false}},
+		{"64u", WordSize{64, false}},
+		{"S32", WordSize{32, true}},
+	}
+	for _, test := range tests {
+		w, err := ParseWordSize(test.name)
+		if err != nil || w != test.expected {
+			t.Errorf("ParseWordSize(%q): expected %s, got %s (err: %v)", test.name, test.expected, w, err)
+		}
+	}
+	for _, name := range []string{"", "12", "u128", "x32"} {
+		if _, err := ParseWordSize(name); err == nil {
+			t.Errorf("Expected error parsing word size %q", name)
+		}
+	}
+	if New().WordSize() != DefaultWordSize || DefaultWordSize.String() != "i64" {
+		t.Errorf("Expected default word size i64, got %s", New().WordSize())
+	}
+	if err := New().SetWordSize(WordSize{Bits: 12}); err == nil {
+		t.Error("Expected error setting a 12 bit word")
+	}
+}
+
+// TestParseInt verifies decimal and radix literals and how they fit the word
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		word, literal, expected string
+	}{
+		{"i64", "42", "42"},
+		{"i64", "1e3", "1000"},
+		{"i64", "0x7fffffffffffffff", "9223372036854775807"},
+		{"i64", "0xffffffffffffffff", "-1"},
+		{"u64", "0xffffffffffffffff", "18446744073709551615"},
+		{"i8", "0xff", "-1"},
+		{"u8", "0b11111111", "255"},
+		{"u16", "0o777", "511"},
+		{"i8", "200", "-56"},
+	}
+	for _, test := range tests {
+		calc := newWordCalculator(t, test.word, OverflowWrap)
+		x, err := calc.ParseInt(test.literal)
+		if err != nil || x.String() != test.expected {
+			t.Errorf("ParseInt(%q) in %s: expected %s, got %v (err: %v)", test.literal, test.word, test.expected, x, err)
+		}
+	}
+
+	strict := newWordCalculator(t, "i8", OverflowError)
+	for _, literal := range []string{"1.5", "abc", "200", "0x1ff"} {
+		if _, err := strict.ParseInt(literal); err == nil {
+			t.Errorf("Expected error parsing %q as i8", literal)
+		}
+	}
+}
+
+// TestIntArithmeticWrap verifies that results wrap like two's complement hardware
+func TestIntArithmeticWrap(t *testing.T) {
+	calc := newWordCalculator(t, "i8", OverflowWrap)
+	a, b := big.NewInt(100), big.NewInt(50)
+
+	if r, _ := calc.IntAdd(a, b); r.Int64() != -106 {
+		t.Errorf("Expected 100 + 50 to wrap to -106 in i8, got %s", r)
+	}
+	if r, _ := calc.IntSubtract(big.NewInt(-100), b); r.Int64() != 106 {
+		t.Errorf("Expected -100 - 50 to wrap to 106 in i8, got %s", r)
+	}
+	if r, _ := calc.IntMultiply(a, b); r.Int64() != -120 {
+		t.Errorf("Expected 100 * 50 to wrap to -120 in i8, got %s", r)
+	}
+	if r, _ := calc.IntDivide(big.NewInt(-128), big.NewInt(-1)); r.Int64() != -128 {
+		t.Errorf("Expected -128 / -1 to wrap to -128 in i8, got %s", r)
+	}
+	if r, _ := calc.IntPower(big.NewInt(3), big.NewInt(5)); r.Int64() != -13 {
+		t.Errorf("Expected 3^5 to wrap to -13 in i8, got %s", r)
+	}
+}
+
+// TestIntArithmeticOverflowError verifies that overflow is reported in error mode
+func TestIntArithmeticOverflowError(t *testing.T) {
+	calc := newWordCalculator(t, "u8", OverflowError)
+	if r, err := calc.IntAdd(big.NewInt(200), big.NewInt(55)); err != nil || r.Int64() != 255 {
+		t.Errorf("Expected 200 + 55 = 255 in u8, got %v (err: %v)", r, err)
+	}
+	if _, err := calc.IntAdd(big.NewInt(200), big.NewInt(56)); err == nil {
+		t.Error("Expected overflow error for 200 + 56 in u8")
+	}
+	if _, err := calc.IntSubtract(big.NewInt(0), big.NewInt(1)); err == nil {
+		t.Error("Expected overflow error for 0 - 1 in u8")
+	}
+	if _, err := calc.IntPower(big.NewInt(2), big.NewInt(1000000)); err == nil {
+		t.Error("Expected overflow error for 2^1000000 in u8")
+	}
+	if r, err := calc.IntPower(big.NewInt(1), big.NewInt(1000000)); err != nil || r.Int64() != 1 {
+		t.Errorf("Expected 1^1000000 = 1, got %v (err: %v)", r, err)
+	}
+}
+
+// TestIntDivideAndModByZero verifies error handling for a zero divisor
+func TestIntDivideAndModByZero(t *testing.T) {
+	calc := New()
+	if _, err := calc.IntDivide(big.NewInt(1), new(big.Int)); err == nil {
+		t.Error("Expected error for integer division by zero")
+	}
+	if _, err := calc.IntMod(big.NewInt(1), new(big.Int)); err == nil {
+		t.Error("Expected error for integer modulus by zero")
+	}
+	want, _ := calc.Mod(-7, 3)
+	if r, _ := calc.IntMod(big.NewInt(-7), big.NewInt(3)); r.Int64() != int64(want) {
+		t.Errorf("Expected IntMod to match Mod for -7 %% 3, got %s", r)
+	}
+	if _, err := calc.IntPower(big.NewInt(2), big.NewInt(-1)); err == nil {
+		t.Error("Expected error for a negative exponent")
+	}
+}
+
+// TestQuotient verifies truncated division of float64 operands
+func TestQuotient(t *testing.T) {
+	calc := New()
+	if q, err := calc.Quotient(-7.5, 2); err != nil || q != -3 {
+		t.Errorf("Expected -7.5 // 2 = -3, got %v (err: %v)", q, err)
+	}
+	if _, err := calc.Quotient(1, 0); err == nil {
+		t.Error("Expected error for quotient by zero")
+	}
+}
+
+// TestBitwise verifies the bitwise operators in signed and unsigned words
+func TestBitwise(t *testing.T) {
+	calc := newWordCalculator(t, "u8", OverflowError)
+	a, b := big.NewInt(0b1100), big.NewInt(0b1010)
+
+	if r := calc.And(a, b); r.Int64() != 0b1000 {
+		t.Errorf("Expected 12 & 10 = 8, got %s", r)
+	}
+	if r := calc.Or(a, b); r.Int64() != 0b1110 {
+		t.Errorf("Expected 12 | 10 = 14, got %s", r)
+	}
+	if r := calc.Xor(a, b); r.Int64() != 0b0110 {
+		t.Errorf("Expected 12 xor 10 = 6, got %s", r)
+	}
+	if r := calc.Not(a); r.Int64() != 0b11110011 {
+		t.Errorf("Expected ~12 = 243 in u8, got %s", r)
+	}
+	if r, err := calc.ShiftLeft(big.NewInt(1), big.NewInt(7)); err != nil || r.Int64() != 128 {
+		t.Errorf("Expected 1 << 7 = 128 in u8, got %v (err: %v)", r, err)
+	}
+	if _, err := calc.ShiftLeft(big.NewInt(1), big.NewInt(8)); err == nil {
+		t.Error("Expected overflow error for 1 << 8 in u8")
+	}
+	if r, err := calc.ShiftRight(big.NewInt(200), big.NewInt(1000)); err != nil || r.Sign() != 0 {
+		t.Errorf("Expected 200 >> 1000 = 0, got %v (err: %v)", r, err)
+	}
+	if _, err := calc.ShiftRight(a, big.NewInt(-1)); err == nil {
+		t.Error("Expected error for a negative shift count")
+	}
+
+	signed := newWordCalculator(t, "i8", OverflowWrap)
+	if r, _ := signed.ShiftRight(big.NewInt(-128), big.NewInt(7)); r.Int64() != -1 {
+		t.Errorf("Expected arithmetic shift -128 >> 7 = -1 in i8, got %s", r)
+	}
+	if r, _ := signed.ShiftLeft(big.NewInt(1), big.NewInt(7)); r.Int64() != -128 {
+		t.Errorf("Expected 1 << 7 to wrap to -128 in i8, got %s", r)
+	}
+}
+
+// TestParseOverflowMode verifies overflow mode names
+func TestParseOverflowMode(t *testing.T) {
+	if mode, err := ParseOverflowMode("Error"); err != nil || mode != OverflowError || mode.String() != "error" {
+		t.Errorf("Expected error mode, got %s (err: %v)", mode, err)
+	}
+	if New().OverflowMode() != OverflowWrap {
+		t.Error("Expected wrap to be the default overflow mode")
+	}
+	if _, err := ParseOverflowMode("saturate"); err == nil {
+		t.Error("Expected error for unknown overflow mode")
+	}
+}
@@ -0,0 +1,270 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Matrix is a dense matrix of float64 stored in row-major order. A vector is a
+// matrix with a single row or column.
+type Matrix struct {
+	Rows, Cols int
+	Data       []float64
+}
+
+// NewMatrix returns a rows by cols matrix of zeros
+func NewMatrix(rows, cols int) Matrix {
+	return Matrix{Rows: rows, Cols: cols, Data: make([]float64, rows*cols)}
+}
+
+// Identity returns the n by n identity matrix
+func Identity(n int) Matrix {
+	m := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+// At returns the element in row i and column j
+func (m Matrix) At(i, j int) float64 {
+	return m.Data[i*m.Cols+j]
+}
+
+// Set changes the element in row i and column j
+func (m Matrix) Set(i, j int, v float64) {
+	m.Data[i*m.Cols+j] = v
+}
+
+// String formats the dimensions of the matrix, e.g. 2x3
+func (m Matrix) String() string {
+	return fmt.Sprintf("%dx%d", m.Rows, m.Cols)
+}
+
+// clone returns a copy of m that can be modified independently
+func (m Matrix) clone() Matrix {
+	return Matrix{Rows: m.Rows, Cols: m.Cols, Data: append([]float64(nil), m.Data...)}
+}
+
+// singularTolerance is the relative size below which a pivot counts as zero
+const singularTolerance = 1e-12
+
+// MatMultiply returns the matrix product a × b with error handling for
+// mismatched dimensions
+func (c *Calculator) MatMultiply(a, b Matrix) (Matrix, error) {
+	if a.Cols != b.Rows {
+		return Matrix{}, fmt.Errorf("cannot multiply %s and %s matrices", a, b)
+	}
+	product := NewMatrix(a.Rows, b.Cols)
+	terms := make([]float64, a.Cols)
+	for i := 0; i < a.Rows; i++ {
+		for j := 0; j < b.Cols; j++ {
+			for k := 0; k < a.Cols; k++ {
+				terms[k] = a.At(i, k) * b.At(k, j)
+			}
+			product.Set(i, j, c.Sum(terms...))
+		}
+	}
+	return product, nil
+}
+
+// Transpose returns the transpose of m, swapping its rows and columns
+func (c *Calculator) Transpose(m Matrix) Matrix {
+	t := NewMatrix(m.Cols, m.Rows)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			t.Set(j, i, m.At(i, j))
+		}
+	}
+	return t
+}
+
+// lu holds the LU decomposition with partial pivoting of a square matrix: the
+// unit lower and upper triangular factors packed into one matrix, the row
+// permutation and its sign
+type lu struct {
+	factors Matrix
+	perm    []int
+	sign    float64
+}
+
+// decompose computes the LU decomposition of the square matrix m, reporting
+// whether m is singular
+func decompose(m Matrix) (lu, bool) {
+	n := m.Rows
+	f := m.clone()
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	sign := 1.0
+	scale := maxAbs(m)
+	singular := scale == 0
+	for k := 0; k < n && !singular; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(f.At(i, k)) > math.Abs(f.At(pivot, k)) {
+				pivot = i
+			}
+		}
+		if math.Abs(f.At(pivot, k)) <= singularTolerance*scale {
+			singular = true
+			break
+		}
+		if pivot != k {
+			for j := 0; j < n; j++ {
+				a, b := f.At(k, j), f.At(pivot, j)
+				f.Set(k, j, b)
+				f.Set(pivot, j, a)
+			}
+			perm[k], perm[pivot] = perm[pivot], perm[k]
+			sign = -sign
+		}
+		for i := k + 1; i < n; i++ {
+			l := f.At(i, k) / f.At(k, k)
+			f.Set(i, k, l)
+			for j := k + 1; j < n; j++ {
+				f.Set(i, j, f.At(i, j)-l*f.At(k, j))
+			}
+		}
+	}
+	return lu{factors: f, perm: perm, sign: sign}, singular
+}
+
+// solve solves the decomposed system for each column of b
+func (d lu) solve(b Matrix) Matrix {
+	n := d.factors.Rows
+	x := NewMatrix(n, b.Cols)
+	for col := 0; col < b.Cols; col++ {
+		// Forward substitution with the unit lower factor, then back substitution
+		y := make([]float64, n)
+		for i := 0; i < n; i++ {
+			sum := b.At(d.perm[i], col)
+			for j := 0; j < i; j++ {
+				sum -= d.factors.At(i, j) * y[j]
+			}
+			y[i] = sum
+		}
+		for i := n - 1; i >= 0; i-- {
+			sum := y[i]
+			for j := i + 1; j < n; j++ {
+				sum -= d.factors.At(i, j) * x.At(j, col)
+			}
+			x.Set(i, col, sum/d.factors.At(i, i))
+		}
+	}
+	return x
+}
+
+// maxAbs returns the largest absolute value of the elements of m
+func maxAbs(m Matrix) float64 {
+	largest := 0.0
+	for _, v := range m.Data {
+		largest = math.Max(largest, math.Abs(v))
+	}
+	return largest
+}
+
+// Det returns the determinant of the square matrix m with error handling for
+// non-square matrices
+func (c *Calculator) Det(m Matrix) (float64, error) {
+	if m.Rows != m.Cols {
+		return 0, fmt.Errorf("determinant requires a square matrix, got %s", m)
+	}
+	d, singular := decompose(m)
+	if singular {
+		return 0, nil
+	}
+	det := d.sign
+	for i := 0; i < m.Rows; i++ {
+		det *= d.factors.At(i, i)
+	}
+	return det, nil
+}
+
+// Inverse returns the inverse of the square matrix m with error handling for
+// non-square and singular matrices
+func (c *Calculator) Inverse(m Matrix) (Matrix, error) {
+	if m.Rows != m.Cols {
+		return Matrix{}, fmt.Errorf("inverse requires a square matrix, got %s", m)
+	}
+	d, singular := decompose(m)
+	if singular {
+		return Matrix{}, errors.New("matrix is singular")
+	}
+	return d.solve(Identity(m.Rows)), nil
+}
+
+// Solve returns x such that a × x = b for a square matrix a, where b has a row
+// for each row of a, with error handling for mismatched dimensions and singular
+// systems
+func (c *Calculator) Solve(a, b Matrix) (Matrix, error) {
+	if a.Rows != a.Cols {
+		return Matrix{}, fmt.Errorf("linear system requires a square matrix, got %s", a)
+	}
+	if b.Rows != a.Rows {
+		return Matrix{}, fmt.Errorf("cannot solve a %s system for a %s right-hand side", a, b)
+	}
+	d, singular := decompose(a)
+	if singular {
+		return Matrix{}, errors.New("matrix is singular")
+	}
+	return d.solve(b), nil
+}
+
+// Rank returns the number of linearly independent rows of m, found by Gaussian
+// elimination with partial pivoting
+func (c *Calculator) Rank(m Matrix) int {
+	f := m.clone()
+	tolerance := singularTolerance * maxAbs(m)
+	rank := 0
+	for col := 0; col < f.Cols && rank < f.Rows; col++ {
+		pivot := rank
+		for i := rank + 1; i < f.Rows; i++ {
+			if math.Abs(f.At(i, col)) > math.Abs(f.At(pivot, col)) {
+				pivot = i
+			}
+		}
+		if math.Abs(f.At(pivot, col)) <= tolerance {
+			continue
+		}
+		for j := col; j < f.Cols; j++ {
+			a, b := f.At(rank, j), f.At(pivot, j)
+			f.Set(rank, j, b)
+			f.Set(pivot, j, a)
+		}
+		for i := rank + 1; i < f.Rows; i++ {
+			l := f.At(i, col) / f.At(rank, col)
+			for j := col; j < f.Cols; j++ {
+				f.Set(i, j, f.At(i, j)-l*f.At(rank, j))
+			}
+		}
+		rank++
+	}
+	return rank
+}
+
+// Dot returns the dot product of two vectors of the same length
+func (c *Calculator) Dot(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("dot product of vectors of length %d and %d", len(a), len(b))
+	}
+	terms := make([]float64, len(a))
+	for i := range a {
+		terms[i] = a[i] * b[i]
+	}
+	return c.Sum(terms...), nil
+}
+
+// Cross returns the cross product of two vectors of length 3
+func (c *Calculator) Cross(a, b []float64) ([]float64, error) {
+	if len(a) != 3 || len(b) != 3 {
+		return nil, fmt.Errorf("cross product requires vectors of length 3, got %d and %d", len(a), len(b))
+	}
+	return []float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}, nil
+}
@@ -0,0 +1,136 @@
+// matrix_test.go
+package calculator
+
+import (
+	"testing"
+)
+
+// =============================================================================
+// MATRIX TESTS
+// These tests verify matrix products, determinants, inverses, ranks, linear
+// systems and vector products, including dimension and singularity errors
+// =============================================================================
+
+// matrixOf builds a matrix from its rows
+func matrixOf(rows ...[]float64) Matrix {
+	m := NewMatrix(len(rows), len(rows[0]))
+	for i, row := range rows {
+		for j, v := range row {
+			m.Set(i, j, v)
+		}
+	}
+	return m
+}
+
+// matrixEquals reports whether two matrices have the same shape and elements
+// within tolerance
+func matrixEquals(a, b Matrix, tolerance float64) bool {
+	if a.Rows != b.Rows || a.Cols != b.Cols {
+		return false
+	}
+	for i := range a.Data {
+		if !floatEquals(a.Data[i], b.Data[i], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestMatMultiply verifies matrix products and the error for mismatched dimensions
+func TestMatMultiply(t *testing.T) {
+	calc := New()
+	a := matrixOf([]float64{1, 2, 3}, []float64{4, 5, 6})
+	b := matrixOf([]float64{7, 8}, []float64{9, 10}, []float64{11, 12})
+
+	product, err := calc.MatMultiply(a, b)
+	if err != nil || !matrixEquals(product, matrixOf([]float64{58, 64}, []float64{139, 154}), 0) {
+		t.Errorf("Expected [[58, 64], [139, 154]], got %v (err: %v)", product.Data, err)
+	}
+	if _, err := calc.MatMultiply(a, a); err == nil {
+		t.Error("Expected error multiplying 2x3 by 2x3")
+	}
+	if tr := calc.Transpose(a); !matrixEquals(tr, matrixOf([]float64{1, 4}, []float64{2, 5}, []float64{3, 6}), 0) {
+		t.Errorf("Expected transpose [[1, 4], [2, 5], [3, 6]], got %v", tr.Data)
+	}
+}
+
+// TestDetInverse verifies determinants and inverses of regular and singular matrices
+func TestDetInverse(t *testing.T) {
+	calc := New()
+	a := matrixOf([]float64{4, 7}, []float64{2, 6})
+
+	if det, err := calc.Det(a); err != nil || !floatEquals(det, 10, 1e-12) {
+		t.Errorf("Expected det = 10, got %f (err: %v)", det, err)
+	}
+	inv, err := calc.Inverse(a)
+	if err != nil || !matrixEquals(inv, matrixOf([]float64{0.6, -0.7}, []float64{-0.2, 0.4}), 1e-12) {
+		t.Errorf("Expected inverse [[0.6, -0.7], [-0.2, 0.4]], got %v (err: %v)", inv.Data, err)
+	}
+	// A row swap changes the sign of the determinant
+	if det, err := calc.Det(matrixOf([]float64{0, 1}, []float64{1, 0})); err != nil || det != -1 {
+		t.Errorf("Expected det = -1, got %f (err: %v)", det, err)
+	}
+
+	singular := matrixOf([]float64{1, 2}, []float64{2, 4})
+	if det, err := calc.Det(singular); err != nil || det != 0 {
+		t.Errorf("Expected det = 0 for a singular matrix, got %f (err: %v)", det, err)
+	}
+	if _, err := calc.Inverse(singular); err == nil {
+		t.Error("Expected error inverting a singular matrix")
+	}
+	if _, err := calc.Det(matrixOf([]float64{1, 2})); err == nil {
+		t.Error("Expected error for the determinant of a non-square matrix")
+	}
+}
+
+// TestSolveRank verifies linear systems and ranks
+func TestSolveRank(t *testing.T) {
+	calc := New()
+	a := matrixOf([]float64{2, 1, -1}, []float64{-3, -1, 2}, []float64{-2, 1, 2})
+	b := matrixOf([]float64{8}, []float64{-11}, []float64{-3})
+
+	x, err := calc.Solve(a, b)
+	if err != nil || !matrixEquals(x, matrixOf([]float64{2}, []float64{3}, []float64{-1}), 1e-12) {
+		t.Errorf("Expected x = [2, 3, -1], got %v (err: %v)", x.Data, err)
+	}
+	if _, err := calc.Solve(a, matrixOf([]float64{1}, []float64{2})); err == nil {
+		t.Error("Expected error for a right-hand side of the wrong length")
+	}
+	if _, err := calc.Solve(matrixOf([]float64{1, 1}, []float64{1, 1}), matrixOf([]float64{1}, []float64{2})); err == nil {
+		t.Error("Expected error for a singular system")
+	}
+
+	tests := []struct {
+		m        Matrix
+		expected int
+	}{
+		{a, 3},
+		{matrixOf([]float64{1, 2, 3}, []float64{2, 4, 6}), 1},
+		{matrixOf([]float64{1, 2}, []float64{3, 4}, []float64{5, 6}), 2},
+		{NewMatrix(2, 2), 0},
+	}
+	for _, test := range tests {
+		if rank := calc.Rank(test.m); rank != test.expected {
+			t.Errorf("Expected rank %d for %v, got %d", test.expected, test.m.Data, rank)
+		}
+	}
+}
+
+// TestDotCross verifies vector products and their length checks
+func TestDotCross(t *testing.T) {
+	calc := New()
+
+	if dot, err := calc.Dot([]float64{1, 2, 3}, []float64{4, 5, 6}); err != nil || dot != 32 {
+		t.Errorf("Expected dot = 32, got %f (err: %v)", dot, err)
+	}
+	if _, err := calc.Dot([]float64{1}, []float64{1, 2}); err == nil {
+		t.Error("Expected error for vectors of different lengths")
+	}
+	cross, err := calc.Cross([]float64{1, 0, 0}, []float64{0, 1, 0})
+	if err != nil || len(cross) != 3 || cross[0] != 0 || cross[1] != 0 || cross[2] != 1 {
+		t.Errorf("Expected cross = [0, 0, 1], got %v (err: %v)", cross, err)
+	}
+	if _, err := calc.Cross([]float64{1, 2}, []float64{3, 4}); err == nil {
+		t.Error("Expected error for the cross product of 2-vectors")
+	}
+}
@@ -0,0 +1,39 @@
+package calculator
+
+// NumberMode selects the kind of numbers the calculator works with
+type NumberMode int
+
+const (
+	// FloatMode works in float64, or in big.Float when a precision is set
+	FloatMode NumberMode = iota
+	// RationalMode works in exact big.Rat fractions
+	RationalMode
+	// IntegerMode works in fixed-width integers of the configured WordSize
+	IntegerMode
+	// ComplexMode works in complex128 so that, e.g., sqrt(-1) is i
+	ComplexMode
+)
+
+// String returns the name of the number mode
+func (m NumberMode) String() string {
+	switch m {
+	case RationalMode:
+		return "rational"
+	case IntegerMode:
+		return "integer"
+	case ComplexMode:
+		return "complex"
+	default:
+		return "float"
+	}
+}
+
+// NumberMode returns the kind of numbers the calculator works with
+func (c *Calculator) NumberMode() NumberMode {
+	return c.numberMode
+}
+
+// SetNumberMode changes the kind of numbers the calculator works with
+func (c *Calculator) SetNumberMode(mode NumberMode) {
+	c.numberMode = mode
+}
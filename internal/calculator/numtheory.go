@@ -0,0 +1,273 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+const (
+	// maxFactorial bounds the argument of Factorial, whose result has about
+	// 35,000 digits at the limit
+	maxFactorial = 10000
+	// trialDivisionLimit is the largest divisor Factor tries before switching to
+	// Pollard's rho method
+	trialDivisionLimit = 10000
+	// rhoIterations bounds the steps of each attempt of Pollard's rho method
+	rhoIterations = 1 << 20
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTwo = big.NewInt(2)
+)
+
+// Factorial returns n! for a non-negative integer n
+func (c *Calculator) Factorial(n *big.Int) (*big.Int, error) {
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("factorial of negative integer %s", n)
+	}
+	if n.Cmp(big.NewInt(maxFactorial)) > 0 {
+		return nil, fmt.Errorf("factorial of %s is too large (limit %d)", n, maxFactorial)
+	}
+	return new(big.Int).MulRange(1, n.Int64()), nil
+}
+
+// Gamma returns the gamma function at x, which extends the factorial to real
+// numbers with gamma(n+1) = n!, with error handling for its poles at zero and
+// the negative integers
+func (c *Calculator) Gamma(x float64) (float64, error) {
+	if x <= 0 && x == math.Trunc(x) {
+		return 0.0, fmt.Errorf("gamma is undefined at %g", x)
+	}
+	return math.Gamma(x), nil
+}
+
+// Choose returns the binomial coefficient n choose k, the number of ways to pick
+// k of n items, which is 0 when k is negative or greater than n
+func (c *Calculator) Choose(n, k *big.Int) (*big.Int, error) {
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("cannot choose from a negative number of items, got %s", n)
+	}
+	if k.Sign() < 0 || k.Cmp(n) > 0 {
+		return new(big.Int), nil
+	}
+	// The binomial coefficient is a product of min(k, n-k) factors
+	smaller := new(big.Int).Sub(n, k)
+	if k.Cmp(smaller) < 0 {
+		smaller.Set(k)
+	}
+	if !n.IsInt64() || smaller.Cmp(big.NewInt(maxFactorial)) > 0 {
+		return nil, fmt.Errorf("%s choose %s is too large to compute", n, k)
+	}
+	return new(big.Int).Binomial(n.Int64(), k.Int64()), nil
+}
+
+// Permutations returns the number of ordered arrangements of k of n items,
+// n! / (n-k)!, which is 0 when k is negative or greater than n
+func (c *Calculator) Permutations(n, k *big.Int) (*big.Int, error) {
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("cannot arrange a negative number of items, got %s", n)
+	}
+	if k.Sign() < 0 || k.Cmp(n) > 0 {
+		return new(big.Int), nil
+	}
+	if k.Cmp(big.NewInt(maxFactorial)) > 0 {
+		return nil, fmt.Errorf("permutations of %s items are too many to count", k)
+	}
+	if k.Sign() == 0 {
+		return big.NewInt(1), nil
+	}
+	// The product (n-k+1) ... n, with at most maxFactorial factors
+	p := new(big.Int).Set(n)
+	factor := new(big.Int).Set(n)
+	for i := int64(1); i < k.Int64(); i++ {
+		factor.Sub(factor, bigOne)
+		p.Mul(p, factor)
+	}
+	return p, nil
+}
+
+// GCD returns the greatest common divisor of the values, which is never negative
+func (c *Calculator) GCD(values ...*big.Int) *big.Int {
+	g := new(big.Int)
+	for _, v := range values {
+		g.GCD(nil, nil, g, new(big.Int).Abs(v))
+	}
+	return g
+}
+
+// LCM returns the least common multiple of the values, which is never negative
+// and is 0 when any value is 0
+func (c *Calculator) LCM(values ...*big.Int) *big.Int {
+	l := big.NewInt(1)
+	for _, v := range values {
+		if v.Sign() == 0 {
+			return new(big.Int)
+		}
+		a := new(big.Int).Abs(v)
+		g := new(big.Int).GCD(nil, nil, l, a)
+		l.Mul(l, a.Quo(a, g))
+	}
+	return l
+}
+
+// IsPrime reports whether n is a prime number. The test is exact below 2^64 and
+// wrong with probability below 4^-20 above.
+func (c *Calculator) IsPrime(n *big.Int) bool {
+	return n.ProbablyPrime(20)
+}
+
+// NextPrime returns the smallest prime greater than n
+func (c *Calculator) NextPrime(n *big.Int) *big.Int {
+	if n.Cmp(bigTwo) < 0 {
+		return big.NewInt(2)
+	}
+	p := new(big.Int).Add(n, bigOne)
+	if p.Bit(0) == 0 && p.Cmp(bigTwo) != 0 {
+		p.Add(p, bigOne)
+	}
+	for !c.IsPrime(p) {
+		p.Add(p, bigTwo)
+	}
+	return p
+}
+
+// Factor returns the prime factorisation of n > 0 in increasing order, with
+// each prime repeated according to its multiplicity; 1 has no prime factors.
+// Small factors are found by trial division and larger ones by Pollard's rho
+// method.
+func (c *Calculator) Factor(n *big.Int) ([]*big.Int, error) {
+	if n.Sign() <= 0 {
+		return nil, fmt.Errorf("can only factor positive integers, got %s", n)
+	}
+	var factors []*big.Int
+	rest := new(big.Int).Set(n)
+	d, q, r := new(big.Int), new(big.Int), new(big.Int)
+	for i := int64(2); i <= trialDivisionLimit; i++ {
+		d.SetInt64(i)
+		if new(big.Int).Mul(d, d).Cmp(rest) > 0 {
+			break
+		}
+		for {
+			q.QuoRem(rest, d, r)
+			if r.Sign() != 0 {
+				break
+			}
+			factors = append(factors, big.NewInt(i))
+			rest.Set(q)
+		}
+	}
+	if rest.Cmp(bigOne) == 0 {
+		return factors, nil
+	}
+
+	large, err := c.splitFactors(rest)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(large, func(i, j int) bool { return large[i].Cmp(large[j]) < 0 })
+	return append(factors, large...), nil
+}
+
+// splitFactors factors n, which has no factors up to the trial division limit,
+// by recursively splitting composites with Pollard's rho method
+func (c *Calculator) splitFactors(n *big.Int) ([]*big.Int, error) {
+	if c.IsPrime(n) || n.Cmp(bigOne) == 0 {
+		return []*big.Int{n}, nil
+	}
+	d, err := pollardRho(n)
+	if err != nil {
+		return nil, err
+	}
+	left, err := c.splitFactors(d)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.splitFactors(new(big.Int).Quo(n, d))
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// pollardRho finds a non-trivial divisor of the composite n with Brent's
+// variant of Pollard's rho method, trying several polynomials x^2 + c
+func pollardRho(n *big.Int) (*big.Int, error) {
+	x, y, ys, q, diff, g := new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	step := func(v *big.Int, add int64) {
+		v.Mul(v, v)
+		v.Add(v, big.NewInt(add))
+		v.Mod(v, n)
+	}
+	const batch = 128
+	for add := int64(1); add <= 10; add++ {
+		y.SetInt64(2)
+		q.SetInt64(1)
+		g.SetInt64(1)
+		for r := 1; g.Cmp(bigOne) == 0 && r <= rhoIterations; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				step(y, add)
+			}
+			for k := 0; k < r && g.Cmp(bigOne) == 0; k += batch {
+				ys.Set(y)
+				for i := 0; i < batch && i < r-k; i++ {
+					step(y, add)
+					q.Mul(q, diff.Abs(diff.Sub(x, y)))
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+		}
+		if g.Cmp(n) == 0 {
+			// The batch overshot; retrace it one step at a time
+			for {
+				step(ys, add)
+				g.GCD(nil, nil, diff.Abs(diff.Sub(x, ys)), n)
+				if g.Cmp(bigOne) != 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(bigOne) != 0 && g.Cmp(n) != 0 {
+			return new(big.Int).Set(g), nil
+		}
+	}
+	return nil, fmt.Errorf("could not factor %s", n)
+}
+
+// ModPow returns base^exp modulo m for m > 0, in [0, m). A negative exponent
+// raises the inverse of base, which must then exist.
+func (c *Calculator) ModPow(base, exp, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, fmt.Errorf("modulus must be positive, got %s", m)
+	}
+	b := new(big.Int).Mod(base, m)
+	e := new(big.Int).Set(exp)
+	if e.Sign() < 0 {
+		inv, err := c.ModInverse(b, m)
+		if err != nil {
+			return nil, err
+		}
+		b, e = inv, e.Neg(e)
+	}
+	return new(big.Int).Exp(b, e, m), nil
+}
+
+// ModInverse returns the x in [0, m) with a*x = 1 modulo m, which exists when a
+// and m have no common factor
+func (c *Calculator) ModInverse(a, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, fmt.Errorf("modulus must be positive, got %s", m)
+	}
+	if m.Cmp(bigOne) == 0 {
+		return new(big.Int), nil
+	}
+	inv := new(big.Int).ModInverse(new(big.Int).Mod(a, m), m)
+	if inv == nil {
+		return nil, fmt.Errorf("%s has no inverse modulo %s", a, m)
+	}
+	return inv, nil
+}
@@ -0,0 +1,260 @@
+// numtheory_test.go
+package calculator
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// COMBINATORICS AND NUMBER THEORY TESTS
+// These tests verify factorials, binomial coefficients, divisibility, primes
+// and modular arithmetic on big integers
+// =============================================================================
+
+// bigInt parses a decimal integer for the tests
+func bigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid integer %q", s)
+	}
+	return x
+}
+
+// TestFactorial verifies exact factorials, including ones beyond float64
+func TestFactorial(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "1"},
+		{1, "1"},
+		{5, "120"},
+		{20, "2432902008176640000"},
+		{30, "265252859812191058636308480000000"},
+	}
+	for _, test := range tests {
+		got, err := calc.Factorial(big.NewInt(test.n))
+		if err != nil {
+			t.Errorf("Factorial(%d) failed: %v", test.n, err)
+			continue
+		}
+		if got.String() != test.expected {
+			t.Errorf("Factorial(%d): expected %s, got %s", test.n, test.expected, got)
+		}
+	}
+
+	if _, err := calc.Factorial(big.NewInt(-1)); err == nil {
+		t.Error("Factorial(-1): expected an error")
+	}
+	if _, err := calc.Factorial(big.NewInt(maxFactorial + 1)); err == nil {
+		t.Error("Factorial above the limit: expected an error")
+	}
+}
+
+// TestGamma verifies the gamma function and its poles
+func TestGamma(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		x        float64
+		expected float64
+	}{
+		{5, 24},
+		{0.5, math.Sqrt(math.Pi)},
+		{1.5, math.Sqrt(math.Pi) / 2},
+		{-0.5, -2 * math.Sqrt(math.Pi)},
+	}
+	for _, test := range tests {
+		got, err := calc.Gamma(test.x)
+		if err != nil {
+			t.Errorf("Gamma(%g) failed: %v", test.x, err)
+			continue
+		}
+		if !floatEquals(got, test.expected, 1e-12) {
+			t.Errorf("Gamma(%g): expected %g, got %g", test.x, test.expected, got)
+		}
+	}
+
+	for _, x := range []float64{0, -1, -7} {
+		if _, err := calc.Gamma(x); err == nil {
+			t.Errorf("Gamma(%g): expected an error at the pole", x)
+		}
+	}
+}
+
+// TestChooseAndPermutations verifies binomial coefficients and arrangements
+func TestChooseAndPermutations(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		n, k         int64
+		choose, perm string
+	}{
+		{5, 2, "10", "20"},
+		{10, 0, "1", "1"},
+		{10, 10, "1", "3628800"},
+		{4, 5, "0", "0"},
+		{4, -1, "0", "0"},
+		{100, 50, "100891344545564193334812497256", "3068518756254966037202730459529469739228459721684688959447786986982158958772355072000000000000"},
+	}
+	for _, test := range tests {
+		n, k := big.NewInt(test.n), big.NewInt(test.k)
+		choose, err := calc.Choose(n, k)
+		if err != nil || choose.String() != test.choose {
+			t.Errorf("Choose(%d, %d): expected %s, got %v (%v)", test.n, test.k, test.choose, choose, err)
+		}
+		perm, err := calc.Permutations(n, k)
+		if err != nil || perm.String() != test.perm {
+			t.Errorf("Permutations(%d, %d): expected %s, got %v (%v)", test.n, test.k, test.perm, perm, err)
+		}
+	}
+
+	if _, err := calc.Choose(big.NewInt(-3), big.NewInt(1)); err == nil {
+		t.Error("Choose(-3, 1): expected an error")
+	}
+	if _, err := calc.Choose(bigInt(t, "1000000000000"), bigInt(t, "500000000000")); err == nil {
+		t.Error("Choose of a huge coefficient: expected an error")
+	}
+}
+
+// TestGCDAndLCM verifies divisibility across signs, zeros and many values
+func TestGCDAndLCM(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		values   []int64
+		gcd, lcm string
+	}{
+		{[]int64{12, 18}, "6", "36"},
+		{[]int64{-12, 18}, "6", "36"},
+		{[]int64{12, 18, 8}, "2", "72"},
+		{[]int64{0, 5}, "5", "0"},
+		{[]int64{7, 13}, "1", "91"},
+	}
+	for _, test := range tests {
+		values := make([]*big.Int, len(test.values))
+		for i, v := range test.values {
+			values[i] = big.NewInt(v)
+		}
+		if got := calc.GCD(values...); got.String() != test.gcd {
+			t.Errorf("GCD(%v): expected %s, got %s", test.values, test.gcd, got)
+		}
+		if got := calc.LCM(values...); got.String() != test.lcm {
+			t.Errorf("LCM(%v): expected %s, got %s", test.values, test.lcm, got)
+		}
+	}
+}
+
+// TestPrimes verifies primality and the search for the next prime
+func TestPrimes(t *testing.T) {
+	calc := New()
+
+	primes := []string{"2", "3", "97", "7919", "2305843009213693951", "170141183460469231731687303715884105727"}
+	for _, p := range primes {
+		if !calc.IsPrime(bigInt(t, p)) {
+			t.Errorf("IsPrime(%s): expected true", p)
+		}
+	}
+	composites := []string{"-7", "0", "1", "4", "561", "1000000000000000000"}
+	for _, c := range composites {
+		if calc.IsPrime(bigInt(t, c)) {
+			t.Errorf("IsPrime(%s): expected false", c)
+		}
+	}
+
+	next := []struct {
+		n, expected string
+	}{
+		{"-10", "2"},
+		{"2", "3"},
+		{"13", "17"},
+		{"100", "101"},
+		{"1000000000000", "1000000000039"},
+	}
+	for _, test := range next {
+		if got := calc.NextPrime(bigInt(t, test.n)); got.String() != test.expected {
+			t.Errorf("NextPrime(%s): expected %s, got %s", test.n, test.expected, got)
+		}
+	}
+}
+
+// TestFactor verifies factorisations by trial division and Pollard's rho method
+func TestFactor(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		n        string
+		expected string
+	}{
+		{"1", ""},
+		{"2", "2"},
+		{"360", "2 2 2 3 3 5"},
+		{"9973", "9973"},
+		{"1000000016000000063", "1000000007 1000000009"},
+		{"600851475143", "71 839 1471 6857"},
+		{"18446744073709551617", "274177 67280421310721"},
+		{"2305843009213693951", "2305843009213693951"},
+	}
+	for _, test := range tests {
+		factors, err := calc.Factor(bigInt(t, test.n))
+		if err != nil {
+			t.Errorf("Factor(%s) failed: %v", test.n, err)
+			continue
+		}
+		text := make([]string, len(factors))
+		for i, f := range factors {
+			text[i] = f.String()
+		}
+		if got := strings.Join(text, " "); got != test.expected {
+			t.Errorf("Factor(%s): expected %q, got %q", test.n, test.expected, got)
+		}
+	}
+
+	for _, n := range []int64{0, -12} {
+		if _, err := calc.Factor(big.NewInt(n)); err == nil {
+			t.Errorf("Factor(%d): expected an error", n)
+		}
+	}
+}
+
+// TestModularArithmetic verifies modular powers and inverses
+func TestModularArithmetic(t *testing.T) {
+	calc := New()
+
+	powers := []struct {
+		base, exp, m int64
+		expected     string
+	}{
+		{4, 13, 497, "445"},
+		{-2, 3, 7, "6"},
+		{3, 0, 7, "1"},
+		{3, -1, 7, "5"},
+		{3, -2, 7, "4"},
+	}
+	for _, test := range powers {
+		got, err := calc.ModPow(big.NewInt(test.base), big.NewInt(test.exp), big.NewInt(test.m))
+		if err != nil || got.String() != test.expected {
+			t.Errorf("ModPow(%d, %d, %d): expected %s, got %v (%v)", test.base, test.exp, test.m, test.expected, got, err)
+		}
+	}
+
+	inverse, err := calc.ModInverse(big.NewInt(-3), big.NewInt(11))
+	if err != nil || inverse.String() != "7" {
+		t.Errorf("ModInverse(-3, 11): expected 7, got %v (%v)", inverse, err)
+	}
+	if _, err := calc.ModInverse(big.NewInt(6), big.NewInt(9)); err == nil || !strings.Contains(err.Error(), "no inverse") {
+		t.Errorf("ModInverse(6, 9): expected a no inverse error, got %v", err)
+	}
+	if _, err := calc.ModPow(big.NewInt(2), big.NewInt(3), big.NewInt(0)); err == nil {
+		t.Error("ModPow with modulus 0: expected an error")
+	}
+	if _, err := calc.ModPow(big.NewInt(6), big.NewInt(-1), big.NewInt(9)); err == nil {
+		t.Error("ModPow(6, -1, 9): expected an error")
+	}
+}
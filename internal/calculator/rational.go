@@ -0,0 +1,88 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+)
+
+// maxRatExponent bounds the exponent accepted by RatPower, since exact powers grow
+// linearly in size with the exponent
+const maxRatExponent = 1 << 16
+
+// ParseRat parses a decimal literal such as 0.1 or 1.5e-3 into an exact fraction
+func (c *Calculator) ParseRat(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errors.New("invalid rational number " + s)
+	}
+	return r, nil
+}
+
+// RatAdd performs exact addition of two fractions
+func (c *Calculator) RatAdd(a, b *big.Rat) *big.Rat {
+	return new(big.Rat).Add(a, b)
+}
+
+// RatSubtract performs exact subtraction of two fractions
+func (c *Calculator) RatSubtract(a, b *big.Rat) *big.Rat {
+	return new(big.Rat).Sub(a, b)
+}
+
+// RatMultiply performs exact multiplication of two fractions
+func (c *Calculator) RatMultiply(a, b *big.Rat) *big.Rat {
+	return new(big.Rat).Mul(a, b)
+}
+
+// RatDivide performs exact division of two fractions with error handling for division by zero
+func (c *Calculator) RatDivide(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return new(big.Rat).Quo(a, b), nil
+}
+
+// RatMod performs exact modulus of two fractions with the sign of the dividend,
+// matching ModFloat, with error handling for modulus by zero
+func (c *Calculator) RatMod(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("modulus by zero")
+	}
+	q := new(big.Rat).Quo(a, b)
+	// Truncate the quotient toward zero
+	trunc := new(big.Int).Quo(q.Num(), q.Denom())
+	product := new(big.Rat).Mul(new(big.Rat).SetInt(trunc), b)
+	return new(big.Rat).Sub(a, product), nil
+}
+
+// RatQuotient performs exact division truncated toward zero, the counterpart of
+// RatMod, with error handling for division by zero
+func (c *Calculator) RatQuotient(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	q := new(big.Rat).Quo(a, b)
+	return new(big.Rat).SetInt(new(big.Int).Quo(q.Num(), q.Denom())), nil
+}
+
+// RatPower raises a fraction to an integer power exactly with error handling for
+// non-integer or very large exponents and for zero raised to a negative power
+func (c *Calculator) RatPower(base, exponent *big.Rat) (*big.Rat, error) {
+	if !exponent.IsInt() {
+		return nil, errors.New("exact power requires an integer exponent")
+	}
+	n := exponent.Num()
+	if n.CmpAbs(big.NewInt(maxRatExponent)) > 0 {
+		return nil, errors.New("exponent too large for exact arithmetic")
+	}
+	if base.Sign() == 0 && n.Sign() < 0 {
+		return nil, errors.New("zero raised to a negative power")
+	}
+
+	abs := new(big.Int).Abs(n)
+	num := new(big.Int).Exp(base.Num(), abs, nil)
+	den := new(big.Int).Exp(base.Denom(), abs, nil)
+	if n.Sign() < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
@@ -0,0 +1,135 @@
+// rational_test.go
+package calculator
+
+import (
+	"math/big"
+	"testing"
+)
+
+// mustParseRat parses a decimal literal into a fraction or fails the test
+func mustParseRat(t *testing.T, calc *Calculator, s string) *big.Rat {
+	t.Helper()
+	r, err := calc.ParseRat(s)
+	if err != nil {
+		t.Fatalf("ParseRat(%q) failed: %v", s, err)
+	}
+	return r
+}
+
+// =============================================================================
+// EXACT RATIONAL ARITHMETIC TESTS
+// These tests verify that fractions are computed exactly and reduced to lowest terms
+// =============================================================================
+
+// TestParseRat verifies decimal and exponent literals parse to exact fractions
+func TestParseRat(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		literal, expected string
+	}{
+		{"0.1", "1/10"},
+		{"2.50", "5/2"},
+		{"1.5e-3", "3/2000"},
+		{"42", "42"},
+	}
+	for _, test := range tests {
+		if got := mustParseRat(t, calc, test.literal).RatString(); got != test.expected {
+			t.Errorf("ParseRat(%q): expected %s, got %s", test.literal, test.expected, got)
+		}
+	}
+	if _, err := calc.ParseRat("abc"); err == nil {
+		t.Error("Expected error parsing abc")
+	}
+}
+
+// TestRatArithmetic verifies 1/3 * 3 is exactly 1 and 0.1 + 0.2 is exactly 3/10
+func TestRatArithmetic(t *testing.T) {
+	calc := New()
+	one := mustParseRat(t, calc, "1")
+	three := mustParseRat(t, calc, "3")
+
+	third, err := calc.RatDivide(one, three)
+	if err != nil || third.RatString() != "1/3" {
+		t.Fatalf("Expected 1/3, got %v (err: %v)", third, err)
+	}
+	if result := calc.RatMultiply(third, three); result.Cmp(one) != 0 {
+		t.Errorf("Expected 1/3 * 3 = 1 exactly, got %s", result.RatString())
+	}
+	if result := calc.RatAdd(mustParseRat(t, calc, "0.1"), mustParseRat(t, calc, "0.2")); result.RatString() != "3/10" {
+		t.Errorf("Expected 0.1 + 0.2 = 3/10, got %s", result.RatString())
+	}
+	if result := calc.RatSubtract(third, one); result.RatString() != "-2/3" {
+		t.Errorf("Expected 1/3 - 1 = -2/3, got %s", result.RatString())
+	}
+	if _, err := calc.RatDivide(one, new(big.Rat)); err == nil {
+		t.Error("Expected division by zero error")
+	}
+}
+
+// TestRatMod verifies the remainder takes the sign of the dividend, matching ModFloat
+func TestRatMod(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		a, b, expected string
+	}{
+		{"7.5", "2", "3/2"},
+		{"-7.5", "2", "-3/2"},
+		{"7.5", "-2", "3/2"},
+		{"1", "0.3", "1/10"},
+	}
+	for _, test := range tests {
+		result, err := calc.RatMod(mustParseRat(t, calc, test.a), mustParseRat(t, calc, test.b))
+		if err != nil || result.RatString() != test.expected {
+			t.Errorf("Expected %s %% %s = %s, got %v (err: %v)", test.a, test.b, test.expected, result, err)
+		}
+	}
+	if _, err := calc.RatMod(mustParseRat(t, calc, "1"), new(big.Rat)); err == nil {
+		t.Error("Expected modulus by zero error")
+	}
+}
+
+// TestRatPower verifies exact integer powers and the errors for other exponents
+func TestRatPower(t *testing.T) {
+	calc := New()
+
+	tests := []struct {
+		base, exponent, expected string
+	}{
+		{"2", "64", "18446744073709551616"},
+		{"-2/3", "3", "-8/27"},
+		{"-2/3", "-3", "-27/8"},
+		{"5", "0", "1"},
+	}
+	for _, test := range tests {
+		base, _ := new(big.Rat).SetString(test.base)
+		result, err := calc.RatPower(base, mustParseRat(t, calc, test.exponent))
+		if err != nil || result.RatString() != test.expected {
+			t.Errorf("Expected %s^%s = %s, got %v (err: %v)", test.base, test.exponent, test.expected, result, err)
+		}
+	}
+
+	if _, err := calc.RatPower(mustParseRat(t, calc, "2"), mustParseRat(t, calc, "0.5")); err == nil {
+		t.Error("Expected error for non-integer exponent")
+	}
+	if _, err := calc.RatPower(new(big.Rat), mustParseRat(t, calc, "-1")); err == nil {
+		t.Error("Expected error for zero raised to a negative power")
+	}
+	if _, err := calc.RatPower(mustParseRat(t, calc, "2"), mustParseRat(t, calc, "1e9")); err == nil {
+		t.Error("Expected error for an exponent too large for exact arithmetic")
+	}
+}
+
+// TestNumberMode verifies the default number mode and the names of the modes
+func TestNumberMode(t *testing.T) {
+	calc := New()
+	if calc.NumberMode() != FloatMode {
+		t.Errorf("Expected default number mode float, got %s", calc.NumberMode())
+	}
+	for mode, name := range map[NumberMode]string{RationalMode: "rational", IntegerMode: "integer", ComplexMode: "complex"} {
+		if mode.String() != name {
+			t.Errorf("Expected %s mode to be named %s, got %s", name, name, mode)
+		}
+	}
+}
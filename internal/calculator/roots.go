@@ -0,0 +1,250 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// RootResult describes a root found numerically and how it was found
+type RootResult struct {
+	Root       float64
+	Residual   float64 // the value of the function at Root
+	Iterations int
+	Method     string
+}
+
+// Func is a real function of one variable for the numerical methods, whose
+// roots, integral or derivative are sought. It may fail, for example outside
+// the domain of a logarithm.
+type Func func(x float64) (float64, error)
+
+const (
+	// rootTolerance is the relative change in x at which iteration stops
+	rootTolerance = 1e-12
+	// maxRootIterations bounds the iterations of Newton's and Brent's methods
+	maxRootIterations = 100
+	// bracketSteps bounds the outward search for a sign change, which grows by
+	// a factor of 1.6 from 1% of the guess to about 10^6 times it
+	bracketSteps = 50
+	// epsilon is the spacing of float64 values near 1
+	epsilon = 2.220446049250313e-16
+)
+
+// ConvergenceError reports that an iterative method stopped without finding
+// an answer to the required tolerance
+type ConvergenceError struct {
+	Method string
+	Reason string
+}
+
+func (e *ConvergenceError) Error() string {
+	return e.Method + " " + e.Reason
+}
+
+// newtonError returns a ConvergenceError for Newton's method
+func newtonError(format string, args ...interface{}) error {
+	return &ConvergenceError{Method: "Newton's method", Reason: fmt.Sprintf(format, args...)}
+}
+
+// Newton finds a root of f near x0 by Newton's method, estimating the
+// derivative by central differences. It fails when the derivative vanishes,
+// the iterate leaves the domain of f or the iteration does not settle within
+// the iteration limit.
+func (c *Calculator) Newton(f Func, x0 float64) (RootResult, error) {
+	x := x0
+	fx, err := f(x)
+	if err != nil {
+		return RootResult{}, err
+	}
+	for i := 1; i <= maxRootIterations; i++ {
+		if fx == 0 {
+			return RootResult{Root: x, Residual: 0, Iterations: i - 1, Method: "Newton"}, nil
+		}
+		h := 1e-6 * math.Max(1, math.Abs(x))
+		above, err1 := f(x + h)
+		below, err2 := f(x - h)
+		if err1 != nil || err2 != nil {
+			return RootResult{}, newtonError("left the domain near x = %g", x)
+		}
+		slope := (above - below) / (2 * h)
+		if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+			return RootResult{}, newtonError("met a zero derivative at x = %g", x)
+		}
+		step := fx / slope
+		x -= step
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return RootResult{}, newtonError("diverged")
+		}
+		if fx, err = f(x); err != nil {
+			return RootResult{}, newtonError("left the domain at x = %g", x)
+		}
+		if math.Abs(step) <= rootTolerance*math.Max(1, math.Abs(x)) {
+			return RootResult{Root: x, Residual: fx, Iterations: i, Method: "Newton"}, nil
+		}
+	}
+	return RootResult{}, newtonError("did not settle in %d iterations (last x = %g, f(x) = %g)", maxRootIterations, x, fx)
+}
+
+// Brent finds a root of f between a and b, where f has opposite signs, by
+// Brent's method, which combines bisection with secant and inverse quadratic
+// interpolation and always converges
+func (c *Calculator) Brent(f Func, a, b float64) (RootResult, error) {
+	fa, err := f(a)
+	if err != nil {
+		return RootResult{}, err
+	}
+	fb, err := f(b)
+	if err != nil {
+		return RootResult{}, err
+	}
+	switch {
+	case fa == 0:
+		return RootResult{Root: a, Iterations: 0, Method: "Brent"}, nil
+	case fb == 0:
+		return RootResult{Root: b, Iterations: 0, Method: "Brent"}, nil
+	case math.Signbit(fa) == math.Signbit(fb):
+		return RootResult{}, fmt.Errorf("f(%g) = %g and f(%g) = %g have the same sign", a, fa, b, fb)
+	}
+
+	cc, fc := a, fa
+	d := b - a
+	e := d
+	for i := 1; i <= maxRootIterations; i++ {
+		if math.Signbit(fb) == math.Signbit(fc) {
+			cc, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, cc = b, cc, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol := 2 * epsilon * math.Max(1, math.Abs(b))
+		m := (cc - b) / 2
+		if math.Abs(m) <= tol || fb == 0 {
+			return RootResult{Root: b, Residual: fb, Iterations: i, Method: "Brent"}, nil
+		}
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			// Try interpolation, accepting it only while it shrinks the bracket quickly
+			var p, q float64
+			s := fb / fa
+			if a == cc {
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d, e = m, m
+			}
+		} else {
+			d, e = m, m
+		}
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else {
+			b += math.Copysign(tol, m)
+		}
+		if fb, err = f(b); err != nil {
+			return RootResult{}, err
+		}
+	}
+	return RootResult{}, &ConvergenceError{Method: "Brent's method", Reason: fmt.Sprintf("did not converge in %d iterations", maxRootIterations)}
+}
+
+// Bracket searches outward from x0 in growing steps for a point where f has
+// the opposite sign to f(x0), skipping points where f is undefined, and
+// returns the interval between them
+func (c *Calculator) Bracket(f Func, x0 float64) (float64, float64, error) {
+	f0, err := f(x0)
+	if err != nil {
+		return 0, 0, err
+	}
+	if f0 == 0 {
+		return x0, x0, nil
+	}
+	step := 0.01 * math.Max(1, math.Abs(x0))
+	for i := 0; i < bracketSteps; i++ {
+		for _, x := range []float64{x0 + step, x0 - step} {
+			if fx, err := f(x); err == nil && math.Signbit(fx) != math.Signbit(f0) {
+				return math.Min(x0, x), math.Max(x0, x), nil
+			}
+		}
+		step *= 1.6
+	}
+	return 0, 0, fmt.Errorf("no sign change within %.3g of %g", step/1.6, x0)
+}
+
+// FindRoot finds a root of f near x0, first by Newton's method and, when that
+// fails, by bracketing a sign change around x0 and applying Brent's method
+func (c *Calculator) FindRoot(f Func, x0 float64) (RootResult, error) {
+	result, err := c.Newton(f, x0)
+	var convergence *ConvergenceError
+	if err == nil || !errors.As(err, &convergence) {
+		return result, err
+	}
+	a, b, bracketErr := c.Bracket(f, x0)
+	if bracketErr != nil {
+		return RootResult{}, fmt.Errorf("no root found near %g: %v, and there is %v", x0, err, bracketErr)
+	}
+	result, err = c.Brent(f, a, b)
+	if err != nil {
+		return RootResult{}, fmt.Errorf("no root found near %g: %v", x0, err)
+	}
+	return result, nil
+}
+
+// FindRoots finds the roots of f between a and b by looking for sign changes
+// over n equal subintervals and refining each with Brent's method. Roots where
+// f touches zero without changing sign are found only at the subdivision points.
+func (c *Calculator) FindRoots(f Func, a, b float64, n int) ([]RootResult, error) {
+	if !(a < b) {
+		return nil, fmt.Errorf("invalid interval [%g, %g]", a, b)
+	}
+	var roots []RootResult
+	add := func(r RootResult) {
+		if len(roots) > 0 && math.Abs(r.Root-roots[len(roots)-1].Root) <= rootTolerance*math.Max(1, math.Abs(r.Root)) {
+			return
+		}
+		roots = append(roots, r)
+	}
+
+	x0 := a
+	f0, err0 := f(x0)
+	if err0 == nil && f0 == 0 {
+		add(RootResult{Root: x0, Method: "Brent"})
+	}
+	for i := 1; i <= n; i++ {
+		x1 := a + (b-a)*float64(i)/float64(n)
+		f1, err1 := f(x1)
+		switch {
+		case err1 != nil:
+		case f1 == 0:
+			add(RootResult{Root: x1, Method: "Brent"})
+		case err0 == nil && f0 != 0 && math.Signbit(f0) != math.Signbit(f1):
+			r, err := c.Brent(f, x0, x1)
+			if err != nil {
+				return nil, err
+			}
+			add(r)
+		}
+		x0, f0, err0 = x1, f1, err1
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no root found in [%g, %g]: f does not change sign over %d subintervals", a, b, n)
+	}
+	return roots, nil
+}
@@ -0,0 +1,99 @@
+// roots_test.go
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// ROOT FINDING TESTS
+// These tests verify Newton's and Brent's methods, the fallback from one to the
+// other and the search for every root in an interval, including failures
+// =============================================================================
+
+// TestNewton verifies quadratic convergence on a smooth function and the
+// errors for a vanishing derivative and a function without roots
+func TestNewton(t *testing.T) {
+	calc := New()
+	square := func(x float64) (float64, error) { return x*x - 2, nil }
+
+	r, err := calc.Newton(square, 1)
+	if err != nil || !floatEquals(r.Root, math.Sqrt2, 1e-12) || r.Method != "Newton" || r.Iterations > 10 {
+		t.Errorf("Expected sqrt(2) by Newton, got %+v (err: %v)", r, err)
+	}
+	var convergence *ConvergenceError
+	if _, err := calc.Newton(square, 0); !errors.As(err, &convergence) {
+		t.Errorf("Expected a convergence error at a zero derivative, got %v", err)
+	}
+	positive := func(x float64) (float64, error) { return x*x + 1, nil }
+	if _, err := calc.Newton(positive, 3); !errors.As(err, &convergence) {
+		t.Errorf("Expected a convergence error without a root, got %v", err)
+	}
+}
+
+// TestBrent verifies bracketed root finding and the error for an interval
+// without a sign change
+func TestBrent(t *testing.T) {
+	calc := New()
+	cubic := func(x float64) (float64, error) { return x*x*x - x - 2, nil }
+
+	r, err := calc.Brent(cubic, 1, 2)
+	if err != nil || !floatEquals(r.Root, 1.5213797068045676, 1e-12) || r.Method != "Brent" {
+		t.Errorf("Expected 1.52137970680457 by Brent, got %+v (err: %v)", r, err)
+	}
+	if _, err := calc.Brent(cubic, 2, 3); err == nil {
+		t.Error("Expected error for an interval without a sign change")
+	}
+	// A discontinuous step is still bracketed down to the jump
+	step := func(x float64) (float64, error) { return math.Copysign(1, x-0.3), nil }
+	if r, err := calc.Brent(step, 0, 1); err != nil || !floatEquals(r.Root, 0.3, 1e-11) {
+		t.Errorf("Expected the jump at 0.3, got %+v (err: %v)", r, err)
+	}
+}
+
+// TestFindRoot verifies the fallback to bracketing when Newton's method fails
+func TestFindRoot(t *testing.T) {
+	calc := New()
+	// Newton's method starting at 0 meets a zero derivative
+	square := func(x float64) (float64, error) { return x*x - 2, nil }
+	if r, err := calc.FindRoot(square, 0); err != nil || !floatEquals(math.Abs(r.Root), math.Sqrt2, 1e-12) || r.Method != "Brent" {
+		t.Errorf("Expected ±sqrt(2) by Brent, got %+v (err: %v)", r, err)
+	}
+	// The cube root overshoots ever further under Newton's method
+	cbrt := func(x float64) (float64, error) { return math.Cbrt(x - 1), nil }
+	if r, err := calc.FindRoot(cbrt, 3); err != nil || !floatEquals(r.Root, 1, 1e-9) {
+		t.Errorf("Expected 1 for the shifted cube root, got %+v (err: %v)", r, err)
+	}
+	positive := func(x float64) (float64, error) { return x*x + 1, nil }
+	if _, err := calc.FindRoot(positive, 0); err == nil {
+		t.Error("Expected error for a function without roots")
+	}
+	failing := func(x float64) (float64, error) { return 0, errors.New("undefined") }
+	if _, err := calc.FindRoot(failing, 0); err == nil || err.Error() != "undefined" {
+		t.Errorf("Expected the function's own error, got %v", err)
+	}
+}
+
+// TestFindRoots verifies that every sign change in an interval is found
+func TestFindRoots(t *testing.T) {
+	calc := New()
+	sine := func(x float64) (float64, error) { return math.Sin(x), nil }
+
+	roots, err := calc.FindRoots(sine, -1, 10, 100)
+	if err != nil || len(roots) != 4 {
+		t.Fatalf("Expected 4 roots of sin in [-1, 10], got %+v (err: %v)", roots, err)
+	}
+	for i, r := range roots {
+		if !floatEquals(r.Root, float64(i)*math.Pi, 1e-12) {
+			t.Errorf("Expected root %d = %g, got %g", i, float64(i)*math.Pi, r.Root)
+		}
+	}
+	if _, err := calc.FindRoots(sine, 0.5, 3, 100); err == nil {
+		t.Error("Expected error for an interval without roots")
+	}
+	if _, err := calc.FindRoots(sine, 3, 1, 100); err == nil {
+		t.Error("Expected error for an empty interval")
+	}
+}
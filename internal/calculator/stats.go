@@ -0,0 +1,142 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sum returns the sum of values, using compensated summation so that long
+// lists of samples do not accumulate rounding error
+func (c *Calculator) Sum(values ...float64) float64 {
+	var sum, compensation float64
+	for _, v := range values {
+		t := sum + v
+		// Neumaier's variant also handles a term larger than the running sum
+		if math.Abs(sum) >= math.Abs(v) {
+			compensation += (sum - t) + v
+		} else {
+			compensation += (v - t) + sum
+		}
+		sum = t
+	}
+	// Once the sum overflows, the compensation is Inf - Inf, which is NaN
+	if math.IsInf(sum, 0) || math.IsNaN(sum) {
+		return sum
+	}
+	return sum + compensation
+}
+
+// Mean returns the arithmetic mean of values with error handling for an empty list
+func (c *Calculator) Mean(values ...float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("mean requires at least one value")
+	}
+	return c.Sum(values...) / float64(len(values)), nil
+}
+
+// Median returns the middle value of values, or the mean of the two middle
+// values when there is an even number, with error handling for an empty list
+func (c *Calculator) Median(values ...float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("median requires at least one value")
+	}
+	sorted := sortedCopy(values)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid], nil
+	}
+	return sorted[mid-1] + (sorted[mid]-sorted[mid-1])/2, nil
+}
+
+// Mode returns the most frequent of values, the smallest of them when several
+// are equally frequent, with error handling for an empty list
+func (c *Calculator) Mode(values ...float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("mode requires at least one value")
+	}
+	sorted := sortedCopy(values)
+	mode, best := sorted[0], 0
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j] == sorted[i] {
+			j++
+		}
+		if j-i > best {
+			mode, best = sorted[i], j-i
+		}
+		i = j
+	}
+	return mode, nil
+}
+
+// PopulationVariance returns the population variance of values, the mean
+// squared deviation from the mean, with error handling for an empty list
+func (c *Calculator) PopulationVariance(values ...float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("variance requires at least one value")
+	}
+	return c.sumSquaredDeviations(values) / float64(len(values)), nil
+}
+
+// SampleVariance returns the sample variance of values, which divides by n-1
+// to estimate the variance of the population they were drawn from, with error
+// handling for fewer than two values
+func (c *Calculator) SampleVariance(values ...float64) (float64, error) {
+	if len(values) < 2 {
+		return 0.0, errors.New("sample variance requires at least two values")
+	}
+	return c.sumSquaredDeviations(values) / float64(len(values)-1), nil
+}
+
+// PopulationStdDev returns the population standard deviation of values
+func (c *Calculator) PopulationStdDev(values ...float64) (float64, error) {
+	v, err := c.PopulationVariance(values...)
+	return math.Sqrt(v), err
+}
+
+// SampleStdDev returns the sample standard deviation of values
+func (c *Calculator) SampleStdDev(values ...float64) (float64, error) {
+	v, err := c.SampleVariance(values...)
+	return math.Sqrt(v), err
+}
+
+// Percentile returns the p-th percentile of values for p between 0 and 100,
+// interpolating linearly between the closest ranks as spreadsheets' PERCENTILE
+// does, with error handling for an empty list and p out of range
+func (c *Calculator) Percentile(values []float64, p float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, errors.New("percentile requires at least one value")
+	}
+	if p < 0 || p > 100 || math.IsNaN(p) {
+		return 0.0, fmt.Errorf("percentile %g out of range [0, 100]", p)
+	}
+	sorted := sortedCopy(values)
+	// Keep the rank scaled by 100 so that whole percentiles split it exactly
+	scaled := p * float64(len(sorted)-1)
+	lower := int(math.Floor(scaled / 100))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1], nil
+	}
+	frac := (scaled - float64(lower)*100) / 100
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower]), nil
+}
+
+// sumSquaredDeviations returns the sum of the squared differences between
+// values and their mean, computed in two passes for accuracy
+func (c *Calculator) sumSquaredDeviations(values []float64) float64 {
+	mean := c.Sum(values...) / float64(len(values))
+	squares := make([]float64, len(values))
+	for i, v := range values {
+		squares[i] = (v - mean) * (v - mean)
+	}
+	return c.Sum(squares...)
+}
+
+// sortedCopy returns values in ascending order without modifying them
+func sortedCopy(values []float64) []float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted
+}
@@ -0,0 +1,129 @@
+// stats_test.go
+package calculator
+
+import (
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// STATISTICS TESTS
+// These tests verify the aggregate functions over lists of values, including
+// their errors on empty input
+// =============================================================================
+
+// TestSumMean verifies sums, including compensation for rounding error, and means
+func TestSumMean(t *testing.T) {
+	calc := New()
+
+	if result := calc.Sum(1, 2, 3.5); result != 6.5 {
+		t.Errorf("Expected sum = 6.5, got %f", result)
+	}
+	if result := calc.Sum(1, 1e100, 1, -1e100); result != 2 {
+		t.Errorf("Expected compensated sum = 2, got %g", result)
+	}
+	// An overflow stays infinite rather than turning into NaN
+	if result := calc.Sum(1e308, 1e308); !math.IsInf(result, 1) {
+		t.Errorf("Expected overflowing sum = +Inf, got %g", result)
+	}
+	if result, err := calc.Mean(1e308, 1e308); err != nil || !math.IsInf(result, 1) {
+		t.Errorf("Expected overflowing mean = +Inf, got %g (err: %v)", result, err)
+	}
+	if result := calc.Sum(); result != 0 {
+		t.Errorf("Expected empty sum = 0, got %f", result)
+	}
+	if result, err := calc.Mean(2, 4, 9); err != nil || result != 5 {
+		t.Errorf("Expected mean = 5, got %f (err: %v)", result, err)
+	}
+	if _, err := calc.Mean(); err == nil {
+		t.Error("Expected error for mean of no values")
+	}
+}
+
+// TestMedianMode verifies medians of odd and even lists and modes with ties
+func TestMedianMode(t *testing.T) {
+	calc := New()
+
+	if result, err := calc.Median(5, 1, 3); err != nil || result != 3 {
+		t.Errorf("Expected median = 3, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Median(4, 1, 3, 2); err != nil || result != 2.5 {
+		t.Errorf("Expected median = 2.5, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Mode(3, 1, 3, 2, 2); err != nil || result != 2 {
+		t.Errorf("Expected mode = 2, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.Mode(7, 1, 7); err != nil || result != 7 {
+		t.Errorf("Expected mode = 7, got %f (err: %v)", result, err)
+	}
+	if _, err := calc.Median(); err == nil {
+		t.Error("Expected error for median of no values")
+	}
+	if _, err := calc.Mode(); err == nil {
+		t.Error("Expected error for mode of no values")
+	}
+}
+
+// TestVariance verifies population and sample variance and standard deviation
+func TestVariance(t *testing.T) {
+	calc := New()
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+
+	if result, err := calc.PopulationVariance(values...); err != nil || result != 4 {
+		t.Errorf("Expected population variance = 4, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.PopulationStdDev(values...); err != nil || result != 2 {
+		t.Errorf("Expected population stddev = 2, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.SampleVariance(values...); err != nil || !floatEquals(result, 32.0/7, 1e-12) {
+		t.Errorf("Expected sample variance = 32/7, got %f (err: %v)", result, err)
+	}
+	if result, err := calc.SampleStdDev(values...); err != nil || !floatEquals(result, math.Sqrt(32.0/7), 1e-12) {
+		t.Errorf("Expected sample stddev = sqrt(32/7), got %f (err: %v)", result, err)
+	}
+	// A large offset must not swamp the small spread
+	if result, err := calc.PopulationVariance(1e9+1, 1e9+2, 1e9+3); err != nil || !floatEquals(result, 2.0/3, 1e-9) {
+		t.Errorf("Expected variance = 2/3, got %f (err: %v)", result, err)
+	}
+	if _, err := calc.PopulationVariance(); err == nil {
+		t.Error("Expected error for variance of no values")
+	}
+	if _, err := calc.SampleVariance(1); err == nil {
+		t.Error("Expected error for sample variance of one value")
+	}
+}
+
+// TestPercentile verifies linear interpolation between ranks and the range of p
+func TestPercentile(t *testing.T) {
+	calc := New()
+	values := []float64{15, 20, 35, 40, 50}
+
+	tests := []struct {
+		p        float64
+		expected float64
+	}{
+		{0, 15},
+		{25, 20},
+		{40, 29},
+		{50, 35},
+		{100, 50},
+	}
+	for _, test := range tests {
+		if result, err := calc.Percentile(values, test.p); err != nil || !floatEquals(result, test.expected, 1e-12) {
+			t.Errorf("Expected percentile %g = %g, got %f (err: %v)", test.p, test.expected, result, err)
+		}
+	}
+	// Interpolation must not leave rounding noise in round percentiles
+	if result, err := calc.Percentile([]float64{120, 95, 310, 101}, 95); err != nil || result != 281.5 {
+		t.Errorf("Expected percentile 95 = 281.5, got %v (err: %v)", result, err)
+	}
+	if result, err := calc.Percentile([]float64{42}, 95); err != nil || result != 42 {
+		t.Errorf("Expected percentile of one value = 42, got %f (err: %v)", result, err)
+	}
+	if _, err := calc.Percentile(values, 101); err == nil {
+		t.Error("Expected error for percentile above 100")
+	}
+	if _, err := calc.Percentile(nil, 50); err == nil {
+		t.Error("Expected error for percentile of no values")
+	}
+}
@@ -0,0 +1,76 @@
+package currency
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxRatesSize bounds the size of a rates document
+const maxRatesSize = 1 << 20
+
+// Loader obtains exchange rates from some source
+type Loader interface {
+	Load() (*Rates, error)
+}
+
+// FileLoader reads rates from a local JSON or CSV file
+type FileLoader struct {
+	Path string
+}
+
+// Load reads and decodes the rates file
+func (l FileLoader) Load() (*Rates, error) {
+	data, err := os.ReadFile(l.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rates: %w", err)
+	}
+	r, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", l.Path, err)
+	}
+	return r, nil
+}
+
+// HTTPLoader fetches a JSON or CSV rates document over HTTP
+type HTTPLoader struct {
+	URL    string
+	Client *http.Client // nil uses a client with a 10 second timeout
+}
+
+// Load downloads and decodes the rates document
+func (l HTTPLoader) Load() (*Rates, error) {
+	client := l.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Get(l.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching rates: %s returned %s", l.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRatesSize))
+	if err != nil {
+		return nil, fmt.Errorf("fetching rates: %w", err)
+	}
+	r, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", l.URL, err)
+	}
+	return r, nil
+}
+
+// NewLoader returns an HTTPLoader for http and https URLs and a FileLoader for
+// anything else
+func NewLoader(source string) Loader {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return HTTPLoader{URL: source}
+	}
+	return FileLoader{Path: source}
+}
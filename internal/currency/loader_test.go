@@ -0,0 +1,60 @@
+package currency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	if err := os.WriteFile(path, []byte("2026-10-01,EUR,USD,1.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if r.Base != "EUR" || r.Rates["USD"] != 1.1 {
+		t.Errorf("Unexpected rates %+v", r)
+	}
+
+	if _, err := (FileLoader{Path: filepath.Join(t.TempDir(), "missing.json")}).Load(); err == nil {
+		t.Error("Expected error loading a missing file")
+	}
+}
+
+func TestHTTPLoader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rates.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"base": "USD", "date": "2026-10-01", "rates": {"EUR": 0.8}}`))
+		case "/bad.json":
+			w.Write([]byte(`{"base": "USD"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	loader := NewLoader(server.URL + "/rates.json")
+	if _, ok := loader.(HTTPLoader); !ok {
+		t.Fatalf("Expected an HTTPLoader for a URL, got %T", loader)
+	}
+	r, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if r.Base != "USD" || r.Rates["EUR"] != 0.8 {
+		t.Errorf("Unexpected rates %+v", r)
+	}
+
+	for _, path := range []string{"/bad.json", "/missing.json"} {
+		if _, err := (HTTPLoader{URL: server.URL + path, Client: server.Client()}).Load(); err == nil {
+			t.Errorf("Expected error loading %s", path)
+		}
+	}
+}
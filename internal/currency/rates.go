@@ -0,0 +1,175 @@
+// Package currency loads foreign exchange rates from a local JSON or CSV file,
+// or from any other source implementing Loader, so that currency conversions
+// work offline.
+package currency
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of the as-of date in rates files
+const DateLayout = "2006-01-02"
+
+// Rates is a set of exchange rates against a base currency, valid as of a date
+type Rates struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]float64 // units of each currency per unit of Base
+}
+
+// Values returns the value of one unit of every currency, the base included,
+// expressed in the base currency
+func (r *Rates) Values() map[string]float64 {
+	values := make(map[string]float64, len(r.Rates)+1)
+	for code, rate := range r.Rates {
+		values[code] = 1 / rate
+	}
+	values[r.Base] = 1
+	return values
+}
+
+// Age returns how long before now the rates were published
+func (r *Rates) Age(now time.Time) time.Duration {
+	return now.Sub(r.Date)
+}
+
+// Stale reports whether the rates are older than maxAge; a maxAge of zero or
+// less never considers rates stale
+func (r *Rates) Stale(now time.Time, maxAge time.Duration) bool {
+	return maxAge > 0 && r.Age(now) > maxAge
+}
+
+// jsonRates is the JSON rates file format:
+//
+//	{"base": "USD", "date": "2026-10-01", "rates": {"EUR": 0.92, "GBP": 0.79}}
+type jsonRates struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Decode parses a rates file in JSON or CSV format, telling them apart by the
+// leading brace of JSON. CSV files have one rate per record with the fields
+// date, base, currency and rate, an optional header row and # comments:
+//
+//	date,base,currency,rate
+//	2026-10-01,USD,EUR,0.92
+func Decode(data []byte) (*Rates, error) {
+	var r *Rates
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		r, err = decodeJSON(data)
+	} else {
+		r, err = decodeCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func decodeJSON(data []byte) (*Rates, error) {
+	var j jsonRates
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("invalid rates JSON: %w", err)
+	}
+	date, err := parseDate(j.Date)
+	if err != nil {
+		return nil, err
+	}
+	return &Rates{Base: j.Base, Date: date, Rates: j.Rates}, nil
+}
+
+func decodeCSV(data []byte) (*Rates, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates CSV: %w", err)
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "date") {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("rates file has no rates")
+	}
+
+	r := &Rates{Base: records[0][1], Rates: make(map[string]float64, len(records))}
+	for i, rec := range records {
+		date, err := parseDate(rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		if i == 0 {
+			r.Date = date
+		}
+		if !date.Equal(r.Date) || rec[1] != r.Base {
+			return nil, fmt.Errorf("record %d: every rate must share the date %s and base %s", i+1, r.Date.Format(DateLayout), r.Base)
+		}
+		rate, err := strconv.ParseFloat(rec[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: invalid rate %q", i+1, rec[3])
+		}
+		r.Rates[rec[2]] = rate
+	}
+	return r, nil
+}
+
+// parseDate parses an as-of date, which is required
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("rates file has no date")
+	}
+	date, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid rates date %q, expected YYYY-MM-DD", s)
+	}
+	return date, nil
+}
+
+// validate checks the currency codes and that every rate is a positive number
+func (r *Rates) validate() error {
+	if !isCode(r.Base) {
+		return fmt.Errorf("invalid base currency %q", r.Base)
+	}
+	if len(r.Rates) == 0 {
+		return fmt.Errorf("rates file has no rates")
+	}
+	for code, rate := range r.Rates {
+		if !isCode(code) {
+			return fmt.Errorf("invalid currency code %q", code)
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return fmt.Errorf("invalid rate %v for %s", rate, code)
+		}
+	}
+	if rate, ok := r.Rates[r.Base]; ok && rate != 1 {
+		return fmt.Errorf("base currency %s has rate %v, expected 1", r.Base, rate)
+	}
+	return nil
+}
+
+// isCode reports whether s looks like an ISO 4217 code: three capital letters
+func isCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
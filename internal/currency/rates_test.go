@@ -0,0 +1,69 @@
+package currency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	r, err := Decode([]byte(`{"base": "USD", "date": "2026-10-01", "rates": {"EUR": 0.8, "GBP": 0.5}}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if r.Base != "USD" || r.Date.Format(DateLayout) != "2026-10-01" || len(r.Rates) != 2 {
+		t.Errorf("Unexpected rates %+v", r)
+	}
+	values := r.Values()
+	if values["USD"] != 1 || values["EUR"] != 1.25 || values["GBP"] != 2 {
+		t.Errorf("Unexpected values %v", values)
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	data := "# rates published daily\ndate,base,currency,rate\n2026-10-01,USD,EUR,0.8\n2026-10-01, USD, JPY, 150\n"
+	r, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if r.Base != "USD" || r.Date.Format(DateLayout) != "2026-10-01" || r.Rates["EUR"] != 0.8 || r.Rates["JPY"] != 150 {
+		t.Errorf("Unexpected rates %+v", r)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`{"base": "USD", "rates": {"EUR": 0.8}}`,
+		`{"base": "USD", "date": "01/10/2026", "rates": {"EUR": 0.8}}`,
+		`{"base": "usd", "date": "2026-10-01", "rates": {"EUR": 0.8}}`,
+		`{"base": "USD", "date": "2026-10-01", "rates": {}}`,
+		`{"base": "USD", "date": "2026-10-01", "rates": {"EURO": 0.8}}`,
+		`{"base": "USD", "date": "2026-10-01", "rates": {"EUR": -1}}`,
+		`{"base": "USD", "date": "2026-10-01", "rates": {"USD": 2}}`,
+		`{"base": "USD"`,
+		"date,base,currency,rate\n",
+		"2026-10-01,USD,EUR\n",
+		"2026-10-01,USD,EUR,abc\n",
+		"2026-10-01,USD,EUR,0.8\n2026-10-02,USD,GBP,0.5\n",
+		"2026-10-01,USD,EUR,0.8\n2026-10-01,EUR,GBP,0.5\n",
+	}
+	for _, data := range tests {
+		if _, err := Decode([]byte(data)); err == nil {
+			t.Errorf("Expected error decoding %q", data)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	r := &Rates{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	if age := r.Age(now); age != 15*24*time.Hour {
+		t.Errorf("Expected an age of 15 days, got %v", age)
+	}
+	if !r.Stale(now, 7*24*time.Hour) {
+		t.Error("Expected rates older than a week to be stale")
+	}
+	if r.Stale(now, 30*24*time.Hour) || r.Stale(now, 0) {
+		t.Error("Expected rates within the maximum age, or without one, not to be stale")
+	}
+}
@@ -0,0 +1,234 @@
+// Package expr implements tokenizing, parsing and evaluation of calculator expressions.
+// Expressions are parsed into an abstract syntax tree which is evaluated using the
+// operations provided by the calculator package.
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is an element of a parsed expression tree
+type Node interface {
+	// Pos returns the byte offset of the node within the source expression
+	Pos() int
+	// String renders the node back into fully parenthesized expression syntax
+	String() string
+}
+
+// Number is a numeric literal
+type Number struct {
+	Value  float64
+	Text   string // Source text, used to parse the literal exactly in precision mode
+	Imag   bool   // an imaginary literal such as 4i, whose Value is the imaginary part
+	Offset int
+}
+
+// Measure is a number followed by a unit of measure, such as 5 km or 9.81 m/s^2
+type Measure struct {
+	X      Node
+	Unit   string
+	Joined bool // the unit is written against the number, as in 15m
+	Offset int
+}
+
+// Convert converts a quantity to another unit, written x to unit or x in unit
+type Convert struct {
+	X      Node
+	Unit   string
+	Offset int
+}
+
+// Percentage is a number of hundredths, written with a percent sign as in 10%
+type Percentage struct {
+	X      Node
+	Offset int
+}
+
+// Factorial is the factorial of an operand, written with an exclamation mark as
+// in 5!
+type Factorial struct {
+	X      Node
+	Offset int
+}
+
+// AsPercent expresses a ratio as a percentage, written x as % of y, or x as %
+// for a fraction on its own, in which case Of is nil
+type AsPercent struct {
+	X      Node
+	Of     Node
+	Offset int
+}
+
+// InZone shows a date and time in another time zone, written t in Asia/Tokyo
+type InZone struct {
+	X      Node
+	Zone   string
+	Offset int
+}
+
+// DateLit is a date, a time of day or both, such as 2026-10-16, 14:30 or
+// 2026-10-16 14:30, interpreted in the session's time zone
+type DateLit struct {
+	Text   string
+	Offset int
+}
+
+// DurationLit is a Go-style duration such as 1h30m or 2d12h
+type DurationLit struct {
+	Text   string
+	Offset int
+}
+
+// ListLit is a list of values written in brackets, such as [1, 2, 3.5]
+type ListLit struct {
+	Elems  []Node
+	Offset int
+}
+
+// Ident is a reference to a named value
+type Ident struct {
+	Name   string
+	Offset int
+}
+
+// HistoryRef recalls an earlier result by its 1-based index, written $N
+type HistoryRef struct {
+	Index  int
+	Offset int
+}
+
+// Unary is a prefix operation such as -x
+type Unary struct {
+	Op     string
+	X      Node
+	Offset int
+}
+
+// Binary is an infix operation such as x + y
+type Binary struct {
+	Op     string
+	X, Y   Node
+	Offset int
+}
+
+// Call is a function application such as sqrt(x)
+type Call struct {
+	Name   string
+	Args   []Node
+	Offset int
+}
+
+// Assign binds the value of an expression to a variable name
+type Assign struct {
+	Name   string
+	Value  Node
+	Offset int
+}
+
+// FuncDef defines a user function, written name(params) = body
+type FuncDef struct {
+	Func   *Function
+	Offset int
+}
+
+func (n *Number) Pos() int      { return n.Offset }
+func (n *Measure) Pos() int     { return n.Offset }
+func (n *Convert) Pos() int     { return n.Offset }
+func (n *InZone) Pos() int      { return n.Offset }
+func (n *Percentage) Pos() int  { return n.Offset }
+func (n *Factorial) Pos() int   { return n.Offset }
+func (n *AsPercent) Pos() int   { return n.Offset }
+func (n *DateLit) Pos() int     { return n.Offset }
+func (n *DurationLit) Pos() int { return n.Offset }
+func (n *ListLit) Pos() int     { return n.Offset }
+func (n *Ident) Pos() int       { return n.Offset }
+func (n *HistoryRef) Pos() int  { return n.Offset }
+func (n *Unary) Pos() int       { return n.Offset }
+func (n *Binary) Pos() int      { return n.Offset }
+func (n *Call) Pos() int        { return n.Offset }
+func (n *Assign) Pos() int      { return n.Offset }
+func (n *FuncDef) Pos() int     { return n.Offset }
+
+func (n *Number) String() string {
+	if n.Imag {
+		return strconv.FormatFloat(n.Value, 'g', -1, 64) + "i"
+	}
+	return strconv.FormatFloat(n.Value, 'g', -1, 64)
+}
+
+func (n *Measure) String() string {
+	return "(" + n.X.String() + " " + n.Unit + ")"
+}
+
+func (n *Convert) String() string {
+	return "(" + n.X.String() + " to " + n.Unit + ")"
+}
+
+func (n *Percentage) String() string {
+	return "(" + n.X.String() + "%)"
+}
+
+func (n *Factorial) String() string {
+	return "(" + n.X.String() + "!)"
+}
+
+func (n *AsPercent) String() string {
+	if n.Of == nil {
+		return "(" + n.X.String() + " as %)"
+	}
+	return "(" + n.X.String() + " as % of " + n.Of.String() + ")"
+}
+
+func (n *InZone) String() string {
+	return "(" + n.X.String() + " in " + n.Zone + ")"
+}
+
+func (n *DateLit) String() string {
+	return n.Text
+}
+
+func (n *DurationLit) String() string {
+	return n.Text
+}
+
+func (n *ListLit) String() string {
+	return "[" + joinNodes(n.Elems) + "]"
+}
+
+func (n *Ident) String() string {
+	return n.Name
+}
+
+func (n *HistoryRef) String() string {
+	return "$" + strconv.Itoa(n.Index)
+}
+
+func (n *Unary) String() string {
+	return "(" + n.Op + n.X.String() + ")"
+}
+
+func (n *Binary) String() string {
+	return "(" + n.X.String() + " " + n.Op + " " + n.Y.String() + ")"
+}
+
+func (n *Call) String() string {
+	return n.Name + "(" + joinNodes(n.Args) + ")"
+}
+
+// joinNodes renders a comma separated list of nodes
+func joinNodes(nodes []Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (n *Assign) String() string {
+	return n.Name + " = " + n.Value.String()
+}
+
+func (n *FuncDef) String() string {
+	return n.Func.String()
+}
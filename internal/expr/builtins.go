@@ -0,0 +1,208 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// variadic marks a builtin that accepts any number of arguments above its minimum
+const variadic = -1
+
+// builtin describes a function callable from expressions
+type builtin struct {
+	minArgs int
+	maxArgs int // variadic for no upper bound
+	fn      func(calc *calculator.Calculator, args []float64) (float64, error)
+	// big optionally implements the function in arbitrary precision; builtins
+	// without it are evaluated in float64 even in precision mode
+	big func(calc *calculator.Calculator, args []*big.Float) (*big.Float, error)
+	// cplx optionally implements the function for complex arguments; builtins
+	// without it only accept real arguments in complex mode
+	cplx func(calc *calculator.Calculator, args []complex128) (complex128, error)
+	// spread expands list arguments into their elements, so that aggregates
+	// accept sum([1, 2, 3]) as well as sum(1, 2, 3)
+	spread bool
+}
+
+// withBig attaches a one-argument arbitrary precision implementation
+func (b builtin) withBig(method func(*calculator.Calculator, *big.Float) (*big.Float, error)) builtin {
+	b.big = func(calc *calculator.Calculator, args []*big.Float) (*big.Float, error) {
+		return method(calc, args[0])
+	}
+	return b
+}
+
+// withComplex attaches a one-argument complex implementation
+func (b builtin) withComplex(method func(*calculator.Calculator, complex128) complex128) builtin {
+	b.cplx = func(calc *calculator.Calculator, args []complex128) (complex128, error) {
+		return method(calc, args[0]), nil
+	}
+	return b
+}
+
+// withComplexErr attaches a one-argument complex implementation with a restricted domain
+func (b builtin) withComplexErr(method func(*calculator.Calculator, complex128) (complex128, error)) builtin {
+	b.cplx = func(calc *calculator.Calculator, args []complex128) (complex128, error) {
+		return method(calc, args[0])
+	}
+	return b
+}
+
+// checkArity verifies that a call to name passes an acceptable number of arguments
+func (b builtin) checkArity(name string, n int) error {
+	switch {
+	case b.maxArgs == variadic && n < b.minArgs:
+		return fmt.Errorf("%s expects at least %d argument(s), got %d", name, b.minArgs, n)
+	case b.maxArgs != variadic && (n < b.minArgs || n > b.maxArgs):
+		if b.minArgs == b.maxArgs {
+			return fmt.Errorf("%s expects %d argument(s), got %d", name, b.minArgs, n)
+		}
+		return fmt.Errorf("%s expects %d to %d arguments, got %d", name, b.minArgs, b.maxArgs, n)
+	}
+	return nil
+}
+
+// aggregate adapts a Calculator method over any number of values, whose
+// arguments may be lists
+func aggregate(method func(*calculator.Calculator, ...float64) (float64, error)) builtin {
+	return builtin{minArgs: 1, maxArgs: variadic, spread: true, fn: func(calc *calculator.Calculator, args []float64) (float64, error) {
+		return method(calc, args...)
+	}}
+}
+
+// unary adapts a total one-argument Calculator method
+func unary(method func(*calculator.Calculator, float64) float64) builtin {
+	return builtin{minArgs: 1, maxArgs: 1, fn: func(calc *calculator.Calculator, args []float64) (float64, error) {
+		return method(calc, args[0]), nil
+	}}
+}
+
+// unaryErr adapts a one-argument Calculator method with a restricted domain
+func unaryErr(method func(*calculator.Calculator, float64) (float64, error)) builtin {
+	return builtin{minArgs: 1, maxArgs: 1, fn: func(calc *calculator.Calculator, args []float64) (float64, error) {
+		return method(calc, args[0])
+	}}
+}
+
+// binary adapts a total two-argument Calculator method
+func binary(method func(*calculator.Calculator, float64, float64) float64) builtin {
+	return builtin{minArgs: 2, maxArgs: 2, fn: func(calc *calculator.Calculator, args []float64) (float64, error) {
+		return method(calc, args[0], args[1]), nil
+	}}
+}
+
+// builtins holds the functions available to every expression, keyed by lower-case name
+var builtins = map[string]builtin{
+	"sqrt":  unaryErr((*calculator.Calculator).Sqrt).withBig((*calculator.Calculator).BigSqrt).withComplex((*calculator.Calculator).CSqrt),
+	"cbrt":  unary((*calculator.Calculator).Cbrt),
+	"sin":   unary((*calculator.Calculator).Sin).withComplex((*calculator.Calculator).CSin),
+	"cos":   unary((*calculator.Calculator).Cos).withComplex((*calculator.Calculator).CCos),
+	"tan":   unaryErr((*calculator.Calculator).Tan).withComplexErr((*calculator.Calculator).CTan),
+	"asin":  unaryErr((*calculator.Calculator).Asin).withComplex((*calculator.Calculator).CAsin),
+	"acos":  unaryErr((*calculator.Calculator).Acos).withComplex((*calculator.Calculator).CAcos),
+	"atan":  unary((*calculator.Calculator).Atan).withComplex((*calculator.Calculator).CAtan),
+	"atan2": binary((*calculator.Calculator).Atan2),
+	"sinh":  unary((*calculator.Calculator).Sinh).withComplex((*calculator.Calculator).CSinh),
+	"cosh":  unary((*calculator.Calculator).Cosh).withComplex((*calculator.Calculator).CCosh),
+	"tanh":  unary((*calculator.Calculator).Tanh).withComplex((*calculator.Calculator).CTanh),
+	"ln":    unaryErr((*calculator.Calculator).Ln).withBig((*calculator.Calculator).BigLn).withComplexErr((*calculator.Calculator).CLn),
+	"log10": unaryErr((*calculator.Calculator).Log10).withComplexErr((*calculator.Calculator).CLog10),
+	"exp": unary((*calculator.Calculator).Exp).withBig(func(calc *calculator.Calculator, x *big.Float) (*big.Float, error) {
+		return calc.BigExp(x), nil
+	}).withComplex((*calculator.Calculator).CExp),
+	"abs": unary((*calculator.Calculator).Abs).withComplex(func(calc *calculator.Calculator, z complex128) complex128 {
+		return complex(calc.CAbs(z), 0)
+	}),
+	"floor": unary((*calculator.Calculator).Floor),
+	"ceil":  unary((*calculator.Calculator).Ceil),
+	"round": unary((*calculator.Calculator).Round),
+	"trunc": unary((*calculator.Calculator).Trunc),
+	"hypot": binary((*calculator.Calculator).Hypot),
+	"gamma": unaryErr((*calculator.Calculator).Gamma),
+	// log(x) is the natural logarithm, log(x, base) uses the given base
+	"log": {minArgs: 1, maxArgs: 2, fn: func(calc *calculator.Calculator, args []float64) (float64, error) {
+		if len(args) == 1 {
+			return calc.Ln(args[0])
+		}
+		return calc.Log(args[0], args[1])
+	}, cplx: func(calc *calculator.Calculator, args []complex128) (complex128, error) {
+		if len(args) == 1 {
+			return calc.CLn(args[0])
+		}
+		return calc.CLog(args[0], args[1])
+	}},
+	"min": aggregate((*calculator.Calculator).Min),
+	"max": aggregate((*calculator.Calculator).Max),
+	// Statistics over lists of samples; variance and stddev are the sample
+	// statistics, pvariance and pstddev those of a whole population
+	"sum": aggregate(func(calc *calculator.Calculator, values ...float64) (float64, error) {
+		return calc.Sum(values...), nil
+	}),
+	"count": aggregate(func(calc *calculator.Calculator, values ...float64) (float64, error) {
+		return float64(len(values)), nil
+	}),
+	"mean":      aggregate((*calculator.Calculator).Mean),
+	"median":    aggregate((*calculator.Calculator).Median),
+	"mode":      aggregate((*calculator.Calculator).Mode),
+	"variance":  aggregate((*calculator.Calculator).SampleVariance),
+	"stddev":    aggregate((*calculator.Calculator).SampleStdDev),
+	"pvariance": aggregate((*calculator.Calculator).PopulationVariance),
+	"pstddev":   aggregate((*calculator.Calculator).PopulationStdDev),
+	// percentile(list, p) takes the p-th percentile, 0 to 100, of the list
+	"percentile": {minArgs: 2, maxArgs: variadic, spread: true, fn: func(calc *calculator.Calculator, args []float64) (float64, error) {
+		return calc.Percentile(args[:len(args)-1], args[len(args)-1])
+	}},
+	// Complex helpers also accept real numbers, which have no imaginary part
+	"re": unary(func(calc *calculator.Calculator, x float64) float64 { return x }).withComplex(func(calc *calculator.Calculator, z complex128) complex128 {
+		return complex(real(z), 0)
+	}),
+	"im": unary(func(calc *calculator.Calculator, x float64) float64 { return 0 }).withComplex(func(calc *calculator.Calculator, z complex128) complex128 {
+		return complex(imag(z), 0)
+	}),
+	"conj": unary(func(calc *calculator.Calculator, x float64) float64 { return x }).withComplex((*calculator.Calculator).CConj),
+	"arg": unary(func(calc *calculator.Calculator, x float64) float64 { return calc.CArg(complex(x, 0)) }).withComplex(func(calc *calculator.Calculator, z complex128) complex128 {
+		return complex(calc.CArg(z), 0)
+	}),
+}
+
+// evaluatorFuncs are the functions handled by the evaluator itself rather than
+// a table, because they control how their arguments are evaluated
+var evaluatorFuncs = []string{ifFunc, pctChangeFunc, solveFunc, rootsFunc, integrateFunc, derivFunc, diffFunc, simplifyFunc}
+
+// BuiltinNames returns the names of the built-in functions in sorted order
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins)+len(matrixFuncs)+len(intFuncs)+len(evaluatorFuncs))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	for name := range matrixFuncs {
+		names = append(names, name)
+	}
+	for name := range intFuncs {
+		names = append(names, name)
+	}
+	names = append(names, evaluatorFuncs...)
+	sort.Strings(names)
+	return names
+}
+
+// isBuiltinFunc reports whether name refers to a built-in function
+func isBuiltinFunc(name string) bool {
+	lower := strings.ToLower(name)
+	_, ok := builtins[lower]
+	_, matrix := matrixFuncs[lower]
+	_, integer := intFuncs[lower]
+	if ok || matrix || integer {
+		return true
+	}
+	for _, name := range evaluatorFuncs {
+		if lower == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package expr
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestBuiltinFunctions(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"sin(0) + cos(0)", 1},
+		{"atan2(1, 1) * 4", math.Pi},
+		{"asin(1) * 2", math.Pi},
+		{"ln(exp(2))", 2},
+		{"log(exp(1))", 1},
+		{"log(1024, 2)", 10},
+		{"log10(0.001)", -3},
+		{"abs(-3) + floor(2.7) + ceil(2.1) + round(2.5) + trunc(-2.9)", 9},
+		{"min(4, 2, 8)", 2},
+		{"max(4)", 4},
+		{"MAX(1, 2, 3, 4, 5)", 5},
+		{"cbrt(-8)", -2},
+		{"hypot(5, 12)", 13},
+		{"tanh(0) + sinh(0) + cosh(0)", 1},
+	}
+
+	for _, test := range tests {
+		result, err := evaluateFloat(e, test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if math.Abs(result-test.expected) > 1e-9 {
+			t.Errorf("Evaluate(%q): expected %v, got %v", test.src, test.expected, result)
+		}
+	}
+}
+
+func TestBuiltinFunctionErrors(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []string{
+		"asin(2)",
+		"ln(0)",
+		"log(8, 1)",
+		"log(1, 2, 3)",
+		"min()",
+		"atan2(1)",
+		"hypot(1, 2, 3)",
+	}
+
+	for _, src := range tests {
+		if _, err := evaluateFloat(e, src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestBuiltinArityMessages(t *testing.T) {
+	tests := []struct {
+		b        builtin
+		n        int
+		expected string
+	}{
+		{unary(nil), 2, "f expects 1 argument(s), got 2"},
+		{builtins["log"], 3, "f expects 1 to 2 arguments, got 3"},
+		{builtins["min"], 0, "f expects at least 1 argument(s), got 0"},
+	}
+
+	for _, test := range tests {
+		err := test.b.checkArity("f", test.n)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected %q, got %v", test.expected, err)
+		}
+	}
+	if err := builtins["max"].checkArity("max", 10); err != nil {
+		t.Errorf("Expected variadic max to accept 10 arguments, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+const (
+	// integrateFunc computes a definite integral, integrate(expr, x, a, b)
+	integrateFunc = "integrate"
+	// derivFunc computes a derivative at a point, deriv(expr, x, at)
+	derivFunc = "deriv"
+)
+
+// evalIntegrate integrates the first argument over the variable named by the
+// second between the bounds, which may be quantities or inf. The result has
+// the unit of the expression times the unit of the variable, so integrating a
+// power in W over a time in h gives an energy.
+func (e *Evaluator) evalIntegrate(n *Call) (Value, error) {
+	if len(n.Args) != 4 {
+		return nil, fmt.Errorf("%s expects 4 argument(s), got %d", n.Name, len(n.Args))
+	}
+	name, err := variableArg(n, 1)
+	if err != nil {
+		return nil, err
+	}
+	a, b, unit, err := e.evalInterval(n, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	f := e.newExprFunc(n.Args[0], name, unit)
+	r, err := e.calc.Integrate(f.eval, a, b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.Name, err)
+	}
+	if r.Converged {
+		e.note("%s: 15-point Gauss-Kronrod over %d subintervals, estimated error %.2g", n.Name, r.Intervals, r.Error)
+	} else {
+		e.note("%s: tolerance not met after %d subintervals, estimated error %.2g, so the result may be inaccurate", n.Name, r.Intervals, r.Error)
+	}
+	// A result within its error estimate of zero, as when the areas above and
+	// below the axis cancel, is zero
+	value := roundSignificant(r.Value, 15)
+	if math.Abs(value) <= r.Error {
+		value = 0
+	}
+	return withUnit(value, resultUnit(f).Mul(unit)), nil
+}
+
+// evalDeriv differentiates the first argument with respect to the variable
+// named by the second at the point given by the third. The result has the unit
+// of the expression divided by the unit of the variable, and is rounded to 12
+// significant digits, about the accuracy the differences can reach.
+func (e *Evaluator) evalDeriv(n *Call) (Value, error) {
+	if len(n.Args) != 3 {
+		return nil, fmt.Errorf("%s expects 3 argument(s), got %d", n.Name, len(n.Args))
+	}
+	name, err := variableArg(n, 1)
+	if err != nil {
+		return nil, err
+	}
+	at, err := e.Eval(n.Args[2])
+	if err != nil {
+		return nil, err
+	}
+	x, unit, err := magnitude(at)
+	if err != nil {
+		return nil, err
+	}
+
+	f := e.newExprFunc(n.Args[0], name, unit)
+	r, err := e.calc.Derivative(f.eval, x)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.Name, err)
+	}
+	if r.Converged {
+		e.note("%s: Richardson extrapolation of central differences, estimated error %.2g", n.Name, r.Error)
+	} else {
+		e.note("%s: tolerance not met, estimated error %.2g, so the result may be inaccurate", n.Name, r.Error)
+	}
+	return withUnit(roundSignificant(r.Value, 12), resultUnit(f).Div(unit)), nil
+}
+
+// resultUnit returns the unit of the values of f, which is dimensionless when
+// f was never evaluated
+func resultUnit(f *exprFunc) units.Unit {
+	if !f.seen {
+		return dimensionless
+	}
+	return f.result
+}
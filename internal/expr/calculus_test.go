@@ -0,0 +1,87 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestCalculus(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"integrate(sin(x), x, 0, pi)", "2"},
+		{"integrate(1/x, x, 1, e)", "1"},
+		{"integrate(exp(-x), x, 0, inf)", "1"},
+		{"integrate(exp(-x^2), x, -inf, inf)", "1.77245385090552"},
+		{"integrate(x, x, 1, 0)", "-0.5"},
+		{"integrate(60 W, t, 0 h, 2 h)", "120 W*h"},
+		{"integrate(9.8 m/s^2 * t, t, 0 s, 3 s)", "44.1 m"},
+		{"f(y) = y^3 - 2*y", ""},
+		{"integrate(f(y), y, 0, 2)", "0"},
+		{"deriv(f(y), y, 2)", "10"},
+		{"deriv(x^2, x, 3)", "6"},
+		{"deriv(sin(x), x, 1)", "0.540302305868"},
+		{"deriv(cos(x), x, 0)", "0"},
+		{"deriv(5 m/s^2 * t^2 / 2, t, 2 s)", "10 m/s"},
+		{"k = 3", ""},
+		{"g(a) = deriv(k * x^a, x, 1)", ""},
+		{"g(4)", "12"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if test.expected != "" && v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	errors := []struct {
+		src     string
+		message string
+	}{
+		{"integrate(1/x, x, -1, 1)", "division by zero"},
+		{"integrate(1, x, 0, inf)", "does not decay fast enough"},
+		{"integrate(x, x, 0 m, 1 s)", "incompatible dimensions"},
+		{"integrate(x, x, 0)", "expects 4 argument(s)"},
+		{"deriv(ln(x), x, 0)", "not defined on both sides of 0"},
+		{"deriv(x, 1, 2)", "argument 2 must be a variable name"},
+		{"deriv(if(floor(x), 1 m, 1 s), x, 1)", "changes dimension"},
+	}
+	for _, test := range errors {
+		_, err := e.Evaluate(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Evaluate(%q): expected error containing %q, got %v", test.src, test.message, err)
+		}
+	}
+}
+
+func TestCalculusNotes(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src  string
+		note string
+	}{
+		{"integrate(x^2, x, 0, 1)", "integrate: 15-point Gauss-Kronrod over 1 subintervals, estimated error 0"},
+		{"integrate(sin(1/x), x, 0, 1)", "integrate: tolerance not met after 500 subintervals"},
+		{"deriv(x^2, x, 3)", "deriv: Richardson extrapolation of central differences, estimated error "},
+		{"deriv(sqrt(abs(x - 0.001)), x, 0)", "deriv: tolerance not met"},
+	}
+	for i, test := range tests {
+		if _, err := e.Evaluate(test.src); err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if notes := e.Notes(); len(notes) != i+1 || !strings.HasPrefix(notes[i], test.note) {
+			t.Errorf("Evaluate(%q): expected a note starting %q, got %q", test.src, test.note, notes)
+		}
+	}
+}
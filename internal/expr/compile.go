@@ -0,0 +1,343 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// A Program is an expression compiled to bytecode for a small stack machine
+// working in float64. Evaluating it with different values of its parameters
+// skips lexing, parsing and the dynamic dispatch of the tree-walking evaluator
+// and allocates nothing, which pays off when the same expression is evaluated
+// many times, as by the root finders and quadrature. Variables other than the
+// parameters are read once, when the program is compiled.
+//
+// A Program is not safe for concurrent use, since its stack is reused.
+type Program struct {
+	code   []instr
+	consts []float64
+	funcs  []builtin
+	params int
+	slots  []float64 // parameters, then the arguments of inlined user functions
+	stack  []float64
+	e      *Evaluator
+}
+
+// opcode identifies an instruction of the stack machine
+type opcode uint8
+
+const (
+	opConst opcode = iota // push consts[arg]
+	opLoad                // push slots[arg]
+	opStore               // pop into slots[arg]
+	opNeg
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opPow
+	opMod
+	opQuot
+	opCall       // call funcs[arg] with the top n values as arguments
+	opJump       // continue at code[arg]
+	opJumpIfZero // pop, and continue at code[arg] if the value is zero
+)
+
+// maxProgramSize bounds the number of instructions of a program, which inlining
+// functions that call other functions several times can multiply
+const maxProgramSize = 1 << 16
+
+// instr is a single instruction with its operands
+type instr struct {
+	op  opcode
+	arg int32
+	n   int32
+}
+
+// binaryOps maps the operators the stack machine implements to opcodes
+var binaryOps = map[string]opcode{
+	"+":   opAdd,
+	"-":   opSub,
+	"*":   opMul,
+	"of":  opMul,
+	"/":   opDiv,
+	"^":   opPow,
+	"%":   opMod,
+	"mod": opMod,
+	"//":  opQuot,
+}
+
+// compiler holds the state of a compilation: the program being built, the
+// slots of the names in scope and the depth of the stack at each point
+type compiler struct {
+	e     *Evaluator
+	p     *Program
+	scope map[string]int
+	// inlining holds the user functions whose bodies are being compiled
+	inlining []*Function
+	sp       int
+	maxSP    int
+}
+
+// Compile translates node into a Program whose parameters are the named
+// variables, in order. It fails for anything the stack machine cannot compute
+// exactly like the evaluator: modes other than float64, units, lists, dates,
+// percentages, recursive functions and functions that control their own
+// evaluation such as solve. Callers can then fall back to Eval.
+func (e *Evaluator) Compile(node Node, params ...string) (*Program, error) {
+	switch {
+	case e.calc.NumberMode() != calculator.FloatMode:
+		return nil, fmt.Errorf("cannot compile in %s mode", e.calc.NumberMode())
+	case e.precise():
+		return nil, fmt.Errorf("cannot compile in precision mode")
+	}
+	c := &compiler{e: e, p: &Program{params: len(params), e: e}, scope: make(map[string]int, len(params))}
+	for i, param := range params {
+		c.scope[param] = i
+	}
+	c.p.slots = make([]float64, len(params))
+	if err := c.compile(node); err != nil {
+		return nil, err
+	}
+	c.p.stack = make([]float64, c.maxSP)
+	return c.p, nil
+}
+
+// emit appends an instruction, tracking its effect on the depth of the stack
+func (c *compiler) emit(op opcode, arg, n int, effect int) {
+	c.p.code = append(c.p.code, instr{op: op, arg: int32(arg), n: int32(n)})
+	c.sp += effect
+	if c.sp > c.maxSP {
+		c.maxSP = c.sp
+	}
+}
+
+// constant emits an instruction pushing x
+func (c *compiler) constant(x float64) {
+	c.p.consts = append(c.p.consts, x)
+	c.emit(opConst, len(c.p.consts)-1, 0, 1)
+}
+
+func (c *compiler) compile(node Node) error {
+	switch n := node.(type) {
+	case *Number:
+		if n.Imag {
+			return fmt.Errorf("cannot compile imaginary number %s", n)
+		}
+		c.constant(n.Value)
+		return nil
+	case *Ident:
+		if slot, ok := c.scope[n.Name]; ok {
+			c.emit(opLoad, slot, 0, 1)
+			return nil
+		}
+		v, err := c.resolve(n.Name)
+		if err != nil {
+			return err
+		}
+		f, ok := c.e.normalize(v).(Float)
+		if !ok {
+			return fmt.Errorf("cannot compile %s, whose value %s is not a plain number", n.Name, v)
+		}
+		c.constant(float64(f))
+		return nil
+	case *Unary:
+		if n.Op != "-" && n.Op != "+" {
+			return fmt.Errorf("cannot compile operator %s", n.Op)
+		}
+		if err := c.compile(n.X); err != nil {
+			return err
+		}
+		if n.Op == "-" {
+			c.emit(opNeg, 0, 0, 0)
+		}
+		return nil
+	case *Binary:
+		op, ok := binaryOps[n.Op]
+		if !ok {
+			return fmt.Errorf("cannot compile operator %s", n.Op)
+		}
+		if err := c.compile(n.X); err != nil {
+			return err
+		}
+		if err := c.compile(n.Y); err != nil {
+			return err
+		}
+		c.emit(op, 0, 0, -1)
+		return nil
+	case *Call:
+		return c.compileCall(n)
+	}
+	return fmt.Errorf("cannot compile %s", node)
+}
+
+// resolve looks up a variable that is not in scope as the evaluator would.
+// Inside an inlined function body that skips the parameters of the function
+// being evaluated, as only the body's own parameters are visible there.
+func (c *compiler) resolve(name string) (Value, error) {
+	if len(c.inlining) > 0 {
+		frames := c.e.frames
+		c.e.frames = nil
+		defer func() { c.e.frames = frames }()
+	}
+	return c.e.lookup(name)
+}
+
+// compileCall compiles a conditional, a call of a builtin, or a call of a user
+// function, whose body is inlined with its arguments in fresh slots
+func (c *compiler) compileCall(n *Call) error {
+	name := strings.ToLower(n.Name)
+	if name == ifFunc {
+		return c.compileIf(n)
+	}
+	if fn, ok := c.e.env.Func(n.Name); ok {
+		return c.inline(fn, n)
+	}
+	fn, ok := builtins[name]
+	switch {
+	case !ok && isBuiltinFunc(name):
+		return fmt.Errorf("cannot compile %s", n.Name)
+	case !ok:
+		return fmt.Errorf("unknown function %q", n.Name)
+	}
+	if err := fn.checkArity(n.Name, len(n.Args)); err != nil {
+		return err
+	}
+	for _, arg := range n.Args {
+		if err := c.compile(arg); err != nil {
+			return err
+		}
+	}
+	c.p.funcs = append(c.p.funcs, fn)
+	c.emit(opCall, len(c.p.funcs)-1, len(n.Args), 1-len(n.Args))
+	return nil
+}
+
+// compileIf compiles if(cond, then, else) to jumps, so that only the selected
+// branch is computed
+func (c *compiler) compileIf(n *Call) error {
+	if len(n.Args) != 3 {
+		return fmt.Errorf("%s expects 3 argument(s), got %d", n.Name, len(n.Args))
+	}
+	if err := c.compile(n.Args[0]); err != nil {
+		return err
+	}
+	toElse := len(c.p.code)
+	c.emit(opJumpIfZero, 0, 0, -1)
+	if err := c.compile(n.Args[1]); err != nil {
+		return err
+	}
+	toEnd := len(c.p.code)
+	c.emit(opJump, 0, 0, -1)
+	c.p.code[toElse].arg = int32(len(c.p.code))
+	if err := c.compile(n.Args[2]); err != nil {
+		return err
+	}
+	c.p.code[toEnd].arg = int32(len(c.p.code))
+	return nil
+}
+
+// inline compiles the arguments of a user function call into new slots and
+// then its body with the parameters bound to them
+func (c *compiler) inline(fn *Function, n *Call) error {
+	if len(n.Args) != len(fn.Params) {
+		return fmt.Errorf("%s expects %d argument(s), got %d", fn.Signature(), len(fn.Params), len(n.Args))
+	}
+	// A function that calls itself cannot be inlined, as the recursion only
+	// stops at run time
+	for _, outer := range c.inlining {
+		if outer.Name == fn.Name {
+			return fmt.Errorf("cannot compile recursive function %s", fn.Name)
+		}
+	}
+	if len(c.p.code) > maxProgramSize {
+		return fmt.Errorf("expression is too large to compile")
+	}
+	scope := make(map[string]int, len(fn.Params))
+	for i, arg := range n.Args {
+		if err := c.compile(arg); err != nil {
+			return err
+		}
+		scope[fn.Params[i]] = len(c.p.slots)
+		c.p.slots = append(c.p.slots, 0)
+		c.emit(opStore, scope[fn.Params[i]], 0, -1)
+	}
+
+	outer := c.scope
+	c.scope = scope
+	c.inlining = append(c.inlining, fn)
+	err := c.compile(fn.Body)
+	c.inlining = c.inlining[:len(c.inlining)-1]
+	c.scope = outer
+	return err
+}
+
+// Run evaluates the program with its parameters bound to args, in order
+func (p *Program) Run(args ...float64) (float64, error) {
+	if len(args) != p.params {
+		return 0, fmt.Errorf("program expects %d argument(s), got %d", p.params, len(args))
+	}
+	copy(p.slots, args)
+	calc := p.e.calc
+	stack := p.stack
+	sp := 0
+	for pc := 0; pc < len(p.code); pc++ {
+		in := p.code[pc]
+		switch in.op {
+		case opConst:
+			stack[sp] = p.consts[in.arg]
+			sp++
+		case opLoad:
+			stack[sp] = p.slots[in.arg]
+			sp++
+		case opStore:
+			sp--
+			p.slots[in.arg] = stack[sp]
+		case opNeg:
+			stack[sp-1] = calc.Subtract(0, stack[sp-1])
+		case opJump:
+			pc = int(in.arg) - 1
+		case opJumpIfZero:
+			sp--
+			if stack[sp] == 0 {
+				pc = int(in.arg) - 1
+			}
+		case opCall:
+			base := sp - int(in.n)
+			result, err := p.funcs[in.arg].fn(calc, stack[base:sp])
+			if err != nil {
+				return 0, err
+			}
+			stack[base] = result
+			sp = base + 1
+		default:
+			sp--
+			a, b := stack[sp-1], stack[sp]
+			var result float64
+			var err error
+			switch in.op {
+			case opAdd:
+				result = calc.Add(a, b)
+			case opSub:
+				result = calc.Subtract(a, b)
+			case opMul:
+				result = calc.Multiply(a, b)
+			case opDiv:
+				result, err = calc.Divide(a, b)
+			case opPow:
+				result = calc.Power(a, b)
+			case opMod:
+				result, err = calc.ModFloat(a, b)
+			case opQuot:
+				result, err = calc.Quotient(a, b)
+			}
+			if err != nil {
+				return 0, err
+			}
+			stack[sp-1] = result
+		}
+	}
+	return stack[0], nil
+}
@@ -0,0 +1,170 @@
+package expr
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// benchmarkSrc exercises operators, builtins, constants and a conditional
+const benchmarkSrc = "3*x^2 - 2*x + sin(x) / sqrt(abs(x) + 1) + if(floor(x), ln(x + e), 1) - x mod 3"
+
+func TestCompile(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+	for _, src := range []string{"k = 2.5", "sq(t) = t * t", "hyp(a, b) = sqrt(sq(a) + sq(b))"} {
+		if _, err := e.Evaluate(src); err != nil {
+			t.Fatalf("Evaluate(%q) failed: %v", src, err)
+		}
+	}
+
+	sources := []string{
+		"x",
+		"-x^2",
+		"2^-x",
+		"sqrt(x) + 1 / x",
+		benchmarkSrc,
+		"k * x + pi",
+		"x // 2 + x % 2",
+		"3 of x",
+		"hyp(x, 4)",
+		"if(x - 1, sq(x), -1)",
+		"max(x, 2, k) + min(x, 0)",
+		"atan2(x, 1) + log(x + 10, 10)",
+	}
+	for _, src := range sources {
+		node, err := Parse(src)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", src, err)
+		}
+		p, err := e.Compile(node, "x")
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", src, err)
+			continue
+		}
+		for _, x := range []float64{-2.5, 0, 1, 3, 7.25} {
+			expected, evalErr := e.evalWith(node, "x", Float(x))
+			got, err := p.Run(x)
+			if evalErr != nil || err != nil {
+				if evalErr == nil || err == nil || err.Error() != evalErr.Error() {
+					t.Errorf("Run(%q) at %g: expected error %v, got %v", src, x, evalErr, err)
+				}
+				continue
+			}
+			want := float64(expected.(Float))
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Errorf("Run(%q) at %g: expected %v, got %v", src, x, want, got)
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+	for _, src := range []string{"fact(n) = if(n, n * fact(n - 1), 1)", "l = [1, 2]"} {
+		if _, err := e.Evaluate(src); err != nil {
+			t.Fatalf("Evaluate(%q) failed: %v", src, err)
+		}
+	}
+
+	tests := []struct {
+		src     string
+		message string
+	}{
+		{"x * m", "not a plain number"},
+		{"x + l", "not a plain number"},
+		{"x + y", "undefined identifier"},
+		{"fact(x)", "recursive function fact"},
+		{"solve(x - 1, x, 0)", "cannot compile solve"},
+		{"det(x)", "cannot compile det"},
+		{"nosuch(x)", "unknown function"},
+		{"sqrt(x, 1)", "sqrt expects"},
+		{"x & 1", "cannot compile operator &"},
+		{"50%", "cannot compile"},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.src)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", test.src, err)
+		}
+		_, err = e.Compile(node, "x")
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Compile(%q): expected error containing %q, got %v", test.src, test.message, err)
+		}
+	}
+
+	node, _ := Parse("1 / x")
+	p, err := e.Compile(node, "x")
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, err := p.Run(0); err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Errorf("Run(0): expected division by zero, got %v", err)
+	}
+	if _, err := p.Run(1, 2); err == nil {
+		t.Errorf("Run(1, 2): expected an arity error")
+	}
+
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.RationalMode)
+	if _, err := NewEvaluator(calc, nil).Compile(node, "x"); err == nil || !strings.Contains(err.Error(), "rational mode") {
+		t.Errorf("Compile in rational mode: expected an error, got %v", err)
+	}
+}
+
+func TestProgramAllocations(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+	node, _ := Parse(benchmarkSrc)
+	p, err := e.Compile(node, "x")
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	x := 0.0
+	allocs := testing.AllocsPerRun(100, func() {
+		x += 0.5
+		if _, err := p.Run(x); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Run allocated %v times per evaluation, expected 0", allocs)
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	e := NewEvaluator(calculator.New(), nil)
+	src := strings.ReplaceAll(benchmarkSrc, "x", "2.5")
+	for i := 0; i < b.N; i++ {
+		if _, err := e.Evaluate(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	e := NewEvaluator(calculator.New(), nil)
+	node, _ := Parse(benchmarkSrc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := e.evalWith(node, "x", Float(2.5)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgramRun(b *testing.B) {
+	e := NewEvaluator(calculator.New(), nil)
+	node, _ := Parse(benchmarkSrc)
+	p, err := e.Compile(node, "x")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Run(2.5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package expr
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// Constant is a named, read-only value available to every expression. Physical
+// constants evaluate to quantities in their SI unit, so c * 2 s is a distance.
+type Constant struct {
+	Name        string
+	Value       float64
+	Unit        string // SI unit of physical constants, empty for pure numbers
+	Description string
+}
+
+// constantList holds the built-in constants in display order. Physical constants
+// use CODATA 2018 recommended values; c, h, k_B, N_A and q_e are exact in the SI.
+var constantList = []Constant{
+	{"pi", math.Pi, "", "ratio of a circle's circumference to its diameter"},
+	{"e", math.E, "", "base of the natural logarithm"},
+	{"phi", math.Phi, "", "golden ratio"},
+	{"tau", 2 * math.Pi, "", "ratio of a circle's circumference to its radius"},
+	{"sqrt2", math.Sqrt2, "", "square root of 2"},
+	{"ln2", math.Ln2, "", "natural logarithm of 2"},
+	{"inf", math.Inf(1), "", "infinity, as a bound of integrals"},
+	{"c", 299792458, "m/s", "speed of light in vacuum"},
+	{"G", 6.67430e-11, "m^3/(kg s^2)", "Newtonian constant of gravitation"},
+	{"h", 6.62607015e-34, "J s", "Planck constant"},
+	{"hbar", 6.62607015e-34 / (2 * math.Pi), "J s", "reduced Planck constant"},
+	{"k_B", 1.380649e-23, "J/K", "Boltzmann constant"},
+	{"N_A", 6.02214076e23, "1/mol", "Avogadro constant"},
+	{"R", 8.314462618, "J/(mol K)", "molar gas constant"},
+	{"q_e", 1.602176634e-19, "C", "elementary charge"},
+	{"m_e", 9.1093837015e-31, "kg", "electron mass"},
+	{"m_p", 1.67262192369e-27, "kg", "proton mass"},
+	{"epsilon_0", 8.8541878128e-12, "F/m", "vacuum electric permittivity"},
+	{"mu_0", 1.25663706212e-6, "N/A^2", "vacuum magnetic permeability"},
+	{"sigma", 5.670374419e-8, "W/(m^2 K^4)", "Stefan-Boltzmann constant"},
+	{"g_n", 9.80665, "m/s^2", "standard acceleration of gravity"},
+}
+
+// bigConstants computes the irrational constants to the calculator's precision;
+// the remaining constants are exact decimals converted from their float64 value
+var bigConstants = map[string]func(calc *calculator.Calculator) *big.Float{
+	"pi": (*calculator.Calculator).BigPi,
+	"e": func(calc *calculator.Calculator) *big.Float {
+		return calc.BigExp(big.NewFloat(1))
+	},
+	"tau": bigTau,
+	"phi": func(calc *calculator.Calculator) *big.Float {
+		root, _ := calc.BigSqrt(big.NewFloat(5))
+		root.Add(root, big.NewFloat(1))
+		return root.Quo(root, big.NewFloat(2))
+	},
+	"sqrt2": func(calc *calculator.Calculator) *big.Float {
+		root, _ := calc.BigSqrt(big.NewFloat(2))
+		return root
+	},
+	"ln2": func(calc *calculator.Calculator) *big.Float {
+		ln, _ := calc.BigLn(big.NewFloat(2))
+		return ln
+	},
+}
+
+// bigTau computes 2 pi to the calculator's precision
+func bigTau(calc *calculator.Calculator) *big.Float {
+	pi := calc.BigPi()
+	return pi.Add(pi, pi)
+}
+
+// constants indexes constantList by name
+var constants = func() map[string]Constant {
+	m := make(map[string]Constant, len(constantList))
+	for _, c := range constantList {
+		m[c.Name] = c
+	}
+	return m
+}()
+
+// Constants returns the built-in constants in display order
+func Constants() []Constant {
+	return append([]Constant(nil), constantList...)
+}
+
+// isConstant reports whether name refers to a built-in constant
+func isConstant(name string) bool {
+	_, ok := constants[name]
+	return ok
+}
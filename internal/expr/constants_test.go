@@ -0,0 +1,120 @@
+package expr
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+func TestConstants(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"pi", math.Pi},
+		{"tau / 2", math.Pi},
+		{"ln(e)", 1},
+		{"phi ^ 2 - phi", 1},
+		{"sqrt2 ^ 2", 2},
+		{"exp(ln2)", 2},
+		{"exp(-inf)", 0},
+		{"c / (1 m/s)", 299792458},
+		{"h / (2 * pi) / hbar", 1},
+		{"k_B * N_A / R", 1},
+	}
+
+	for _, test := range tests {
+		result, err := evaluateFloat(e, test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if math.Abs(result-test.expected) > 1e-9*math.Max(1, math.Abs(test.expected)) {
+			t.Errorf("Evaluate(%q): expected %v, got %v", test.src, test.expected, result)
+		}
+	}
+}
+
+func TestConstantsAreProtected(t *testing.T) {
+	env := NewEnv()
+	e := NewEvaluator(calculator.New(), env)
+
+	for _, src := range []string{"pi = 3", "c = 1", "N_A = 6e23"} {
+		if _, err := evaluateFloat(e, src); err == nil {
+			t.Errorf("Expected error reassigning constant in %q", src)
+		}
+	}
+	if v, _ := evaluateFloat(e, "pi"); v != math.Pi {
+		t.Errorf("Expected pi to be unchanged, got %v", v)
+	}
+
+	// Parameters are local, so they may shadow a constant inside a function body
+	if _, err := e.Evaluate("scale(c) = c * 2"); err != nil {
+		t.Fatalf("Failed to define scale: %v", err)
+	}
+	if v, err := evaluateFloat(e, "(scale(3 m/s) + c) / (1 m/s)"); err != nil || v != 299792464 {
+		t.Errorf("Expected scale(3 m/s) + c = 299792464 m/s, got %v (err: %v)", v, err)
+	}
+}
+
+func TestConstantUnits(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"c", "2.99792458e+08 m/s"},
+		{"c * 2 s to km", "599584.916 km"},
+		{"h * 1 Hz to J", "6.62607015e-34 J"},
+		{"g_n * 2 kg to N", "19.6133 N"},
+		// A bare name is the constant; after a number or to it is the unit
+		{"h", "6.62607015e-34 J*s"},
+		{"3 h to min", "180 min"},
+		{"7200 s to h", "2 h"},
+		{"3 hbar to bar", "300 bar"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{"c + 1", "h + 1 h", "c * 2 s to kg"} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestConstantsMetadata(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range Constants() {
+		if seen[c.Name] {
+			t.Errorf("Duplicate constant %q", c.Name)
+		}
+		seen[c.Name] = true
+		if c.Description == "" {
+			t.Errorf("Constant %q has no description", c.Name)
+		}
+		if c.Unit != "" {
+			if _, err := units.Parse(c.Unit); err != nil {
+				t.Errorf("Constant %q has unit %q: %v", c.Name, c.Unit, err)
+			}
+		}
+	}
+	for _, name := range []string{"G", "h", "k_B", "N_A"} {
+		if constants[name].Unit == "" {
+			t.Errorf("Expected physical constant %q to note its unit", name)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the IANA time zone database so that zones such as Europe/Paris load
+	// on systems without one installed
+	_ "time/tzdata"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+// day is the length of a calendar day without a daylight saving change
+const day = 24 * time.Hour
+
+// second is the unit in which durations convert to quantities
+var second, _ = units.Lookup("s")
+
+// errDurationRange is returned for durations beyond about 292 years
+var errDurationRange = errors.New("duration out of range")
+
+// Time is a moment produced by date and time literals, now and today. A Time
+// with DateOnly set is midnight of a calendar date and is shown without a clock.
+type Time struct {
+	T        time.Time
+	DateOnly bool
+}
+
+// String formats the date as 2026-10-16, or with the time and zone as
+// 2026-10-16 14:30:00 CEST
+func (t Time) String() string {
+	if t.DateOnly {
+		return t.T.Format("2006-01-02")
+	}
+	return t.T.Format("2006-01-02 15:04:05 MST")
+}
+
+// Duration is a length of time such as 1h30m
+type Duration time.Duration
+
+// String formats the duration in days, hours, minutes and seconds, omitting
+// zero parts, e.g. 2d3h or 1m30.5s
+func (d Duration) String() string {
+	if d == 0 {
+		return "0s"
+	}
+	var b strings.Builder
+	// Work in uint64 so that the most negative duration has a magnitude
+	n := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		n = -n
+	}
+	parts := []struct {
+		size   uint64
+		suffix string
+	}{{uint64(day), "d"}, {uint64(time.Hour), "h"}, {uint64(time.Minute), "m"}}
+	for _, part := range parts {
+		if n >= part.size {
+			fmt.Fprintf(&b, "%d%s", n/part.size, part.suffix)
+			n %= part.size
+		}
+	}
+	if n > 0 {
+		b.WriteString(strconv.FormatFloat(float64(n)/float64(time.Second), 'f', -1, 64) + "s")
+	}
+	return b.String()
+}
+
+// durationUnits maps the suffixes of duration literals to their length
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  day,
+	"w":  7 * day,
+}
+
+// parseDuration parses a duration literal such as 1h30m or 1.5d2h, which
+// extends Go's duration syntax with days and weeks
+func parseDuration(text string) (Duration, error) {
+	var total float64
+	for _, part := range durationPart.FindAllStringSubmatch(text, -1) {
+		number := strings.TrimSuffix(part[0], part[2])
+		x, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed duration %q", text)
+		}
+		total += x * float64(durationUnits[part[2]])
+	}
+	return durationFromNanos(total)
+}
+
+// durationFromNanos rounds a number of nanoseconds to a Duration
+func durationFromNanos(ns float64) (Duration, error) {
+	if math.IsNaN(ns) || math.Abs(ns) >= math.MaxInt64 {
+		return 0, errDurationRange
+	}
+	return Duration(math.Round(ns)), nil
+}
+
+// parseDateTime parses a date, a time of day or both, as produced by the lexer,
+// in loc. A time of day on its own falls on the date of now.
+func parseDateTime(text string, loc *time.Location, now time.Time) (Time, error) {
+	datePart, clock, _ := strings.Cut(strings.Replace(text, "T", " ", 1), " ")
+	if !strings.Contains(datePart, "-") {
+		datePart, clock = "", datePart
+	}
+
+	year, month, dayOfMonth := now.Date()
+	if datePart != "" {
+		date, err := time.ParseInLocation("2006-01-02", datePart, loc)
+		if err != nil {
+			return Time{}, fmt.Errorf("invalid date %q", datePart)
+		}
+		year, month, dayOfMonth = date.Date()
+	}
+	if clock == "" {
+		return Time{T: time.Date(year, month, dayOfMonth, 0, 0, 0, 0, loc), DateOnly: true}, nil
+	}
+
+	fields := strings.Split(clock, ":")
+	hour, _ := strconv.Atoi(fields[0])
+	minute, _ := strconv.Atoi(fields[1])
+	var seconds float64
+	if len(fields) == 3 {
+		seconds, _ = strconv.ParseFloat(fields[2], 64)
+	}
+	if hour > 23 || minute > 59 || seconds >= 60 {
+		return Time{}, fmt.Errorf("invalid time of day %q", clock)
+	}
+	whole, frac := math.Modf(seconds)
+	t := time.Date(year, month, dayOfMonth, hour, minute, int(whole), int(math.Round(frac*1e9)), loc)
+	return Time{T: t}, nil
+}
+
+// loadLocation loads a time zone by IANA name, such as Asia/Tokyo or UTC
+func loadLocation(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// add moves t by d. Whole days move by calendar days, so that a date plus 7d
+// keeps its clock time across a daylight saving change and stays a date.
+func (t Time) add(d Duration) Time {
+	if time.Duration(d)%day == 0 {
+		return Time{T: t.T.AddDate(0, 0, int(time.Duration(d)/day)), DateOnly: t.DateOnly}
+	}
+	return Time{T: t.T.Add(time.Duration(d))}
+}
+
+// sub returns the time from u to t, counting calendar days between two dates
+func (t Time) sub(u Time) Duration {
+	if t.DateOnly && u.DateOnly {
+		return Duration(midnightUTC(t.T).Sub(midnightUTC(u.T)))
+	}
+	return Duration(t.T.Sub(u.T))
+}
+
+// midnightUTC returns the start of t's calendar date in UTC, where every day
+// lasts 24 hours
+func midnightUTC(t time.Time) time.Time {
+	year, month, dayOfMonth := t.Date()
+	return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+}
+
+// isTemporal reports whether v is a Time or a Duration
+func isTemporal(v Value) bool {
+	switch v.(type) {
+	case Time, Duration:
+		return true
+	}
+	return false
+}
+
+// isDurationLike reports whether v is a Duration or a quantity of time such as 90 d
+func isDurationLike(v Value) bool {
+	switch x := v.(type) {
+	case Duration:
+		return true
+	case Quantity:
+		return x.Unit.Compatible(second)
+	}
+	return false
+}
+
+// toDuration converts a Duration or a quantity of time to a Duration
+func toDuration(v Value) (Duration, error) {
+	switch x := v.(type) {
+	case Duration:
+		return x, nil
+	case Quantity:
+		if x.Unit.Compatible(second) {
+			return durationFromNanos(x.in(second) * float64(time.Second))
+		}
+	}
+	return 0, fmt.Errorf("expected a duration, got %s", v)
+}
+
+// wholeNanoseconds applies % or // to two quantities of time exactly when both
+// are whole numbers of nanoseconds, so that 1 d % 7 h is 0.125 d rather than
+// 0.12499999999999895 d. It reports false for other quantities and a zero
+// divisor, which float64 handles.
+func wholeNanoseconds(op string, a, b Quantity) (Value, bool) {
+	c, errA := toDuration(a)
+	d, errB := toDuration(b)
+	if errA != nil || errB != nil || d == 0 ||
+		float64(c) != a.in(second)*float64(time.Second) || float64(d) != b.in(second)*float64(time.Second) {
+		return nil, false
+	}
+	if op == "//" {
+		return Float(c / d), true
+	}
+	r := Quantity{Value: float64(c%d) / float64(time.Second), Unit: second}
+	return quantity(r.in(a.Unit), a.Unit), true
+}
+
+// addDurations adds two durations, reporting overflow
+func addDurations(a, b Duration) (Duration, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, errDurationRange
+	}
+	return sum, nil
+}
+
+// timeArith applies an arithmetic operator when an operand is a Time or a
+// Duration. A time minus a time is a duration; a time plus or minus a duration
+// is a time; durations add, subtract, divide into a plain ratio and scale by
+// plain numbers. Quantities of time such as 90 d count as durations.
+func (e *Evaluator) timeArith(op string, x, y Value) (Value, error) {
+	invalid := fmt.Errorf("cannot apply %s to %s and %s", op, x, y)
+	a, aTime := x.(Time)
+	b, bTime := y.(Time)
+	switch {
+	case aTime && bTime:
+		if op != "-" {
+			return nil, invalid
+		}
+		return a.sub(b), nil
+	case aTime:
+		if !isDurationLike(y) || (op != "+" && op != "-") {
+			return nil, invalid
+		}
+		d, err := toDuration(y)
+		if err != nil {
+			return nil, err
+		}
+		if op == "-" {
+			d = -d
+		}
+		return a.add(d), nil
+	case bTime:
+		if !isDurationLike(x) || op != "+" {
+			return nil, invalid
+		}
+		d, err := toDuration(x)
+		if err != nil {
+			return nil, err
+		}
+		return b.add(d), nil
+	}
+
+	if isDurationLike(x) && isDurationLike(y) {
+		c, err := toDuration(x)
+		if err != nil {
+			return nil, err
+		}
+		d, err := toDuration(y)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "+":
+			return durationResult(addDurations(c, d))
+		case "-":
+			return durationResult(addDurations(c, -d))
+		}
+		if d == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		switch op {
+		case "/":
+			return Float(float64(c) / float64(d)), nil
+		case "//":
+			return Float(c / d), nil
+		case "%":
+			return c % d, nil
+		}
+		return nil, invalid
+	}
+
+	// A duration scaled by a plain number
+	switch {
+	case isDurationLike(x) && !hasQuantity(y) && (op == "*" || op == "/"):
+		return e.scaleDuration(op, x, y)
+	case isDurationLike(y) && !hasQuantity(x) && op == "*":
+		return e.scaleDuration(op, y, x)
+	}
+	return nil, invalid
+}
+
+// scaleDuration multiplies or divides the duration d by the plain number f
+func (e *Evaluator) scaleDuration(op string, d, f Value) (Value, error) {
+	c, err := toDuration(d)
+	if err != nil {
+		return nil, err
+	}
+	factor, err := ToFloat(f)
+	if err != nil {
+		return nil, err
+	}
+	if op == "/" {
+		if factor == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return durationResult(durationFromNanos(float64(c) / factor))
+	}
+	return durationResult(durationFromNanos(float64(c) * factor))
+}
+
+// durationResult wraps the result of a fallible duration computation
+func durationResult(d Duration, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// evalInZone shows a moment in another time zone
+func (e *Evaluator) evalInZone(n *InZone) (Value, error) {
+	v, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := v.(Time)
+	if !ok {
+		return nil, fmt.Errorf("cannot show %s in time zone %s: not a date or time", v, n.Zone)
+	}
+	loc, err := loadLocation(n.Zone)
+	if err != nil {
+		return nil, err
+	}
+	return Time{T: t.T.In(loc)}, nil
+}
@@ -0,0 +1,107 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestDateTime(t *testing.T) {
+	env := NewEnv()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation failed: %v", err)
+	}
+	env.SetLocation(berlin)
+	env.SetClock(func() time.Time { return time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC) })
+	e := NewEvaluator(calculator.New(), env)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"today", "2026-10-16"},
+		{"now", "2026-10-16 11:15:00 CEST"},
+		{"14:30", "2026-10-16 14:30:00 CEST"},
+		{"2026-12-25 - today", "70d"},
+		{"2026-12-25 - today to d", "70 d"},
+		{"now + 90d", "2027-01-14 11:15:00 CET"},
+		{"today + 1w2d", "2026-10-25"},
+		{"2026-10-16 - 2026-10-17", "-1d"},
+		{"2026-10-16T08:00 in UTC", "2026-10-16 06:00:00 UTC"},
+		{"2026-10-16 14:30 in Asia/Tokyo", "2026-10-16 21:30:00 JST"},
+		{"2026-10-24 12:00 + 1 d", "2026-10-25 12:00:00 CET"},
+		{"2026-10-25 00:00 + 3h", "2026-10-25 02:00:00 CET"},
+		{"3h20m * 4", "13h20m"},
+		{"2 * 1h30m", "3h"},
+		{"1h30m / 4", "22m30s"},
+		{"1h30m + 15 min", "1h45m"},
+		{"1h30m - 2h", "-30m"},
+		{"1h30m / 30 min", "3"},
+		{"1h30m // 40 min", "2"},
+		{"1h30m % 40 min", "10m"},
+		{"1h30m to min", "90 min"},
+		// A bare m beside a duration is minutes, and remainders of times are exact
+		{"1h30m + 15m", "1h45m"},
+		{"15m + 1h30m", "1h45m"},
+		{"-1h30m - 15m", "-1h45m"},
+		{"1h30m / 30m", "3"},
+		{"1d % 7h", "0.125 d"},
+		{"1d // 7h", "3"},
+		{"10m / 2s", "5 m/s"},
+		{"-1m30s", "-1m30s"},
+		{"1s500ms", "1.5s"},
+		{"x = 2026-01-01", "2026-01-01"},
+		{"x + 1d12h", "2026-01-02 12:00:00 CET"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{
+		"2026-13-01",
+		"2026-02-29",
+		"25:00",
+		"today + today",
+		"today * 2",
+		"1h30m - 2",
+		"1h30m + 5 km",
+		"1h30m + 15 m",
+		"1h30m / 0",
+		"1h30m % 0 s",
+		"200000d1h",
+		"5 km in Asia/Tokyo",
+		"today in Nowhere/Atlantis",
+	} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestDurationString(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{90 * time.Minute, "1h30m"},
+		{49 * time.Hour, "2d1h"},
+		{1500 * time.Millisecond, "1.5s"},
+		{-time.Minute, "-1m"},
+		{time.Nanosecond, "0.000000001s"},
+	}
+	for _, test := range tests {
+		if s := Duration(test.d).String(); s != test.expected {
+			t.Errorf("Duration(%v): expected %s, got %s", test.d, test.expected, s)
+		}
+	}
+}
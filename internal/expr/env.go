@@ -0,0 +1,139 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Env is a per-session symbol table holding the variables defined by assignments,
+// user-defined functions and the history of results, along with the session's
+// time zone and clock
+type Env struct {
+	vars     map[string]Value
+	funcs    map[string]*Function
+	history  *History
+	maxDepth int
+	location *time.Location
+	clock    func() time.Time
+}
+
+// NewEnv creates an empty symbol table
+func NewEnv() *Env {
+	return &Env{
+		vars:     make(map[string]Value),
+		funcs:    make(map[string]*Function),
+		history:  &History{},
+		maxDepth: DefaultMaxDepth,
+	}
+}
+
+// History returns the session result history
+func (env *Env) History() *History {
+	return env.history
+}
+
+// isReserved reports whether name refers to the previous result rather than a variable
+func isReserved(name string) bool {
+	return name == "ans" || name == "_"
+}
+
+// Get returns the value bound to name and whether it is defined
+func (env *Env) Get(name string) (Value, bool) {
+	v, ok := env.vars[name]
+	return v, ok
+}
+
+// Set binds name to value, replacing any previous binding
+func (env *Env) Set(name string, value Value) error {
+	if isReserved(name) {
+		return fmt.Errorf("cannot assign to reserved name %q", name)
+	}
+	if isConstant(name) {
+		return fmt.Errorf("cannot assign to constant %q", name)
+	}
+	env.vars[name] = value
+	return nil
+}
+
+// Names returns the defined variable names in sorted order
+func (env *Env) Names() []string {
+	names := make([]string, 0, len(env.vars))
+	for name := range env.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Define adds or replaces a user-defined function
+func (env *Env) Define(fn *Function) error {
+	if err := fn.validate(); err != nil {
+		return err
+	}
+	env.funcs[fn.Name] = fn
+	return nil
+}
+
+// Func returns the user-defined function called name and whether it exists
+func (env *Env) Func(name string) (*Function, bool) {
+	fn, ok := env.funcs[name]
+	return fn, ok
+}
+
+// Funcs returns the user-defined functions sorted by name
+func (env *Env) Funcs() []*Function {
+	funcs := make([]*Function, 0, len(env.funcs))
+	for _, fn := range env.funcs {
+		funcs = append(funcs, fn)
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].Name < funcs[j].Name })
+	return funcs
+}
+
+// MaxDepth returns the limit on nested user-defined function calls
+func (env *Env) MaxDepth() int {
+	return env.maxDepth
+}
+
+// SetMaxDepth changes the limit on nested user-defined function calls, which
+// must be between 1 and MaxCallDepth
+func (env *Env) SetMaxDepth(depth int) error {
+	if depth < 1 {
+		return fmt.Errorf("maximum depth must be at least 1, got %d", depth)
+	}
+	if depth > MaxCallDepth {
+		return fmt.Errorf("maximum depth must be at most %d, got %d", MaxCallDepth, depth)
+	}
+	env.maxDepth = depth
+	return nil
+}
+
+// Location returns the time zone in which dates and times are read and shown,
+// the system's local zone unless changed
+func (env *Env) Location() *time.Location {
+	if env.location == nil {
+		return time.Local
+	}
+	return env.location
+}
+
+// SetLocation changes the session time zone
+func (env *Env) SetLocation(loc *time.Location) {
+	env.location = loc
+}
+
+// Now returns the current time in the session time zone
+func (env *Env) Now() time.Time {
+	now := time.Now
+	if env.clock != nil {
+		now = env.clock
+	}
+	return now().In(env.Location())
+}
+
+// SetClock replaces the source of the current time, for tests; nil restores
+// the system clock
+func (env *Env) SetClock(clock func() time.Time) {
+	env.clock = clock
+}
@@ -0,0 +1,25 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvGetSet(t *testing.T) {
+	env := NewEnv()
+
+	if _, ok := env.Get("x"); ok {
+		t.Error("Expected x to be undefined in a new Env")
+	}
+
+	env.Set("x", Float(3))
+	env.Set("rate", Float(0.5))
+	env.Set("x", Float(4))
+
+	if v, ok := env.Get("x"); !ok || v != Float(4) {
+		t.Errorf("Expected x = 4, got %v (defined: %v)", v, ok)
+	}
+	if names := env.Names(); !reflect.DeepEqual(names, []string{"rate", "x"}) {
+		t.Errorf("Expected sorted names [rate x], got %v", names)
+	}
+}
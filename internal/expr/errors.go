@@ -0,0 +1,133 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is an error located in the source of an expression: a syntax error
+// from Tokenize or Parse, or an evaluation error attributed to the part of the
+// expression that caused it, such as the / of a division by zero. Use
+// errors.As to recover it from the error returned by Parse or Eval.
+type Error struct {
+	Offset int // byte offset of the problem in the source
+	// Span is the length in bytes of the offending text, 0 at the end of input
+	Span int
+	// Expected lists what the parser would have accepted instead, for syntax
+	// errors, e.g. "')'" or "number"
+	Expected []string
+	// Hint suggests a fix, or is empty
+	Hint string
+	Err  error
+}
+
+// Error returns the message of the underlying error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostic renders src, the line the error was found in, with carets under
+// the offending text, followed by the hint if there is one:
+//
+//	1 + 2 / 0
+//	      ^
+func (e *Error) Diagnostic(src string) string {
+	offset := max(0, min(e.Offset, len(src)))
+	end := max(offset, min(offset+e.Span, len(src)))
+
+	// Tabs are kept so that the carets line up however the terminal expands them
+	var pad strings.Builder
+	for _, r := range src[:offset] {
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	carets := max(1, utf8.RuneCountInString(src[offset:end]))
+
+	text := fmt.Sprintf("  %s\n  %s%s\n", src, pad.String(), strings.Repeat("^", carets))
+	if e.Hint != "" {
+		text += "Hint: " + e.Hint + "\n"
+	}
+	return text
+}
+
+// syntaxError returns an Error for the text of the given length at pos, whose
+// message ends with the position
+func syntaxError(pos, span int, format string, args ...interface{}) *Error {
+	return &Error{Offset: pos, Span: span, Err: fmt.Errorf(format+" at position %d", append(args, pos)...)}
+}
+
+// unexpected reports a token the parser cannot use where it stands
+func unexpected(tok Token, expected ...string) *Error {
+	err := syntaxError(tok.Pos, len(tok.Text), "unexpected %s", tok)
+	err.Expected = expected
+	return err
+}
+
+// expectedToken reports a token found in place of one of the expected ones,
+// naming them in the message
+func expectedToken(tok Token, expected ...string) *Error {
+	err := syntaxError(tok.Pos, len(tok.Text), "expected %s but found %s", orList(expected), tok)
+	err.Expected = expected
+	return err
+}
+
+// orList joins alternatives as in "a, b or c"
+func orList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
+
+// operandTokens describes the tokens that can start an operand, for errors
+var operandTokens = []string{"number", "identifier", "'('", "'['", "'-'"}
+
+// locate attributes an evaluation error to node unless an inner node has
+// already claimed it
+func locate(err error, node Node) error {
+	var located *Error
+	if errors.As(err, &located) {
+		return err
+	}
+	return &Error{Offset: node.Pos(), Span: nodeSpan(node), Err: err}
+}
+
+// nodeSpan returns the length of the text at a node's position that a located
+// error underlines: its name, literal, operator or keyword
+func nodeSpan(n Node) int {
+	switch n := n.(type) {
+	case *Number:
+		if n.Imag {
+			return len(n.Text) + 1
+		}
+		return len(n.Text)
+	case *Ident:
+		return len(n.Name)
+	case *Call:
+		return len(n.Name)
+	case *Assign:
+		return len(n.Name)
+	case *Unary:
+		return len(n.Op)
+	case *Binary:
+		return len(n.Op)
+	case *DateLit:
+		return len(n.Text)
+	case *DurationLit:
+		return len(n.Text)
+	case *Convert, *InZone, *AsPercent:
+		// to, in or as
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,109 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestSyntaxErrorLocation(t *testing.T) {
+	tests := []struct {
+		src      string
+		offset   int
+		span     int
+		expected []string
+		hint     string
+	}{
+		{"2 + * 3", 4, 1, operandTokens, `"*" needs an operand before it`},
+		{"5 /", 3, 0, operandTokens, "the expression is incomplete"},
+		{"(1 + 2", 6, 0, []string{"')'"}, `close the "(" at position 0`},
+		{"max(1, 2", 8, 0, []string{"','", "')'"}, `close the "(" at position 3`},
+		{"[1, 2 3]", 6, 1, []string{"','", "']'"}, ""},
+		{"1 + 2)", 5, 1, []string{"operator", "end of input"}, "this ')' has no matching '('"},
+		{"3 x", 2, 1, []string{"operator", "end of input"}, `"x" is not a known unit; write * to multiply`},
+		{"(1) (2)", 4, 1, []string{"operator", "end of input"}, "write an operator between the operands, such as * to multiply"},
+		{"5 km to parsec", 8, 6, nil, ""},
+		{"1 < 2", 2, 1, nil, "there are no comparison operators; << and >> shift bits"},
+		{"2 # 3", 2, 1, nil, ""},
+		{"$ + 1", 0, 1, nil, "refer to earlier results as $1, $2 and so on"},
+		{"0x", 0, 2, nil, ""},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.src)
+		var located *Error
+		if !errors.As(err, &located) {
+			t.Errorf("Parse(%q): expected an *Error, got %v", test.src, err)
+			continue
+		}
+		if located.Offset != test.offset || located.Span != test.span {
+			t.Errorf("Parse(%q): expected offset %d and span %d, got %d and %d", test.src, test.offset, test.span, located.Offset, located.Span)
+		}
+		if !reflect.DeepEqual(located.Expected, test.expected) {
+			t.Errorf("Parse(%q): expected %q to be expected, got %q", test.src, test.expected, located.Expected)
+		}
+		if located.Hint != test.hint {
+			t.Errorf("Parse(%q): expected hint %q, got %q", test.src, test.hint, located.Hint)
+		}
+	}
+}
+
+func TestEvalErrorLocation(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+	if _, err := e.Evaluate("f(x) = 1 / x"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		src    string
+		offset int
+		span   int
+	}{
+		{"1 + 2 / 0", 6, 1},
+		{"2 * missing", 4, 7},
+		{"sqrt(-4) + 1", 0, 4},
+		{"nosuch(1)", 0, 6},
+		{"10 mod 0", 3, 3},
+		{"5 km + 3 s", 5, 1},
+		{"5 km to kg", 5, 2},
+		// Errors in the body of a function are located at the call
+		{"1 + f(0)", 4, 1},
+	}
+	for _, test := range tests {
+		_, err := e.Evaluate(test.src)
+		var located *Error
+		if !errors.As(err, &located) {
+			t.Errorf("Evaluate(%q): expected an *Error, got %v", test.src, err)
+			continue
+		}
+		if located.Offset != test.offset || located.Span != test.span {
+			t.Errorf("Evaluate(%q): expected offset %d and span %d, got %d and %d", test.src, test.offset, test.span, located.Offset, located.Span)
+		}
+	}
+
+	_, err := e.Evaluate("1 / 0")
+	if inner := errors.Unwrap(err); inner == nil || inner.Error() != "division by zero" {
+		t.Errorf("Expected the located error to wrap the division by zero, got %v", inner)
+	}
+}
+
+func TestErrorDiagnostic(t *testing.T) {
+	tests := []struct {
+		err      *Error
+		src      string
+		expected string
+	}{
+		{&Error{Offset: 6, Span: 1}, "1 + 2 / 0", "  1 + 2 / 0\n        ^\n"},
+		{&Error{Offset: 0, Span: 4, Hint: "try again"}, "sqrt(-4)", "  sqrt(-4)\n  ^^^^\nHint: try again\n"},
+		{&Error{Offset: 3, Span: 0}, "5 /", "  5 /\n     ^\n"},
+		{&Error{Offset: 3, Span: 1}, "\t1\t/ 0", "  \t1\t/ 0\n  \t \t^\n"},
+		{&Error{Offset: 6, Span: 2}, "π × ab", "  π × ab\n      ^^\n"},
+		{&Error{Offset: 40, Span: 2}, "1 +", "  1 +\n     ^\n"},
+	}
+	for _, test := range tests {
+		if got := test.err.Diagnostic(test.src); got != test.expected {
+			t.Errorf("Diagnostic(%q): expected %q, got %q", test.src, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,880 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+// ifFunc is the conditional if(cond, then, else). It is handled by the evaluator
+// rather than the builtins table because only the selected branch is evaluated,
+// which is what allows recursive user functions to terminate.
+const ifFunc = "if"
+
+// Evaluator walks expression trees and computes their value using a Calculator,
+// resolving and assigning variables in its Env. When the Calculator has a non-zero
+// precision, literals and arithmetic use arbitrary precision BigFloat values; in
+// rational mode they use exact Rat fractions, in integer mode fixed-width Int values
+// and in complex mode Complex values.
+type Evaluator struct {
+	calc   *calculator.Calculator
+	env    *Env
+	frames []map[string]Value // parameter bindings of active user function calls
+	notes  []string           // diagnostics about the evaluation, such as how a root was found
+	// warnings are the IEEE-754 edge cases met in lenient mode, such as overflow
+	warnings []string
+	// sampling counts the numerical methods, such as solve, evaluating an
+	// expression at many points, where edge cases are expected and not checked
+	sampling int
+	// detached counts the trees being evaluated that are not part of the
+	// source, such as function bodies, whose errors are located at the call
+	detached int
+}
+
+// NewEvaluator creates an Evaluator backed by the given Calculator and symbol table.
+// A nil env gets a fresh, empty symbol table.
+func NewEvaluator(calc *calculator.Calculator, env *Env) *Evaluator {
+	if env == nil {
+		env = NewEnv()
+	}
+	return &Evaluator{calc: calc, env: env}
+}
+
+// Evaluate parses and evaluates src in a single step
+func (e *Evaluator) Evaluate(src string) (Value, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return e.Eval(node)
+}
+
+// Notes returns the diagnostics gathered while evaluating, such as the method
+// and number of iterations by which solve found a root
+func (e *Evaluator) Notes() []string {
+	return e.notes
+}
+
+// note records a diagnostic about the evaluation
+func (e *Evaluator) note(format string, args ...interface{}) {
+	e.notes = append(e.notes, fmt.Sprintf(format, args...))
+}
+
+// noteOnce records a diagnostic unless it was already recorded, as for a
+// function called at every step of a recursion
+func (e *Evaluator) noteOnce(format string, args ...interface{}) {
+	if note := fmt.Sprintf(format, args...); !slices.Contains(e.notes, note) {
+		e.notes = append(e.notes, note)
+	}
+}
+
+// precise reports whether arithmetic is carried out in arbitrary precision
+func (e *Evaluator) precise() bool {
+	return e.calc.NumberMode() == calculator.FloatMode && e.calc.Precision() > 0
+}
+
+// rational reports whether arithmetic is carried out in exact fractions
+func (e *Evaluator) rational() bool {
+	return e.calc.NumberMode() == calculator.RationalMode
+}
+
+// integer reports whether arithmetic is carried out in fixed-width integers
+func (e *Evaluator) integer() bool {
+	return e.calc.NumberMode() == calculator.IntegerMode
+}
+
+// complexMode reports whether arithmetic is carried out in complex numbers
+func (e *Evaluator) complexMode() bool {
+	return e.calc.NumberMode() == calculator.ComplexMode
+}
+
+// normalize converts stored values back to Float once the mode that produced
+// them has been switched off
+func (e *Evaluator) normalize(v Value) Value {
+	switch x := v.(type) {
+	case BigFloat:
+		if !e.precise() {
+			f, _ := x.X.Float64()
+			return Float(f)
+		}
+	case Rat:
+		// Integers too large for float64, such as 25!, stay exact
+		if !e.rational() && !(x.X.IsInt() && x.X.Num().CmpAbs(maxExactFloat) > 0) {
+			f, _ := x.X.Float64()
+			return Float(f)
+		}
+	case Int:
+		if !e.integer() {
+			f, _ := ToFloat(x)
+			return Float(f)
+		}
+	case Complex:
+		// Values with an imaginary part are kept and rejected where a real is needed
+		if !e.complexMode() && imag(x) == 0 {
+			return Float(real(x))
+		}
+	case Percent:
+		return Percent{e.normalize(x.X)}
+	case List:
+		values := make(List, len(x))
+		for i, elem := range x {
+			values[i] = e.normalize(elem)
+		}
+		return values
+	}
+	return v
+}
+
+// Eval computes the value of a parsed expression tree. Function definitions are
+// added to the Env and yield a nil Value. Errors are returned as an *Error
+// located at the innermost node of the source that failed.
+func (e *Evaluator) Eval(node Node) (Value, error) {
+	v, err := e.eval(node)
+	if err != nil && e.detached == 0 {
+		return nil, locate(err, node)
+	}
+	return v, err
+}
+
+// evalDetached evaluates a tree that is not part of the source, leaving its
+// errors for the caller to locate
+func (e *Evaluator) evalDetached(node Node) (Value, error) {
+	e.detached++
+	defer func() { e.detached-- }()
+	return e.Eval(node)
+}
+
+func (e *Evaluator) eval(node Node) (Value, error) {
+	switch n := node.(type) {
+	case *Number:
+		return e.evalNumber(n)
+	case *Ident:
+		v, err := e.lookup(n.Name)
+		if err != nil {
+			return nil, err
+		}
+		return e.normalize(v), nil
+	case *HistoryRef:
+		v, err := e.env.History().Get(n.Index)
+		if err != nil {
+			return nil, err
+		}
+		return e.normalize(v), nil
+	case *Assign:
+		v, err := e.Eval(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.env.Set(n.Name, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case *FuncDef:
+		return nil, e.env.Define(n.Func)
+	case *Unary:
+		return e.evalUnary(n)
+	case *Binary:
+		return e.evalBinary(n)
+	case *Call:
+		return e.evalCall(n)
+	case *Measure:
+		return e.evalMeasure(n)
+	case *Convert:
+		return e.evalConvert(n)
+	case *InZone:
+		return e.evalInZone(n)
+	case *DateLit:
+		t, err := parseDateTime(n.Text, e.env.Location(), e.env.Now())
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	case *DurationLit:
+		return durationResult(parseDuration(n.Text))
+	case *Factorial:
+		return e.evalFactorial(n)
+	case *Percentage:
+		x, err := e.Eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		if hasQuantity(x) || isTemporal(x) || isList(x) {
+			return nil, fmt.Errorf("cannot take a percentage of %s", x)
+		}
+		return Percent{x}, nil
+	case *AsPercent:
+		return e.evalAsPercent(n)
+	case *ListLit:
+		return e.evalList(n)
+	default:
+		return nil, fmt.Errorf("unsupported expression node %T", node)
+	}
+}
+
+func (e *Evaluator) evalNumber(n *Number) (Value, error) {
+	if n.Imag && !e.complexMode() {
+		return nil, fmt.Errorf("imaginary number %s requires complex mode", n)
+	}
+	if e.complexMode() {
+		if n.Imag {
+			return Complex(complex(0, n.Value)), nil
+		}
+		return Complex(complex(n.Value, 0)), nil
+	}
+	text := n.Text
+	if text == "" {
+		text = Float(n.Value).String()
+	}
+	if e.integer() {
+		x, err := e.calc.ParseInt(text)
+		if err != nil {
+			return nil, err
+		}
+		return Int{x}, nil
+	}
+	if e.rational() {
+		x, err := e.calc.ParseRat(text)
+		if err != nil {
+			return nil, err
+		}
+		return Rat{x}, nil
+	}
+	if !e.precise() {
+		if e.sampling > 0 {
+			return Float(n.Value), nil
+		}
+		return floatResult(e.checkResult(e.calc.DiagnoseLiteral(text, n.Value)))
+	}
+	// Parse the literal text so that decimals such as 0.1 are exact to the precision
+	if n.Text != "" {
+		x, err := e.calc.ParseBig(n.Text)
+		if err != nil {
+			return nil, err
+		}
+		return BigFloat{x}, nil
+	}
+	x, err := e.calc.BigFromFloat(n.Value)
+	if err != nil {
+		return nil, err
+	}
+	return BigFloat{x}, nil
+}
+
+// lookup resolves a name against the previous result, the parameters of the
+// innermost function call, the built-in constants, the session variables, the
+// current time as now and today and the units of measure, so that km on its own
+// is 1 km. In complex mode an otherwise undefined i is the imaginary unit.
+// Constants come before units, so a bare h is the Planck constant and hbar the
+// reduced one; after a number or to, as in 3 h or 3 hbar, they are units.
+func (e *Evaluator) lookup(name string) (Value, error) {
+	if isReserved(name) {
+		return e.env.History().Last()
+	}
+	if len(e.frames) > 0 {
+		if v, ok := e.frames[len(e.frames)-1][name]; ok {
+			return v, nil
+		}
+	}
+	if c, ok := constants[name]; ok {
+		return e.constantValue(c)
+	}
+	if v, ok := e.env.Get(name); ok {
+		return v, nil
+	}
+	switch name {
+	case "now":
+		return Time{T: e.env.Now()}, nil
+	case "today":
+		year, month, dayOfMonth := e.env.Now().Date()
+		return Time{T: time.Date(year, month, dayOfMonth, 0, 0, 0, 0, e.env.Location()), DateOnly: true}, nil
+	}
+	if u, ok := units.Lookup(name); ok {
+		return Quantity{Value: 1, Unit: u}, nil
+	}
+	if name == "i" && e.complexMode() {
+		return Complex(1i), nil
+	}
+	return nil, fmt.Errorf("undefined identifier %q", name)
+}
+
+// constantValue returns a constant at the working precision, or as a quantity
+// in its SI unit for a physical constant
+func (e *Evaluator) constantValue(c Constant) (Value, error) {
+	if c.Unit != "" {
+		u, err := units.Parse(c.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("constant %s: %w", c.Name, err)
+		}
+		return Quantity{Value: c.Value, Unit: u}, nil
+	}
+	if !e.precise() {
+		return Float(c.Value), nil
+	}
+	if compute, ok := bigConstants[c.Name]; ok {
+		return BigFloat{compute(e.calc)}, nil
+	}
+	x, err := e.calc.BigFromFloat(c.Value)
+	if err != nil {
+		return nil, err
+	}
+	return BigFloat{x}, nil
+}
+
+func (e *Evaluator) evalUnary(n *Unary) (Value, error) {
+	x, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	switch n.Op {
+	case "-":
+		return e.negate(x)
+	case "+":
+		return x, nil
+	case "~":
+		a, err := toInt(x)
+		if err != nil {
+			return nil, err
+		}
+		return e.intValue("~"+a.String(), e.calc.Not(a))
+	default:
+		return nil, fmt.Errorf("unsupported unary operator: %s", n.Op)
+	}
+}
+
+// negate changes the sign of x, keeping its unit, duration or percent sign
+func (e *Evaluator) negate(x Value) (Value, error) {
+	switch v := x.(type) {
+	case Quantity:
+		return Quantity{Value: -v.Value, Unit: v.Unit}, nil
+	case Duration:
+		return -v, nil
+	case Percent:
+		neg, err := e.negate(v.X)
+		if err != nil {
+			return nil, err
+		}
+		return Percent{neg}, nil
+	case List:
+		neg := make(List, len(v))
+		for i, elem := range v {
+			x, err := e.negate(elem)
+			if err != nil {
+				return nil, err
+			}
+			neg[i] = x
+		}
+		return neg, nil
+	}
+	// Subtract from a zero of the same kind so that fractions and integers stay exact
+	var zero Value = Float(0)
+	switch x.(type) {
+	case Rat:
+		zero = Rat{new(big.Rat)}
+	case Int:
+		zero = Int{new(big.Int)}
+	}
+	return e.arith("-", zero, x)
+}
+
+func (e *Evaluator) evalBinary(n *Binary) (Value, error) {
+	a, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	b, err := e.Eval(n.Y)
+	if err != nil {
+		return nil, err
+	}
+	return e.arith(n.Op, a, b)
+}
+
+// arith applies a binary arithmetic operator in float64, in arbitrary precision
+// in precision mode, exactly in rational mode when both operands are fractions, or
+// in fixed-width integers in integer mode when both operands are integers.
+// Other operands are passed on: lists to listArith, element by element;
+// percentages to percentArith; dates, times and durations to timeArith; and
+// operands with units to quantityArith. The keyword mod is the remainder and of
+// multiplies, as in 20% of 80.
+func (e *Evaluator) arith(op string, x, y Value) (Value, error) {
+	switch op {
+	case "mod":
+		op = "%"
+	case "of":
+		op = "*"
+		// 20% of 80 is a share of 80 rather than a percentage scaled by 80
+		if p, ok := x.(Percent); ok {
+			share, err := e.fraction(p)
+			if err != nil {
+				return nil, err
+			}
+			x = share
+		}
+	}
+	if op == "@" || isList(x) || isList(y) {
+		return e.listArith(op, x, y)
+	}
+	if isPercent(x) || isPercent(y) {
+		return e.percentArith(op, x, y)
+	}
+	if isBitwise(op) {
+		return e.bitwise(op, x, y)
+	}
+	if isTemporal(x) || isTemporal(y) {
+		return e.timeArith(op, x, y)
+	}
+	if hasQuantity(x, y) {
+		return e.quantityArith(op, x, y)
+	}
+	if e.complexMode() {
+		return e.complexArith(op, x, y)
+	}
+	if a, ok := x.(Int); ok && e.integer() {
+		if b, ok := y.(Int); ok {
+			return e.intArith(op, a.X, b.X)
+		}
+	}
+	if e.precise() {
+		return e.bigArith(op, x, y)
+	}
+	if a, ok := x.(Rat); ok && e.rational() {
+		if b, ok := y.(Rat); ok {
+			// Powers with a fractional exponent are generally irrational, so they
+			// fall through to float64 like any other inexact operand
+			if op != "^" || b.X.IsInt() {
+				return e.ratArith(op, a.X, b.X)
+			}
+		}
+	}
+
+	a, err := e.toFloat(x)
+	if err != nil {
+		return nil, err
+	}
+	b, err := e.toFloat(y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+":
+		return e.checkFloat(op, e.calc.Add(a, b), a, b)
+	case "-":
+		return e.checkFloat(op, e.calc.Subtract(a, b), a, b)
+	case "*":
+		return e.checkFloat(op, e.calc.Multiply(a, b), a, b)
+	case "/":
+		r, err := e.calc.Divide(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return e.checkFloat(op, r, a, b)
+	case "^":
+		return e.checkFloat(op, e.calc.Power(a, b), a, b)
+	case "%":
+		r, err := e.calc.ModFloat(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return e.checkFloat(op, r, a, b)
+	case "//":
+		r, err := e.calc.Quotient(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return e.checkFloat(op, r, a, b)
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
+
+func (e *Evaluator) bigArith(op string, x, y Value) (Value, error) {
+	a, err := toBig(e.calc, x)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toBig(e.calc, y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+":
+		return bigResult(e.calc.BigAdd(a, b))
+	case "-":
+		return bigResult(e.calc.BigSubtract(a, b))
+	case "*":
+		return bigResult(e.calc.BigMultiply(a, b))
+	case "/":
+		return bigResult(e.calc.BigDivide(a, b))
+	case "^":
+		return bigResult(e.calc.BigPower(a, b))
+	case "%":
+		return bigResult(e.calc.BigModFloat(a, b))
+	case "//":
+		return bigResult(e.calc.BigQuotient(a, b))
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
+
+func (e *Evaluator) ratArith(op string, a, b *big.Rat) (Value, error) {
+	switch op {
+	case "+":
+		return Rat{e.calc.RatAdd(a, b)}, nil
+	case "-":
+		return Rat{e.calc.RatSubtract(a, b)}, nil
+	case "*":
+		return Rat{e.calc.RatMultiply(a, b)}, nil
+	case "/":
+		return ratResult(e.calc.RatDivide(a, b))
+	case "^":
+		return ratResult(e.calc.RatPower(a, b))
+	case "%":
+		return ratResult(e.calc.RatMod(a, b))
+	case "//":
+		return ratResult(e.calc.RatQuotient(a, b))
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
+
+// complexArith applies an arithmetic operator in complex128; % and // are only
+// defined for real operands
+func (e *Evaluator) complexArith(op string, x, y Value) (Value, error) {
+	a, err := toComplex(x)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toComplex(y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+":
+		return Complex(e.calc.CAdd(a, b)), nil
+	case "-":
+		return Complex(e.calc.CSubtract(a, b)), nil
+	case "*":
+		return Complex(e.calc.CMultiply(a, b)), nil
+	case "/":
+		return complexResult(e.calc.CDivide(a, b))
+	case "^":
+		return complexResult(e.calc.CPower(a, b))
+	case "%", "//":
+		if imag(a) != 0 || imag(b) != 0 {
+			return nil, fmt.Errorf("operator %s is not defined for complex numbers", op)
+		}
+		if op == "%" {
+			return complexResult(realResult(e.calc.ModFloat(real(a), real(b))))
+		}
+		return complexResult(realResult(e.calc.Quotient(real(a), real(b))))
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
+
+// realResult lifts the result of a fallible float64 Calculator method to complex128
+func realResult(f float64, err error) (complex128, error) {
+	return complex(f, 0), err
+}
+
+// complexResult wraps the result of a fallible complex128 Calculator method
+func complexResult(z complex128, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Complex(z), nil
+}
+
+// intArith applies an arithmetic operator to fixed-width integers; / and //
+// both truncate toward zero
+func (e *Evaluator) intArith(op string, a, b *big.Int) (Value, error) {
+	switch op {
+	case "+":
+		return intResult(e.calc.IntAdd(a, b))
+	case "-":
+		return intResult(e.calc.IntSubtract(a, b))
+	case "*":
+		return intResult(e.calc.IntMultiply(a, b))
+	case "/", "//":
+		return intResult(e.calc.IntDivide(a, b))
+	case "^":
+		return intResult(e.calc.IntPower(a, b))
+	case "%":
+		return intResult(e.calc.IntMod(a, b))
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
+
+// isBitwise reports whether op is one of the binary bitwise operators
+func isBitwise(op string) bool {
+	switch op {
+	case "&", "|", "xor", "<<", ">>":
+		return true
+	}
+	return false
+}
+
+// bitwise applies a bitwise operator in the calculator's word size. Outside
+// integer mode the operands must still be whole numbers and the result is a Float.
+func (e *Evaluator) bitwise(op string, x, y Value) (Value, error) {
+	a, err := toInt(x)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toInt(y)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *big.Int
+	switch op {
+	case "&":
+		r = e.calc.And(a, b)
+	case "|":
+		r = e.calc.Or(a, b)
+	case "xor":
+		r = e.calc.Xor(a, b)
+	case "<<":
+		r, err = e.calc.ShiftLeft(a, b)
+	case ">>":
+		r, err = e.calc.ShiftRight(a, b)
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e.intValue(a.String()+" "+op+" "+b.String(), r)
+}
+
+// intValue wraps the integer result of op as an Int in integer mode and as a
+// Float otherwise, which may overflow or be rounded
+func (e *Evaluator) intValue(op string, x *big.Int) (Value, error) {
+	if e.integer() {
+		return Int{x}, nil
+	}
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return e.checkExact(op, new(big.Rat).SetInt(x), f)
+}
+
+// intResult wraps the result of a fallible big.Int Calculator method
+func intResult(x *big.Int, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Int{x}, nil
+}
+
+// ratResult wraps the result of a fallible big.Rat Calculator method
+func ratResult(x *big.Rat, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Rat{x}, nil
+}
+
+// floatResult wraps the result of a fallible float64 Calculator method
+func floatResult(f float64, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Float(f), nil
+}
+
+// bigResult wraps the result of a fallible big.Float Calculator method
+func bigResult(x *big.Float, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return BigFloat{x}, nil
+}
+
+func (e *Evaluator) evalCall(n *Call) (Value, error) {
+	switch strings.ToLower(n.Name) {
+	case ifFunc:
+		return e.evalIf(n)
+	case pctChangeFunc:
+		return e.evalPctChange(n)
+	case solveFunc:
+		return e.evalSolve(n)
+	case rootsFunc:
+		return e.evalRoots(n)
+	case integrateFunc:
+		return e.evalIntegrate(n)
+	case derivFunc:
+		return e.evalDeriv(n)
+	case diffFunc:
+		return e.evalDiff(n)
+	case simplifyFunc:
+		return e.evalSimplify(n)
+	}
+	if fn, ok := e.env.Func(n.Name); ok {
+		return e.callFunction(fn, n)
+	}
+
+	name := strings.ToLower(n.Name)
+	if fn, ok := matrixFuncs[name]; ok {
+		return e.callMatrix(n.Name, fn, n.Args)
+	}
+	if fn, ok := intFuncs[name]; ok {
+		return e.callInt(n.Name, fn, n.Args)
+	}
+	fn, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", n.Name)
+	}
+	if err := fn.checkArity(n.Name, len(n.Args)); err != nil {
+		return nil, err
+	}
+	args, err := e.evalArgs(n.Args)
+	if err != nil {
+		return nil, err
+	}
+	if name == "percentile" {
+		// percentile(xs, 50%) is the median rather than the 0.5th percentile
+		if p, ok := args[len(args)-1].(Percent); ok {
+			args[len(args)-1] = p.X
+		}
+	}
+	if fn.spread {
+		args = spread(args)
+	}
+	if hasQuantity(args...) {
+		return e.callQuantity(name, fn, args)
+	}
+	return e.callBuiltin(name, fn, args)
+}
+
+// callBuiltin invokes the implementation of a builtin that suits its arguments.
+// The complex implementation serves complex mode and complex arguments, and the
+// arbitrary precision one serves precision mode; a builtin without the one that
+// applies, and any other call, uses the float64 implementation.
+func (e *Evaluator) callBuiltin(name string, fn builtin, args []Value) (Value, error) {
+	if fn.cplx != nil && (e.complexMode() || hasComplex(args)) {
+		// Real arguments use the real implementation where it is defined, which
+		// keeps results such as sin(180) in degree mode exact
+		if v, err := e.callReal(name, fn, args); err == nil {
+			return Complex(complex(float64(v.(Float)), 0)), nil
+		}
+		complexArgs := make([]complex128, len(args))
+		for i, arg := range args {
+			z, err := toComplex(arg)
+			if err != nil {
+				return nil, err
+			}
+			complexArgs[i] = z
+		}
+		return complexResult(fn.cplx(e.calc, complexArgs))
+	}
+	if e.precise() && fn.big != nil {
+		bigArgs := make([]*big.Float, len(args))
+		for i, arg := range args {
+			x, err := toBig(e.calc, arg)
+			if err != nil {
+				return nil, err
+			}
+			bigArgs[i] = x
+		}
+		return bigResult(fn.big(e.calc, bigArgs))
+	}
+	if e.precise() {
+		e.noteOnce("%s has no arbitrary precision implementation, so it is computed in float64", name)
+	}
+	return e.callReal(name, fn, args)
+}
+
+// hasComplex reports whether any of args has an imaginary part, which can happen
+// outside complex mode when a variable was assigned in it
+func hasComplex(args []Value) bool {
+	for _, arg := range args {
+		if z, ok := arg.(Complex); ok && imag(z) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// callReal invokes the float64 implementation of a builtin, checking its
+// result for IEEE-754 edge cases
+func (e *Evaluator) callReal(name string, fn builtin, args []Value) (Value, error) {
+	floatArgs := make([]float64, len(args))
+	for i, arg := range args {
+		f, err := e.toFloat(arg)
+		if err != nil {
+			return nil, err
+		}
+		floatArgs[i] = f
+	}
+	r, err := fn.fn(e.calc, floatArgs)
+	if err != nil {
+		return nil, err
+	}
+	return e.checkFloat(name, r, floatArgs...)
+}
+
+func (e *Evaluator) evalArgs(nodes []Node) ([]Value, error) {
+	args := make([]Value, len(nodes))
+	for i, arg := range nodes {
+		v, err := e.Eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// evalIf evaluates the condition and then only the selected branch; any non-zero
+// condition is true
+func (e *Evaluator) evalIf(n *Call) (Value, error) {
+	if len(n.Args) != 3 {
+		return nil, fmt.Errorf("%s expects 3 argument(s), got %d", n.Name, len(n.Args))
+	}
+	cond, err := e.Eval(n.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	truthy, err := isTruthy(cond)
+	if err != nil {
+		return nil, err
+	}
+	if truthy {
+		return e.Eval(n.Args[1])
+	}
+	return e.Eval(n.Args[2])
+}
+
+// callFunction evaluates the arguments in the caller's scope and then the body of
+// fn with its parameters bound, enforcing the Env's maximum call depth. The body
+// is walked rather than compiled: a call runs it once, which costs about what
+// compiling would, and a program reads variables when it is compiled, so one
+// kept with fn would miss later assignments.
+func (e *Evaluator) callFunction(fn *Function, n *Call) (Value, error) {
+	if len(n.Args) != len(fn.Params) {
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d", fn.Signature(), len(fn.Params), len(n.Args))
+	}
+	if len(e.frames) >= e.env.MaxDepth() {
+		return nil, fmt.Errorf("maximum call depth %d exceeded in %s", e.env.MaxDepth(), fn.Name)
+	}
+	args, err := e.evalArgs(n.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make(map[string]Value, len(fn.Params))
+	for i, param := range fn.Params {
+		frame[param] = args[i]
+	}
+	e.frames = append(e.frames, frame)
+	defer func() { e.frames = e.frames[:len(e.frames)-1] }()
+
+	return e.evalDetached(fn.Body)
+}
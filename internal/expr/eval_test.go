@@ -0,0 +1,279 @@
+package expr
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// evaluateFloat evaluates src and converts the result to float64
+func evaluateFloat(e *Evaluator, src string) (float64, error) {
+	v, err := e.Evaluate(src)
+	if err != nil {
+		return 0, err
+	}
+	return ToFloat(v)
+}
+
+func TestEvaluate(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"3 + 4 * 2", 11},
+		{"(1+2)/3", 1},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"(-2) ^ 2", 4},
+		{"2 ^ -1", 0.5},
+		{"-(3 - 5)", 2},
+		{"10 % 4 * 2", 4},
+		{"SQRT(16) + sqrt(9)", 7},
+		{"sqrt((3 + 1) * 4) / -2", -2},
+		{"1.5e2 - 50", 100},
+	}
+
+	for _, test := range tests {
+		result, err := evaluateFloat(e, test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if math.Abs(result-test.expected) > 1e-9 {
+			t.Errorf("Evaluate(%q): expected %v, got %v", test.src, test.expected, result)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []string{
+		"1 / 0",
+		"5 % (2 - 2)",
+		"sqrt(-4)",
+		"sqrt(1, 2)",
+		"nosuch(1)",
+		"x + 1",
+	}
+
+	for _, src := range tests {
+		if _, err := evaluateFloat(e, src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestEvaluateAssignment(t *testing.T) {
+	env := NewEnv()
+	e := NewEvaluator(calculator.New(), env)
+
+	if v, err := evaluateFloat(e, "rate = 0.07"); err != nil || v != 0.07 {
+		t.Fatalf("Expected assignment to return 0.07, got %v (err: %v)", v, err)
+	}
+	if v, err := evaluateFloat(e, "total = 1200 * (1 + rate)"); err != nil || math.Abs(v-1284) > 1e-9 {
+		t.Fatalf("Expected total = 1284, got %v (err: %v)", v, err)
+	}
+	if v, ok := env.Get("total"); !ok || math.Abs(float64(v.(Float))-1284) > 1e-9 {
+		t.Errorf("Expected total to be stored in env, got %v (defined: %v)", v, ok)
+	}
+
+	// A failed assignment must not bind the name
+	if _, err := evaluateFloat(e, "bad = 1 / 0"); err == nil {
+		t.Error("Expected division by zero error")
+	}
+	if _, ok := env.Get("bad"); ok {
+		t.Error("Expected failed assignment to leave bad undefined")
+	}
+
+	if _, err := evaluateFloat(e, "undefined_name * 2"); err == nil {
+		t.Error("Expected undefined identifier error")
+	}
+}
+
+func TestEvaluateHistoryReferences(t *testing.T) {
+	env := NewEnv()
+	e := NewEvaluator(calculator.New(), env)
+
+	if _, err := evaluateFloat(e, "ans + 1"); err == nil {
+		t.Error("Expected error referencing ans with empty history")
+	}
+
+	env.History().Add("3 + 4", Float(7))
+	env.History().Add("2 * 5", Float(10))
+
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"ans", 10},
+		{"_ * 2", 20},
+		{"$1 + $2", 17},
+		{"sqrt($2 - 1)", 3},
+	}
+	for _, test := range tests {
+		result, err := evaluateFloat(e, test.src)
+		if err != nil || result != test.expected {
+			t.Errorf("Evaluate(%q): expected %v, got %v (err: %v)", test.src, test.expected, result, err)
+		}
+	}
+
+	for _, src := range []string{"$3", "$0", "ans = 5", "_ = 1"} {
+		if _, err := evaluateFloat(e, src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestEvaluateUserFunctions(t *testing.T) {
+	env := NewEnv()
+	e := NewEvaluator(calculator.New(), env)
+
+	definitions := []string{
+		"f(x, y) = x^2 + y",
+		"capacity(cores, util) = cores * util / 100",
+		"fact(n) = if(n, n * fact(n - 1), 1)",
+		"zero() = 0",
+		"shadow(rate) = rate * 2",
+	}
+	for _, src := range definitions {
+		if _, err := e.Evaluate(src); err != nil {
+			t.Fatalf("Failed to define %q: %v", src, err)
+		}
+	}
+	env.Set("rate", Float(100))
+
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"f(3, 1)", 10},
+		{"f(f(1, 1), 0) + 1", 5},
+		{"capacity(16, 75)", 12},
+		{"fact(5)", 120},
+		{"zero() + 1", 1},
+		{"shadow(3)", 6},
+		{"rate", 100},
+	}
+	for _, test := range tests {
+		result, err := evaluateFloat(e, test.src)
+		if err != nil || math.Abs(result-test.expected) > 1e-9 {
+			t.Errorf("Evaluate(%q): expected %v, got %v (err: %v)", test.src, test.expected, result, err)
+		}
+	}
+}
+
+func TestEvaluateUserFunctionErrors(t *testing.T) {
+	env := NewEnv()
+	e := NewEvaluator(calculator.New(), env)
+
+	if _, err := e.Evaluate("loop(x) = loop(x + 1)"); err != nil {
+		t.Fatalf("Failed to define loop: %v", err)
+	}
+	if _, err := e.Evaluate("g(x) = x + y"); err != nil {
+		t.Fatalf("Failed to define g: %v", err)
+	}
+
+	if err := env.SetMaxDepth(10); err != nil {
+		t.Fatalf("SetMaxDepth failed: %v", err)
+	}
+	if _, err := evaluateFloat(e, "loop(1)"); err == nil || !strings.Contains(err.Error(), "maximum call depth 10") {
+		t.Errorf("Expected maximum call depth error, got %v", err)
+	}
+	// The evaluator must unwind its frames after a failed call
+	if len(e.frames) != 0 {
+		t.Errorf("Expected no active frames after error, got %d", len(e.frames))
+	}
+
+	for _, src := range []string{
+		"g(1)",             // y is undefined
+		"g(1, 2)",          // wrong arity
+		"sqrt(x) = x",      // built-in name
+		"ans(x) = x",       // reserved name
+		"h(x, x) = x",      // duplicate parameter
+		"h(_) = 1",         // reserved parameter
+		"if(1, 2)",         // wrong arity for if
+		"if(1 / 0, 1, 2)",  // error in condition
+		"undefinedfunc(1)", // unknown function
+	} {
+		if _, err := evaluateFloat(e, src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+
+	if err := env.SetMaxDepth(0); err == nil {
+		t.Error("Expected error for max depth of 0")
+	}
+	if err := env.SetMaxDepth(100000000); err == nil {
+		t.Error("Expected error for a max depth beyond MaxCallDepth")
+	}
+
+	// The deepest allowed recursion fails cleanly instead of overflowing the stack
+	if err := env.SetMaxDepth(MaxCallDepth); err != nil {
+		t.Fatalf("SetMaxDepth(MaxCallDepth) failed: %v", err)
+	}
+	if _, err := e.Evaluate("loop(1)"); err == nil || !strings.Contains(err.Error(), "maximum call depth") {
+		t.Errorf("Expected maximum call depth error at MaxCallDepth, got %v", err)
+	}
+}
+
+func TestEvaluateIEEE(t *testing.T) {
+	calc := calculator.New()
+
+	warnings := []struct {
+		src      string
+		expected []string
+	}{
+		{"0 ^ -1", []string{"0 ^ -1 divides by zero, giving +Inf"}},
+		{"exp(1000) - exp(1000)", []string{"exp(1000) overflows to +Inf", "+Inf - +Inf is NaN"}},
+		{"9007199254740993 - 1", []string{"9007199254740993 is rounded to 9007199254740992: integers above 2^53 are not exact in float64"}},
+		{"1e-200 * 1e-200 + 1", []string{"1e-200 * 1e-200 underflows to 0"}},
+		{"3 * 0.1 - 0.3", []string{"0.30000000000000004 - 0.3 loses 52 of 53 significant bits to cancellation"}},
+		{"sqrt(2) * 2 + 1", nil},
+		{"solve(1/x - 2, x, 1)", nil},
+	}
+	for _, test := range warnings {
+		e := NewEvaluator(calc, nil)
+		if _, err := e.Evaluate(test.src); err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if got := e.Warnings(); strings.Join(got, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Evaluate(%q): expected warnings %q, got %q", test.src, test.expected, got)
+		}
+	}
+
+	calc.SetIEEEMode(calculator.IEEEStrict)
+	e := NewEvaluator(calc, nil)
+	errors := []struct {
+		src     string
+		message string
+	}{
+		{"1 + 0 ^ -1", "0 ^ -1 divides by zero"},
+		{"(-8) ^ (1/3)", "a negative number has no real fractional power"},
+		{"2 ^ 64 + 1", "integers above 2^53 are not exact"},
+		{"171.5!", "171.5! overflows to +Inf"},
+		{"nCr(2000, 1000) / 2", "overflows to +Inf"},
+		{"1e309", "1e309 overflows to +Inf"},
+		{"1e-400", "1e-400 underflows to 0"},
+		{"1e308 m * 10", "1e+308 * 10 overflows to +Inf"},
+		{"1e308 km to mm", "1e+308 km to mm overflows to +Inf"},
+		{"1e200 m * 1e200 m", "overflows to +Inf"},
+		{"det([[1e200, 0], [0, 1e200]])", "det overflows to +Inf"},
+		{"[[1e200]] @ [[1e200]]", "matrix product overflows to +Inf"},
+		{"sum([1e308, 1e308])", "sum(1e+308, 1e+308) overflows to +Inf"},
+	}
+	for _, test := range errors {
+		_, err := e.Evaluate(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Evaluate(%q): expected error containing %q, got %v", test.src, test.message, err)
+		}
+	}
+	if _, err := e.Evaluate("integrate(1/sqrt(x), x, 0, 1)"); err != nil {
+		t.Errorf("integrate in strict mode: expected the samples to go unchecked, got %v", err)
+	}
+}
@@ -0,0 +1,451 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// Notation selects how a Format lays out decimal numbers
+type Notation int
+
+const (
+	// NotationAuto uses each value's own String method
+	NotationAuto Notation = iota
+	// NotationFixed prints a fixed number of decimal places
+	NotationFixed
+	// NotationScientific prints one digit before the point and a power of ten
+	NotationScientific
+	// NotationEngineering prints a power of ten that is a multiple of three
+	NotationEngineering
+	// NotationSignificant rounds to a number of significant figures
+	NotationSignificant
+)
+
+// Format describes how results are displayed. The zero value displays values
+// unchanged.
+type Format struct {
+	Radix    int // 2 to 36, or 0 for decimal
+	Notation Notation
+	Digits   int  // decimal places for NotationFixed, significant figures otherwise; 0 for as many as needed
+	Group    bool // separate thousands with commas, or radix digits with underscores
+	Polar    bool // show complex numbers as modulus∠argument
+}
+
+// String describes the format in the syntax accepted by ParseFormat
+func (f Format) String() string {
+	var parts []string
+	switch f.Radix {
+	case 0:
+	case 2:
+		parts = append(parts, "bin")
+	case 8:
+		parts = append(parts, "oct")
+	case 16:
+		parts = append(parts, "hex")
+	default:
+		parts = append(parts, fmt.Sprintf("base %d", f.Radix))
+	}
+	switch f.Notation {
+	case NotationFixed:
+		parts = append(parts, fmt.Sprintf("fix %d", f.Digits))
+	case NotationScientific:
+		parts = append(parts, withDigits("sci", f.Digits))
+	case NotationEngineering:
+		parts = append(parts, withDigits("eng", f.Digits))
+	case NotationSignificant:
+		parts = append(parts, fmt.Sprintf("sig %d", f.Digits))
+	}
+	if f.Group {
+		parts = append(parts, "group")
+	}
+	if f.Polar {
+		parts = append(parts, "polar")
+	}
+	if len(parts) == 0 {
+		return "auto"
+	}
+	return strings.Join(parts, " ")
+}
+
+// withDigits appends an optional digit count to a notation name
+func withDigits(name string, digits int) string {
+	if digits == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s %d", name, digits)
+}
+
+// ParseFormat applies the settings named in fields to f and returns the result.
+// Fields are hex, oct, bin, dec, base N, fix N, sci [N], eng [N], sig N, group,
+// nogroup, polar, rect and auto, which restores the default. A radix and a notation exclude
+// each other, so the one named last wins.
+func ParseFormat(f Format, fields []string) (Format, error) {
+	if len(fields) == 0 {
+		return f, fmt.Errorf("missing display format")
+	}
+	for i := 0; i < len(fields); i++ {
+		// count consumes the following field as a digit count when it is a number
+		count := func(required bool, min int) (int, error) {
+			if i+1 < len(fields) {
+				if n, err := strconv.Atoi(fields[i+1]); err == nil {
+					i++
+					if n < min {
+						return 0, fmt.Errorf("%s needs a count of at least %d, got %d", fields[i-1], min, n)
+					}
+					return n, nil
+				}
+			}
+			if required {
+				return 0, fmt.Errorf("%s needs a digit count", fields[i])
+			}
+			return 0, nil
+		}
+
+		var err error
+		switch name := strings.ToLower(fields[i]); name {
+		case "auto", "default", "reset":
+			f = Format{}
+		case "dec", "decimal":
+			f.Radix, f.Notation, f.Digits = 0, NotationAuto, 0
+		case "hex":
+			err = f.setRadix(16)
+		case "oct":
+			err = f.setRadix(8)
+		case "bin":
+			err = f.setRadix(2)
+		case "base":
+			var radix int
+			if radix, err = count(true, 2); err == nil {
+				err = f.setRadix(radix)
+			}
+		case "fix", "fixed":
+			f.Radix, f.Notation = 0, NotationFixed
+			f.Digits, err = count(true, 0)
+		case "sci", "scientific":
+			f.Radix, f.Notation = 0, NotationScientific
+			f.Digits, err = count(false, 1)
+		case "eng", "engineering":
+			f.Radix, f.Notation = 0, NotationEngineering
+			f.Digits, err = count(false, 1)
+		case "sig":
+			f.Radix, f.Notation = 0, NotationSignificant
+			f.Digits, err = count(true, 1)
+		case "group", "grouped":
+			f.Group = true
+		case "nogroup":
+			f.Group = false
+		case "polar":
+			f.Polar = true
+		case "rect", "rectangular":
+			f.Polar = false
+		default:
+			// Accept the compact form base16
+			radix, convErr := strconv.Atoi(strings.TrimPrefix(name, "base"))
+			if !strings.HasPrefix(name, "base") || convErr != nil {
+				return f, fmt.Errorf("unknown display format %q", fields[i])
+			}
+			err = f.setRadix(radix)
+		}
+		if err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// setRadix selects the output base, treating base 10 as decimal, and drops any notation
+func (f *Format) setRadix(radix int) error {
+	if radix < 2 || radix > 36 {
+		return fmt.Errorf("base must be 2 to 36, got %d", radix)
+	}
+	if radix == 10 {
+		radix = 0
+	}
+	f.Radix, f.Notation, f.Digits = radix, NotationAuto, 0
+	return nil
+}
+
+// formatNames are the words that start a display format, besides the compact
+// form base16
+var formatNames = map[string]bool{
+	"auto": true, "default": true, "reset": true, "dec": true, "decimal": true,
+	"hex": true, "oct": true, "bin": true, "base": true, "fix": true, "fixed": true,
+	"sci": true, "scientific": true, "eng": true, "engineering": true, "sig": true,
+	"group": true, "grouped": true, "nogroup": true, "polar": true, "rect": true,
+	"rectangular": true,
+}
+
+// isFormatName reports whether word starts a display format
+func isFormatName(word string) bool {
+	word = strings.ToLower(word)
+	if formatNames[word] {
+		return true
+	}
+	_, err := strconv.Atoi(strings.TrimPrefix(word, "base"))
+	return strings.HasPrefix(word, "base") && err == nil
+}
+
+// SplitFormat separates a trailing display suffix such as "to hex" or "to fix 2"
+// from an expression. It returns the expression, the format obtained by applying
+// the suffix to base, and whether a suffix was found. Text after "to" that is not
+// a display format is left in place, but a suffix that starts like one and is
+// invalid, such as "to base 37", is an error.
+func SplitFormat(src string, base Format) (string, Format, bool, error) {
+	lower := strings.ToLower(src)
+	for end := len(lower); end > 0; {
+		i := strings.LastIndex(lower[:end], " to ")
+		if i < 0 {
+			break
+		}
+		fields := strings.Fields(src[i+4:])
+		f, err := ParseFormat(base, fields)
+		if err == nil {
+			return strings.TrimSpace(src[:i]), f, true, nil
+		}
+		if len(fields) > 0 && isFormatName(fields[0]) {
+			return strings.TrimSpace(src[:i]), base, true, err
+		}
+		end = i
+	}
+	return src, base, false, nil
+}
+
+// Render formats v for display. Values that are not numbers, and infinities and
+// NaN, are shown unchanged. A radix other than decimal needs a whole number; in
+// integer mode negative integers are shown as their two's complement bit pattern
+// in the calculator's word size. Complex numbers apply the format to each part,
+// and in polar form show the argument in the calculator's angle mode. Quantities
+// apply it to their magnitude, percentages to their number of percent and
+// lists to each element.
+func (f Format) Render(calc *calculator.Calculator, v Value) (string, error) {
+	if z, ok := v.(Complex); ok {
+		return f.renderComplex(calc, complex128(z))
+	}
+	if q, ok := v.(Quantity); ok {
+		s, err := f.Render(calc, Float(q.Value))
+		if err != nil {
+			return "", err
+		}
+		return s + " " + q.Unit.String(), nil
+	}
+	if l, ok := v.(List); ok {
+		parts := make([]string, len(l))
+		for i, elem := range l {
+			s, err := f.Render(calc, elem)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+	if p, ok := v.(Percent); ok {
+		if f.Radix != 0 {
+			return "", fmt.Errorf("cannot display the percentage %s in base %d", p, f.Radix)
+		}
+		s, err := f.Render(calc, p.X)
+		if err != nil {
+			return "", err
+		}
+		return s + "%", nil
+	}
+	if fl, ok := v.(Float); ok && (math.IsInf(float64(fl), 0) || math.IsNaN(float64(fl))) {
+		return v.String(), nil
+	}
+	if !isNumeric(v) {
+		return v.String(), nil
+	}
+	if f.Radix != 0 {
+		return f.renderRadix(calc, v)
+	}
+
+	var s string
+	switch f.Notation {
+	case NotationFixed:
+		s = fixedText(v, f.Digits)
+	case NotationScientific:
+		s = toBigFloat(v).Text('e', f.Digits-1)
+	case NotationEngineering:
+		s = engineering(toBigFloat(v).Text('e', f.Digits-1))
+	case NotationSignificant:
+		s = toBigFloat(v).Text('g', f.Digits)
+	default:
+		s = v.String()
+		// Grouping is only useful without an exponent, so spell out large floats
+		if x, ok := v.(Float); ok && f.Group && math.Abs(float64(x)) >= 1 && math.Abs(float64(x)) < 1e21 {
+			s = strconv.FormatFloat(float64(x), 'f', -1, 64)
+		}
+	}
+	if f.Group {
+		s = groupThousands(s)
+	}
+	return s, nil
+}
+
+// renderComplex formats a complex number in rectangular or polar form
+func (f Format) renderComplex(calc *calculator.Calculator, z complex128) (string, error) {
+	if f.Radix != 0 {
+		if imag(z) != 0 {
+			return "", fmt.Errorf("cannot display %s in base %d: not an integer", Complex(z), f.Radix)
+		}
+		return f.Render(calc, Float(real(z)))
+	}
+
+	var err error
+	part := func(x float64) string {
+		s, partErr := f.Render(calc, Float(x))
+		if partErr != nil {
+			err = partErr
+		}
+		return s
+	}
+	if !f.Polar {
+		s := rectangular(z, part)
+		return s, err
+	}
+
+	r, theta := calc.Polar(z)
+	unit := map[calculator.AngleMode]string{calculator.Radians: " rad", calculator.Degrees: "°", calculator.Gradians: " grad"}
+	s := part(r) + "∠" + part(theta) + unit[calc.AngleMode()]
+	return s, err
+}
+
+// renderRadix formats a whole number in f.Radix with a 0x, 0o or 0b prefix, or
+// with a "(base N)" suffix for other bases
+func (f Format) renderRadix(calc *calculator.Calculator, v Value) (string, error) {
+	x, err := toInt(v)
+	if err != nil {
+		return "", fmt.Errorf("cannot display %s in base %d: not an integer", v, f.Radix)
+	}
+	if _, ok := v.(Int); ok && x.Sign() < 0 {
+		x = new(big.Int).Add(x, new(big.Int).Lsh(big.NewInt(1), calc.WordSize().Bits))
+	}
+
+	sign := ""
+	if x.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(x).Text(f.Radix)
+	size := map[int]int{2: 4, 8: 3, 16: 4}[f.Radix]
+	if f.Group && size > 0 {
+		digits = groupDigits(digits, size, '_')
+	}
+	switch f.Radix {
+	case 2:
+		return sign + "0b" + digits, nil
+	case 8:
+		return sign + "0o" + digits, nil
+	case 16:
+		return sign + "0x" + digits, nil
+	default:
+		return fmt.Sprintf("%s%s (base %d)", sign, digits, f.Radix), nil
+	}
+}
+
+// isNumeric reports whether v is one of the number types
+func isNumeric(v Value) bool {
+	switch v.(type) {
+	case Float, BigFloat, Rat, Int:
+		return true
+	}
+	return false
+}
+
+// toBigFloat converts a number to a big.Float without losing precision where
+// possible, so that shortest formatting reproduces the value's own digits
+func toBigFloat(v Value) *big.Float {
+	switch x := v.(type) {
+	case BigFloat:
+		return x.X
+	case Rat:
+		return new(big.Float).SetRat(x.X)
+	case Int:
+		return new(big.Float).SetInt(x.X)
+	default:
+		f, _ := ToFloat(v)
+		return new(big.Float).SetFloat64(f)
+	}
+}
+
+// fixedText formats v with the given number of decimal places, exactly for
+// fractions and integers
+func fixedText(v Value, places int) string {
+	switch x := v.(type) {
+	case Rat:
+		return x.X.FloatString(places)
+	case Int:
+		return new(big.Rat).SetInt(x.X).FloatString(places)
+	default:
+		return toBigFloat(v).Text('f', places)
+	}
+}
+
+// engineering rewrites scientific notation such as 1.2345e+04 so the exponent is
+// a multiple of three, e.g. 12.345e+03
+func engineering(sci string) string {
+	mantissa, expText, ok := strings.Cut(sci, "e")
+	if !ok {
+		return sci
+	}
+	exp, err := strconv.Atoi(expText)
+	if err != nil {
+		return sci
+	}
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") {
+		sign, mantissa = "-", mantissa[1:]
+	}
+	digits := strings.Replace(mantissa, ".", "", 1)
+
+	shift := ((exp % 3) + 3) % 3
+	for len(digits) < shift+1 {
+		digits += "0"
+	}
+	s := sign + digits[:shift+1]
+	if frac := digits[shift+1:]; frac != "" {
+		s += "." + frac
+	}
+	return fmt.Sprintf("%se%+03d", s, exp-shift)
+}
+
+// groupThousands inserts commas into the integer part of a decimal number and,
+// for fractions, of both numerator and denominator
+func groupThousands(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && isDigit(rune(s[j])) {
+			j++
+		}
+		if j == i {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		// Only runs at the start, after a leading sign or after a fraction bar are integer parts
+		if i == 0 || s[i-1] == '/' || (i == 1 && s[0] == '-') {
+			b.WriteString(groupDigits(s[i:j], 3, ','))
+		} else {
+			b.WriteString(s[i:j])
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// groupDigits separates digits into groups of size counted from the right
+func groupDigits(digits string, size int, sep byte) string {
+	var b strings.Builder
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%size == 0 {
+			b.WriteByte(sep)
+		}
+		b.WriteByte(digits[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package expr
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"hex", "hex"},
+		{"base 36", "base 36"},
+		{"base16", "hex"},
+		{"base 10", "auto"},
+		{"sci 3 hex", "hex"},
+		{"eng dec", "auto"},
+		{"fix 2 group", "fix 2 group"},
+		{"hex sci", "sci"},
+		{"sci 4", "sci 4"},
+		{"ENG", "eng"},
+		{"sig 3", "sig 3"},
+		{"group nogroup", "auto"},
+		{"fix 2 auto", "auto"},
+	}
+	for _, test := range tests {
+		f, err := ParseFormat(Format{}, strings.Fields(test.spec))
+		if err != nil {
+			t.Errorf("ParseFormat(%q) failed: %v", test.spec, err)
+			continue
+		}
+		if f.String() != test.expected {
+			t.Errorf("ParseFormat(%q): expected %s, got %s", test.spec, test.expected, f)
+		}
+	}
+
+	for _, spec := range []string{"", "base", "base 1", "base 37", "fix", "fix -1", "sig 0", "sci 0", "roman", "hex 2"} {
+		if _, err := ParseFormat(Format{}, strings.Fields(spec)); err == nil {
+			t.Errorf("Expected error parsing format %q", spec)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	calc := calculator.New()
+	format := func(spec string) Format {
+		f, err := ParseFormat(Format{}, strings.Fields(spec))
+		if err != nil {
+			t.Fatalf("ParseFormat(%q) failed: %v", spec, err)
+		}
+		return f
+	}
+
+	tests := []struct {
+		spec     string
+		value    Value
+		expected string
+	}{
+		{"auto", Float(0.5), "0.5"},
+		{"hex", Float(255), "0xff"},
+		{"oct", Float(8), "0o10"},
+		{"bin", Float(-5), "-0b101"},
+		{"bin group", Float(240), "0b1111_0000"},
+		{"base 36", Float(1295), "zz (base 36)"},
+		{"fix 2", Float(2.675), "2.67"}, // 2.675 is slightly below in binary
+		{"fix 3", Rat{big.NewRat(2, 3)}, "0.667"},
+		{"fix 2 group", Float(1234567.891), "1,234,567.89"},
+		{"group", Float(-1234567), "-1,234,567"},
+		{"group", Rat{big.NewRat(1000000, 3)}, "1,000,000/3"},
+		{"group", Float(1e21), "1e+21"},
+		{"sci", Float(1234567), "1.234567e+06"},
+		{"sci 3", Float(0.00012345), "1.23e-04"},
+		{"eng", Float(1234567), "1.234567e+06"},
+		{"eng", Float(12345), "12.345e+03"},
+		{"eng 2", Float(0.000047), "47e-06"},
+		{"eng", Float(-0.5), "-500e-03"},
+		{"sig 3", Float(math.Pi), "3.14"},
+		{"sig 2", Int{big.NewInt(123456)}, "1.2e+05"},
+		{"fix 2", Float(math.Inf(1)), "+Inf"},
+		{"hex", Float(math.NaN()), "NaN"},
+	}
+	for _, test := range tests {
+		s, err := format(test.spec).Render(calc, test.value)
+		if err != nil {
+			t.Errorf("Render(%s, %v) failed: %v", test.spec, test.value, err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("Render(%s, %v): expected %s, got %s", test.spec, test.value, test.expected, s)
+		}
+	}
+
+	if _, err := format("hex").Render(calc, Float(2.5)); err == nil {
+		t.Error("Expected error displaying 2.5 in hex")
+	}
+	if _, err := format("hex").Render(calc, Percent{Float(10)}); err == nil {
+		t.Error("Expected error displaying 10% in hex")
+	}
+
+	// Negative integers in programmer mode show their two's complement bit pattern
+	if err := calc.SetWordSize(calculator.WordSize{Bits: 8, Signed: true}); err != nil {
+		t.Fatalf("SetWordSize failed: %v", err)
+	}
+	if s, err := format("hex").Render(calc, Int{big.NewInt(-1)}); err != nil || s != "0xff" {
+		t.Errorf("Expected -1 as i8 to display as 0xff, got %s (err: %v)", s, err)
+	}
+}
+
+func TestSplitFormat(t *testing.T) {
+	tests := []struct {
+		src      string
+		expr     string
+		format   string
+		suffixed bool
+	}{
+		{"255 to hex", "255", "hex group", true},
+		{"x * 2 TO fix 2 nogroup", "x * 2", "fix 2", true},
+		{"1234 to sci to fix 1", "1234 to sci", "fix 1 group", true},
+		{"to_total + 1", "to_total + 1", "group", false},
+		{"5 km to mi", "5 km to mi", "group", false},
+	}
+	base := Format{Group: true}
+	for _, test := range tests {
+		src, f, ok, err := SplitFormat(test.src, base)
+		if err != nil || src != test.expr || f.String() != test.format || ok != test.suffixed {
+			t.Errorf("SplitFormat(%q): expected %q, %s, %v; got %q, %s, %v (err: %v)",
+				test.src, test.expr, test.format, test.suffixed, src, f, ok, err)
+		}
+	}
+
+	errors := []struct {
+		src     string
+		message string
+	}{
+		{"255 to base 37", "base must be 2 to 36, got 37"},
+		{"255 to base1", "base must be 2 to 36, got 1"},
+		{"1 to sig 0", "sig needs a count of at least 1, got 0"},
+		{"12345 to sci 0", "sci needs a count of at least 1, got 0"},
+		{"5 km to mi to fix", "fix needs a digit count"},
+	}
+	for _, test := range errors {
+		if _, _, _, err := SplitFormat(test.src, base); err == nil || err.Error() != test.message {
+			t.Errorf("SplitFormat(%q): expected error %q, got %v", test.src, test.message, err)
+		}
+	}
+}
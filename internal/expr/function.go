@@ -0,0 +1,51 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxDepth is the default limit on nested user-defined function calls
+const DefaultMaxDepth = 256
+
+// MaxCallDepth is the highest limit SetMaxDepth accepts, low enough that the
+// evaluator's own recursion always fits in the goroutine stack
+const MaxCallDepth = 10000
+
+// Function is a user-defined function such as f(x, y) = x^2 + y
+type Function struct {
+	Name   string
+	Params []string
+	Body   Node
+}
+
+// Signature renders the function name and parameter list, e.g. f(x, y)
+func (f *Function) Signature() string {
+	return f.Name + "(" + strings.Join(f.Params, ", ") + ")"
+}
+
+// String renders the complete definition
+func (f *Function) String() string {
+	return f.Signature() + " = " + f.Body.String()
+}
+
+// validate checks the definition for names that cannot be bound
+func (f *Function) validate() error {
+	if isReserved(f.Name) {
+		return fmt.Errorf("cannot define function with reserved name %q", f.Name)
+	}
+	if isBuiltinFunc(f.Name) {
+		return fmt.Errorf("cannot redefine built-in function %q", f.Name)
+	}
+	seen := make(map[string]bool, len(f.Params))
+	for _, param := range f.Params {
+		if isReserved(param) {
+			return fmt.Errorf("cannot use reserved name %q as a parameter", param)
+		}
+		if seen[param] {
+			return fmt.Errorf("duplicate parameter %q in %s", param, f.Signature())
+		}
+		seen[param] = true
+	}
+	return nil
+}
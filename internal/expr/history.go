@@ -0,0 +1,46 @@
+package expr
+
+import "fmt"
+
+// HistoryEntry is a single evaluated line and its result
+type HistoryEntry struct {
+	Source string
+	Value  Value
+}
+
+// History is an append-only, 1-indexed record of the results of a session
+type History struct {
+	entries []HistoryEntry
+}
+
+// Add records the result of evaluating source and returns its index
+func (h *History) Add(source string, value Value) int {
+	h.entries = append(h.entries, HistoryEntry{Source: source, Value: value})
+	return len(h.entries)
+}
+
+// Len returns the number of recorded entries
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// Entries returns a copy of all recorded entries, oldest first
+func (h *History) Entries() []HistoryEntry {
+	return append([]HistoryEntry(nil), h.entries...)
+}
+
+// Last returns the most recently recorded value
+func (h *History) Last() (Value, error) {
+	if len(h.entries) == 0 {
+		return nil, fmt.Errorf("no previous result")
+	}
+	return h.entries[len(h.entries)-1].Value, nil
+}
+
+// Get returns the value recorded at the given 1-based index
+func (h *History) Get(index int) (Value, error) {
+	if index < 1 || index > len(h.entries) {
+		return nil, fmt.Errorf("history entry $%d does not exist", index)
+	}
+	return h.entries[index-1].Value, nil
+}
@@ -0,0 +1,31 @@
+package expr
+
+import "testing"
+
+func TestHistory(t *testing.T) {
+	h := &History{}
+
+	if _, err := h.Last(); err == nil {
+		t.Error("Expected error for Last on empty history")
+	}
+
+	if idx := h.Add("3 + 4", Float(7)); idx != 1 {
+		t.Errorf("Expected first entry index 1, got %d", idx)
+	}
+	h.Add("ans * 2", Float(14))
+
+	if v, err := h.Last(); err != nil || v != Float(14) {
+		t.Errorf("Expected last value 14, got %v (err: %v)", v, err)
+	}
+	if v, err := h.Get(1); err != nil || v != Float(7) {
+		t.Errorf("Expected $1 = 7, got %v (err: %v)", v, err)
+	}
+	for _, idx := range []int{0, 3, -1} {
+		if _, err := h.Get(idx); err == nil {
+			t.Errorf("Expected error for $%d", idx)
+		}
+	}
+	if h.Len() != 2 || h.Entries()[1].Source != "ans * 2" {
+		t.Errorf("Unexpected entries: %v", h.Entries())
+	}
+}
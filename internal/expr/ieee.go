@@ -0,0 +1,135 @@
+package expr
+
+import (
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+// Every float64 result is checked for IEEE-754 edge cases, such as overflow to
+// +Inf or NaN, according to the calculator's IEEE mode: an edge case is an
+// error in strict mode and a warning in lenient mode. Values sampled by solve,
+// integrate and the like are not checked, as numerical methods probe where
+// functions misbehave.
+
+// Warnings returns the IEEE-754 edge cases met while evaluating in lenient
+// mode, such as a result that overflowed to +Inf
+func (e *Evaluator) Warnings() []string {
+	return e.warnings
+}
+
+// checkFloat applies the IEEE mode to the float64 result of op
+func (e *Evaluator) checkFloat(op string, result float64, operands ...float64) (Value, error) {
+	return floatResult(e.check(op, result, operands...))
+}
+
+// checkQuantity applies the IEEE mode to the magnitude of a result with a unit
+func (e *Evaluator) checkQuantity(op string, result float64, u units.Unit, operands ...float64) (Value, error) {
+	f, err := e.check(op, result, operands...)
+	if err != nil {
+		return nil, err
+	}
+	return quantity(f, u), nil
+}
+
+// check applies the IEEE mode to the float64 result of op, returning it unless
+// strict mode turns an edge case into an error
+func (e *Evaluator) check(op string, result float64, operands ...float64) (float64, error) {
+	if e.sampling > 0 {
+		return result, nil
+	}
+	return e.checkResult(e.calc.Diagnose(op, result, operands...))
+}
+
+// checkExact applies the IEEE mode to the float64 rounding of an exact value
+func (e *Evaluator) checkExact(op string, exact *big.Rat, f float64) (Value, error) {
+	if e.sampling > 0 {
+		return Float(f), nil
+	}
+	return floatResult(e.checkResult(e.calc.DiagnoseExact(op, exact, f)))
+}
+
+// checkResult applies the IEEE mode to a diagnosed result, recording each
+// warning once
+func (e *Evaluator) checkResult(r calculator.Result) (float64, error) {
+	f, warnings, err := e.calc.Check(r)
+	if err != nil {
+		return 0, err
+	}
+	for _, w := range warnings {
+		if !slices.Contains(e.warnings, w) {
+			e.warnings = append(e.warnings, w)
+		}
+	}
+	return f, nil
+}
+
+// toFloat converts an operand of a float64 operation, applying the IEEE mode
+// to exact values, such as 1000!, that float64 cannot hold
+func (e *Evaluator) toFloat(v Value) (float64, error) {
+	f, err := ToFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	var exact *big.Rat
+	switch x := v.(type) {
+	case Rat:
+		exact = x.X
+	case Int:
+		exact = new(big.Rat).SetInt(x.X)
+	case BigFloat:
+		exact, _ = x.X.Rat(nil)
+	}
+	if exact == nil {
+		return f, nil
+	}
+	r, err := e.checkExact(calculator.DescribeExact(exact), exact, f)
+	if err != nil {
+		return 0, err
+	}
+	return float64(r.(Float)), nil
+}
+
+// checkElements applies the IEEE mode to every float64 in the result of a
+// function of lists such as det, unless its arguments were not all finite
+func (e *Evaluator) checkElements(name string, v Value, args ...Value) (Value, error) {
+	if !finite(args...) {
+		return v, nil
+	}
+	switch x := v.(type) {
+	case Float:
+		return e.checkFloat(name, float64(x))
+	case List:
+		checked := make(List, len(x))
+		for i, elem := range x {
+			c, err := e.checkElements(name, elem)
+			if err != nil {
+				return nil, err
+			}
+			checked[i] = c
+		}
+		return checked, nil
+	}
+	return v, nil
+}
+
+// finite reports whether every float64 in values, including the elements of
+// lists, is neither infinite nor NaN
+func finite(values ...Value) bool {
+	for _, v := range values {
+		switch x := v.(type) {
+		case Float:
+			if math.IsInf(float64(x), 0) || math.IsNaN(float64(x)) {
+				return false
+			}
+		case List:
+			if !finite(x...) {
+				return false
+			}
+		}
+	}
+	return true
+}
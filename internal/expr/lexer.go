@@ -0,0 +1,310 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the lexical class of a token
+type TokenKind int
+
+const (
+	TokenEOF TokenKind = iota
+	TokenNumber
+	TokenIdent
+	TokenOperator
+	TokenLParen
+	TokenRParen
+	TokenComma
+	TokenAssign
+	TokenHistory
+	TokenImaginary
+	TokenDate
+	TokenTime
+	TokenDuration
+	TokenLBracket
+	TokenRBracket
+)
+
+// String returns a human readable name for the token kind
+func (k TokenKind) String() string {
+	switch k {
+	case TokenEOF:
+		return "end of input"
+	case TokenNumber:
+		return "number"
+	case TokenIdent:
+		return "identifier"
+	case TokenOperator:
+		return "operator"
+	case TokenLParen:
+		return "'('"
+	case TokenRParen:
+		return "')'"
+	case TokenComma:
+		return "','"
+	case TokenAssign:
+		return "'='"
+	case TokenHistory:
+		return "history reference"
+	case TokenImaginary:
+		return "imaginary number"
+	case TokenDate:
+		return "date"
+	case TokenTime:
+		return "time"
+	case TokenDuration:
+		return "duration"
+	case TokenLBracket:
+		return "'['"
+	case TokenRBracket:
+		return "']'"
+	default:
+		return "unknown token"
+	}
+}
+
+// keywordOperators are words that the lexer turns into operator tokens, which
+// makes them unavailable as variable names
+var keywordOperators = map[string]bool{
+	"xor": true,
+	"to":  true, // unit conversion, 5 km to mi
+	"in":  true, // unit conversion, 60 W * 3 h in kWh
+	"mod": true, // remainder, an unambiguous spelling of %
+	"of":  true, // percentage of a value, 20% of 80
+	"as":  true, // x as % of y
+}
+
+// Token is a single lexical element of an expression together with its byte offset
+type Token struct {
+	Kind  TokenKind
+	Text  string
+	Pos   int
+	Value float64 // Parsed value for TokenNumber and TokenImaginary, entry index for TokenHistory
+}
+
+// String describes the token for use in error messages
+func (t Token) String() string {
+	if t.Kind == TokenEOF {
+		return t.Kind.String()
+	}
+	return fmt.Sprintf("%q", t.Text)
+}
+
+// Tokenize splits src into tokens, always terminating the slice with a TokenEOF
+func Tokenize(src string) ([]Token, error) {
+	var tokens []Token
+	pos := 0
+	for pos < len(src) {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case isDigit(r) || r == '.':
+			if tok := scanTemporal(src, pos); tok != nil {
+				tokens = append(tokens, *tok)
+				pos += len(tok.Text)
+				continue
+			}
+			tok, err := scanNumber(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			pos += len(tok.Text)
+			// A number directly followed by a lone i is an imaginary literal such as 4i
+			if pos < len(src) && src[pos] == 'i' && (pos+1 == len(src) || !isIdentPart(rune(src[pos+1]))) {
+				tok.Kind = TokenImaginary
+				tok.Text += "i"
+				pos++
+			}
+			tokens = append(tokens, tok)
+		case isIdentStart(r):
+			end := pos + size
+			for end < len(src) {
+				next, n := utf8.DecodeRuneInString(src[end:])
+				if !isIdentPart(next) {
+					break
+				}
+				end += n
+			}
+			text := src[pos:end]
+			if keywordOperators[strings.ToLower(text)] {
+				tokens = append(tokens, Token{Kind: TokenOperator, Text: strings.ToLower(text), Pos: pos})
+			} else {
+				tokens = append(tokens, Token{Kind: TokenIdent, Text: text, Pos: pos})
+			}
+			pos = end
+		case r == '$':
+			end := pos + 1
+			for end < len(src) && isDigit(rune(src[end])) {
+				end++
+			}
+			if end == pos+1 {
+				err := syntaxError(pos, 1, "expected history index after '$'")
+				err.Hint = "refer to earlier results as $1, $2 and so on"
+				return nil, err
+			}
+			index, err := strconv.Atoi(src[pos+1 : end])
+			if err != nil {
+				return nil, syntaxError(pos, end-pos, "history index %q out of range", src[pos:end])
+			}
+			tokens = append(tokens, Token{Kind: TokenHistory, Text: src[pos:end], Pos: pos, Value: float64(index)})
+			pos = end
+		case r == '(':
+			tokens = append(tokens, Token{Kind: TokenLParen, Text: "(", Pos: pos})
+			pos++
+		case r == ')':
+			tokens = append(tokens, Token{Kind: TokenRParen, Text: ")", Pos: pos})
+			pos++
+		case r == '[':
+			tokens = append(tokens, Token{Kind: TokenLBracket, Text: "[", Pos: pos})
+			pos++
+		case r == ']':
+			tokens = append(tokens, Token{Kind: TokenRBracket, Text: "]", Pos: pos})
+			pos++
+		case r == ',':
+			tokens = append(tokens, Token{Kind: TokenComma, Text: ",", Pos: pos})
+			pos++
+		case r == '=':
+			tokens = append(tokens, Token{Kind: TokenAssign, Text: "=", Pos: pos})
+			pos++
+		case r == '/' || r == '<' || r == '>':
+			// Two character operators //, << and >>; a lone < or > is not an operator
+			if pos+1 < len(src) && src[pos+1] == src[pos] {
+				tokens = append(tokens, Token{Kind: TokenOperator, Text: src[pos : pos+2], Pos: pos})
+				pos += 2
+			} else if r == '/' {
+				tokens = append(tokens, Token{Kind: TokenOperator, Text: "/", Pos: pos})
+				pos++
+			} else {
+				err := syntaxError(pos, 1, "unexpected character %q", r)
+				err.Hint = "there are no comparison operators; << and >> shift bits"
+				return nil, err
+			}
+		case strings.ContainsRune("+-*%^&|~@!", r):
+			tokens = append(tokens, Token{Kind: TokenOperator, Text: string(r), Pos: pos})
+			pos++
+		default:
+			return nil, syntaxError(pos, size, "unexpected character %q", r)
+		}
+	}
+	tokens = append(tokens, Token{Kind: TokenEOF, Pos: len(src)})
+	return tokens, nil
+}
+
+// scanNumber reads a decimal literal with optional fraction and exponent, or a
+// 0x, 0o or 0b integer literal, starting at pos
+func scanNumber(src string, pos int) (Token, error) {
+	if radixPrefix(src, pos) {
+		return scanRadixNumber(src, pos)
+	}
+	end := pos
+	digits := 0
+	for end < len(src) && isDigit(rune(src[end])) {
+		end++
+		digits++
+	}
+	if end < len(src) && src[end] == '.' {
+		end++
+		for end < len(src) && isDigit(rune(src[end])) {
+			end++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return Token{}, syntaxError(pos, 1, "malformed number")
+	}
+	// Only treat 'e' as an exponent when digits follow, so "2e" stays a number and an identifier
+	if end < len(src) && (src[end] == 'e' || src[end] == 'E') {
+		exp := end + 1
+		if exp < len(src) && (src[exp] == '+' || src[exp] == '-') {
+			exp++
+		}
+		if exp < len(src) && isDigit(rune(src[exp])) {
+			for exp < len(src) && isDigit(rune(src[exp])) {
+				exp++
+			}
+			end = exp
+		}
+	}
+
+	text := src[pos:end]
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return Token{}, syntaxError(pos, len(text), "malformed number %q", text)
+	}
+	return Token{Kind: TokenNumber, Text: text, Pos: pos, Value: value}, nil
+}
+
+var (
+	dateLiteral     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{1,2}:\d{2}(:\d{2}(\.\d+)?)?)?`)
+	timeLiteral     = regexp.MustCompile(`^\d{1,2}:\d{2}(:\d{2}(\.\d+)?)?`)
+	durationLiteral = regexp.MustCompile(`^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h|d|w))+`)
+	durationPart    = regexp.MustCompile(`\d+(\.\d+)?(ns|us|µs|ms|s|m|h|d|w)`)
+)
+
+// scanTemporal reads a date such as 2026-10-16 or 2026-10-16T09:30, a time of
+// day such as 14:30:05, or a Go-style duration of two or more parts such as
+// 1h30m, starting at pos. It returns nil when none starts there. A duration
+// with a single part, such as 90d or 5m, is left to the number and unit rules,
+// which read 5m as five metres except beside a duration, as in 1h30m + 15m.
+func scanTemporal(src string, pos int) *Token {
+	rest := src[pos:]
+	// The literal must not run into further digits or letters, as in 2026-10-160
+	complete := func(text string) bool {
+		if len(text) == len(rest) {
+			return true
+		}
+		next, _ := utf8.DecodeRuneInString(rest[len(text):])
+		return !isIdentPart(next) && next != '.' && next != ':'
+	}
+	if text := dateLiteral.FindString(rest); text != "" && complete(text) {
+		return &Token{Kind: TokenDate, Text: text, Pos: pos}
+	}
+	if text := timeLiteral.FindString(rest); text != "" && complete(text) {
+		return &Token{Kind: TokenTime, Text: text, Pos: pos}
+	}
+	if text := durationLiteral.FindString(rest); text != "" && complete(text) && len(durationPart.FindAllString(text, -1)) > 1 {
+		return &Token{Kind: TokenDuration, Text: text, Pos: pos}
+	}
+	return nil
+}
+
+// radixPrefix reports whether a 0x, 0o or 0b prefix starts at pos
+func radixPrefix(src string, pos int) bool {
+	return pos+1 < len(src) && src[pos] == '0' && strings.ContainsRune("xXoObB", rune(src[pos+1]))
+}
+
+// scanRadixNumber reads an integer literal with a 0x, 0o or 0b prefix; underscores
+// may separate digits as in Go, e.g. 0b1111_0000
+func scanRadixNumber(src string, pos int) (Token, error) {
+	end := pos + 2
+	for end < len(src) && isIdentPart(rune(src[end])) {
+		end++
+	}
+	text := src[pos:end]
+	x, ok := new(big.Int).SetString(text, 0)
+	if !ok || end == pos+2 {
+		return Token{}, syntaxError(pos, len(text), "malformed number %q", text)
+	}
+	value, _ := new(big.Float).SetInt(x).Float64()
+	return Token{Kind: TokenNumber, Text: text, Pos: pos, Value: value}, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '°' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
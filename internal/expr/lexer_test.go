@@ -0,0 +1,209 @@
+package expr
+
+import "testing"
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize("3.5 + sqrt(x_1, 2e3)")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+
+	expected := []struct {
+		kind TokenKind
+		text string
+		pos  int
+	}{
+		{TokenNumber, "3.5", 0},
+		{TokenOperator, "+", 4},
+		{TokenIdent, "sqrt", 6},
+		{TokenLParen, "(", 10},
+		{TokenIdent, "x_1", 11},
+		{TokenComma, ",", 14},
+		{TokenNumber, "2e3", 16},
+		{TokenRParen, ")", 19},
+		{TokenEOF, "", 20},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+	for i, want := range expected {
+		got := tokens[i]
+		if got.Kind != want.kind || got.Text != want.text || got.Pos != want.pos {
+			t.Errorf("Token %d: expected %v %q at %d, got %v %q at %d",
+				i, want.kind, want.text, want.pos, got.Kind, got.Text, got.Pos)
+		}
+	}
+	if tokens[6].Value != 2000 {
+		t.Errorf("Expected 2e3 to parse as 2000, got %v", tokens[6].Value)
+	}
+}
+
+func TestTokenizeNumbers(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"42", 42},
+		{".5", 0.5},
+		{"5.", 5},
+		{"1.5e-3", 0.0015},
+		{"1E+2", 100},
+		{"0xff", 255},
+		{"0XFF", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+	}
+
+	for _, test := range tests {
+		tokens, err := Tokenize(test.src)
+		if err != nil {
+			t.Errorf("Tokenize(%q) failed: %v", test.src, err)
+			continue
+		}
+		if tokens[0].Kind != TokenNumber || tokens[0].Value != test.expected {
+			t.Errorf("Tokenize(%q): expected number %v, got %v", test.src, test.expected, tokens[0])
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, src := range []string{"2 $ 3", "$", ".", "1 # 2", "1 < 2", "0x", "0b102", "0o8", "$99999999999999999999"} {
+		if _, err := Tokenize(src); err == nil {
+			t.Errorf("Expected error tokenizing %q", src)
+		}
+	}
+}
+
+func TestTokenizeBitwiseOperators(t *testing.T) {
+	tokens, err := Tokenize("~a & b | c XOR d << 2 >> 1 // 3")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	var ops []string
+	for _, tok := range tokens {
+		if tok.Kind == TokenOperator {
+			ops = append(ops, tok.Text)
+		}
+	}
+	expected := []string{"~", "&", "|", "xor", "<<", ">>", "//"}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected operators %v, got %v", expected, ops)
+	}
+	for i := range expected {
+		if ops[i] != expected[i] {
+			t.Errorf("Operator %d: expected %q, got %q", i, expected[i], ops[i])
+		}
+	}
+}
+
+func TestTokenizeImaginary(t *testing.T) {
+	tokens, err := Tokenize("3+4i - 2.5e1i * i + 2im")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if tokens[2].Kind != TokenImaginary || tokens[2].Text != "4i" || tokens[2].Value != 4 {
+		t.Errorf("Expected imaginary 4i, got %v %v", tokens[2].Kind, tokens[2])
+	}
+	if tokens[4].Kind != TokenImaginary || tokens[4].Value != 25 {
+		t.Errorf("Expected imaginary 2.5e1i, got %v %v", tokens[4].Kind, tokens[4])
+	}
+	if tokens[6].Kind != TokenIdent || tokens[6].Text != "i" {
+		t.Errorf("Expected identifier i, got %v", tokens[6])
+	}
+	// A longer identifier after a number is not an imaginary suffix
+	if tokens[8].Kind != TokenNumber || tokens[9].Kind != TokenIdent || tokens[9].Text != "im" {
+		t.Errorf("Expected 2 followed by identifier im, got %v %v", tokens[8], tokens[9])
+	}
+}
+
+func TestTokenizeConversionKeywords(t *testing.T) {
+	tokens, err := Tokenize("5 km TO mi in °C")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if tokens[2].Kind != TokenOperator || tokens[2].Text != "to" {
+		t.Errorf("Expected operator to, got %v %v", tokens[2].Kind, tokens[2])
+	}
+	if tokens[4].Kind != TokenOperator || tokens[4].Text != "in" {
+		t.Errorf("Expected operator in, got %v %v", tokens[4].Kind, tokens[4])
+	}
+	if tokens[5].Kind != TokenIdent || tokens[5].Text != "°C" {
+		t.Errorf("Expected identifier °C, got %v", tokens[5])
+	}
+}
+
+func TestTokenizeBrackets(t *testing.T) {
+	tokens, err := Tokenize("[1, 2]")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	kinds := []TokenKind{TokenLBracket, TokenNumber, TokenComma, TokenNumber, TokenRBracket, TokenEOF}
+	if len(tokens) != len(kinds) {
+		t.Fatalf("Expected %d tokens, got %d", len(kinds), len(tokens))
+	}
+	for i, kind := range kinds {
+		if tokens[i].Kind != kind {
+			t.Errorf("Token %d: expected %v, got %v", i, kind, tokens[i].Kind)
+		}
+	}
+}
+
+func TestTokenizePercentKeywords(t *testing.T) {
+	tokens, err := Tokenize("7 MOD 4 as % Of x")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	for _, i := range []int{1, 3, 4, 5} {
+		if tokens[i].Kind != TokenOperator {
+			t.Errorf("Expected operator at %d, got %v %v", i, tokens[i].Kind, tokens[i])
+		}
+	}
+	if tokens[1].Text != "mod" || tokens[5].Text != "of" {
+		t.Errorf("Expected lower-case keywords, got %v and %v", tokens[1], tokens[5])
+	}
+}
+
+func TestTokenizeTemporal(t *testing.T) {
+	tests := []struct {
+		src  string
+		kind TokenKind
+		text string
+	}{
+		{"2026-10-16", TokenDate, "2026-10-16"},
+		{"2026-10-16T09:30", TokenDate, "2026-10-16T09:30"},
+		{"14:30:05.25", TokenTime, "14:30:05.25"},
+		{"9:05", TokenTime, "9:05"},
+		{"1h30m", TokenDuration, "1h30m"},
+		{"2d12h", TokenDuration, "2d12h"},
+		{"1m30.5s", TokenDuration, "1m30.5s"},
+		{"1s500ms", TokenDuration, "1s500ms"},
+		{"90d", TokenNumber, "90"},
+		{"5m", TokenNumber, "5"},
+		{"2026-10", TokenNumber, "2026"},
+		{"1h30min", TokenNumber, "1"},
+	}
+	for _, test := range tests {
+		tokens, err := Tokenize(test.src)
+		if err != nil {
+			t.Errorf("Tokenize(%q) failed: %v", test.src, err)
+			continue
+		}
+		if tokens[0].Kind != test.kind || tokens[0].Text != test.text {
+			t.Errorf("Tokenize(%q): expected %v %q, got %v %q", test.src, test.kind, test.text, tokens[0].Kind, tokens[0].Text)
+		}
+	}
+}
+
+func TestTokenizeHistoryReference(t *testing.T) {
+	tokens, err := Tokenize("$12 + ans")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if tokens[0].Kind != TokenHistory || tokens[0].Text != "$12" || tokens[0].Value != 12 {
+		t.Errorf("Expected history reference $12, got %v", tokens[0])
+	}
+	if tokens[2].Kind != TokenIdent || tokens[2].Text != "ans" {
+		t.Errorf("Expected identifier ans, got %v", tokens[2])
+	}
+}
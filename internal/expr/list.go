@@ -0,0 +1,39 @@
+package expr
+
+import "strings"
+
+// List is an ordered collection of values written [1, 2, 3.5], the argument to
+// aggregate functions such as sum and median. Lists of numbers also serve as
+// vectors and lists of equally long lists as matrices.
+type List []Value
+
+// String formats the elements in brackets, separated by commas
+func (l List) String() string {
+	parts := make([]string, len(l))
+	for i, v := range l {
+		parts[i] = v.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// evalList evaluates the elements of a list literal
+func (e *Evaluator) evalList(n *ListLit) (Value, error) {
+	elems, err := e.evalArgs(n.Elems)
+	if err != nil {
+		return nil, err
+	}
+	return List(elems), nil
+}
+
+// spread replaces list arguments, including nested lists, by their elements
+func spread(args []Value) []Value {
+	var values []Value
+	for _, arg := range args {
+		if l, ok := arg.(List); ok {
+			values = append(values, spread(l)...)
+		} else {
+			values = append(values, arg)
+		}
+	}
+	return values
+}
@@ -0,0 +1,93 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestListsAndStatistics(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"[1, 2, 3.5]", "[1, 2, 3.5]"},
+		{"[]", "[]"},
+		{"[1 + 1, 2 km, 10%]", "[2, 2 km, 10%]"},
+		{"sum([1, 2, 3.5])", "6.5"},
+		{"sum(1, 2, 3.5)", "6.5"},
+		{"mean([2, 4, 9])", "5"},
+		{"median([3, 1, 2, 10])", "2.5"},
+		{"mode([1, 2, 2, 3])", "2"},
+		{"variance([2, 4, 4, 4, 5, 5, 7, 9])", "4.571428571428571"},
+		{"pvariance([2, 4, 4, 4, 5, 5, 7, 9])", "4"},
+		{"pstddev([2, 4, 4, 4, 5, 5, 7, 9])", "2"},
+		{"stddev([1, 3])", "1.4142135623730951"},
+		{"percentile([15, 20, 35, 40, 50], 40)", "29"},
+		{"percentile([15, 20, 35, 40, 50], 100)", "50"},
+		{"percentile([15, 20, 35, 40, 50], 40%)", "29"},
+		{"count([1, 2, 3])", "3"},
+		{"count([])", "0"},
+		{"min([3, 1], 2)", "1"},
+		{"max(1, [5, [7]])", "7"},
+		{"xs = [120, 80, 100]", "[120, 80, 100]"},
+		{"mean(xs) + 1", "101"},
+		{"mean([120 ms, 80 ms, 0.1 s])", "100 ms"},
+		{"pvariance([1 m, 3 m])", "1 m^2"},
+		{"percentile([1 s, 2 s], 50)", "1.5 s"},
+		{"percentile([1 s, 2 s], 50%)", "1.5 s"},
+		{"count([1 m, 2 m])", "2"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{
+		"mean([])",
+		"variance([1])",
+		"percentile([1, 2])",
+		"percentile([1, 2], 101)",
+		"percentile([1 m], 50 m)",
+		"sum([1 m, 1 s])",
+		"sqrt([4])",
+	} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestListsInOtherModes(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.RationalMode)
+	e := NewEvaluator(calc, nil)
+	if v, err := e.Evaluate("xs = [1/2, 1/4]"); err != nil || v.String() != "[1/2, 1/4]" {
+		t.Errorf("Expected [1/2, 1/4] in rational mode, got %v (err: %v)", v, err)
+	}
+	calc.SetNumberMode(calculator.FloatMode)
+	if v, err := e.Evaluate("xs"); err != nil || v.String() != "[0.5, 0.25]" {
+		t.Errorf("Expected [0.5, 0.25] after leaving rational mode, got %v (err: %v)", v, err)
+	}
+}
+
+func TestRenderList(t *testing.T) {
+	calc := calculator.New()
+	e := NewEvaluator(calc, nil)
+	v, err := e.Evaluate("[1234.5678, 2 km]")
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	f := Format{Notation: NotationFixed, Digits: 1, Group: true}
+	if s, err := f.Render(calc, v); err != nil || s != "[1,234.6, 2.0 km]" {
+		t.Errorf("Expected [1,234.6, 2.0 km], got %s (err: %v)", s, err)
+	}
+}
@@ -0,0 +1,298 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// Vectors and matrices are lists: [1, 2, 3] is a vector and [[1, 2], [3, 4]] a
+// matrix with two rows. The arithmetic operators apply element by element,
+// repeating a plain number against every element, and @ is the matrix product.
+
+// matrixFunc is a function of vectors and matrices. Unlike the builtins, its
+// arguments and result are values rather than float64.
+type matrixFunc struct {
+	args int
+	fn   func(calc *calculator.Calculator, args []Value) (Value, error)
+}
+
+// matrixFuncs holds the functions of vectors and matrices, keyed by lower-case name
+var matrixFuncs = map[string]matrixFunc{
+	"transpose": {1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		m, _, err := toMatrix(args[0])
+		if err != nil {
+			return nil, err
+		}
+		// A vector is a row, so its transpose is a column
+		return fromMatrix(calc.Transpose(m)), nil
+	}},
+	"det": {1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		m, err := toSquare(args[0])
+		if err != nil {
+			return nil, err
+		}
+		det, err := calc.Det(m)
+		if err != nil {
+			return nil, err
+		}
+		return Float(roundSignificant(det, 15)), nil
+	}},
+	"inv": {1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		m, err := toSquare(args[0])
+		if err != nil {
+			return nil, err
+		}
+		inv, err := calc.Inverse(m)
+		if err != nil {
+			return nil, err
+		}
+		return fromMatrix(tidy(inv)), nil
+	}},
+	"rank": {1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		m, _, err := toMatrix(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Float(calc.Rank(m)), nil
+	}},
+	// linsolve(A, b) solves A x = b for a vector b, or for each column of a matrix b
+	"linsolve": {2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, err := toSquare(args[0])
+		if err != nil {
+			return nil, err
+		}
+		b, vector, err := toMatrix(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if vector {
+			b = calc.Transpose(b)
+		}
+		x, err := calc.Solve(a, b)
+		if err != nil {
+			return nil, err
+		}
+		x = tidy(x)
+		if vector {
+			return fromVector(x.Data), nil
+		}
+		return fromMatrix(x), nil
+	}},
+	"dot": {2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := toVectors(args)
+		if err != nil {
+			return nil, err
+		}
+		return floatResult(calc.Dot(a, b))
+	}},
+	"cross": {2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := toVectors(args)
+		if err != nil {
+			return nil, err
+		}
+		cross, err := calc.Cross(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return fromVector(cross), nil
+	}},
+}
+
+// callMatrix invokes a function of vectors and matrices
+func (e *Evaluator) callMatrix(name string, fn matrixFunc, nodes []Node) (Value, error) {
+	if len(nodes) != fn.args {
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d", name, fn.args, len(nodes))
+	}
+	args, err := e.evalArgs(nodes)
+	if err != nil {
+		return nil, err
+	}
+	v, err := fn.fn(e.calc, args)
+	if err != nil {
+		return nil, err
+	}
+	return e.checkElements(name, v, args...)
+}
+
+// toMatrix converts a list of numbers to a one-row matrix, reporting that it is
+// a vector, and a list of equally long lists of numbers to a matrix
+func toMatrix(v Value) (calculator.Matrix, bool, error) {
+	rows, ok := v.(List)
+	if !ok || len(rows) == 0 {
+		return calculator.Matrix{}, false, fmt.Errorf("expected a vector or matrix, got %s", v)
+	}
+	if _, nested := rows[0].(List); !nested {
+		data, err := toFloats(rows)
+		if err != nil {
+			return calculator.Matrix{}, false, err
+		}
+		return calculator.Matrix{Rows: 1, Cols: len(data), Data: data}, true, nil
+	}
+
+	cols := len(rows[0].(List))
+	m := calculator.Matrix{Rows: len(rows), Cols: cols, Data: make([]float64, 0, len(rows)*cols)}
+	for _, row := range rows {
+		elems, ok := row.(List)
+		if !ok || len(elems) != cols || cols == 0 {
+			return calculator.Matrix{}, false, fmt.Errorf("matrix rows must be non-empty lists of the same length, got %s", v)
+		}
+		data, err := toFloats(elems)
+		if err != nil {
+			return calculator.Matrix{}, false, err
+		}
+		m.Data = append(m.Data, data...)
+	}
+	return m, false, nil
+}
+
+// toSquare converts v to a square matrix
+func toSquare(v Value) (calculator.Matrix, error) {
+	m, _, err := toMatrix(v)
+	if err != nil {
+		return m, err
+	}
+	if m.Rows != m.Cols {
+		return m, fmt.Errorf("expected a square matrix, got %s with %d rows and %d columns", v, m.Rows, m.Cols)
+	}
+	return m, nil
+}
+
+// toVectors converts both arguments of a vector product to vectors
+func toVectors(args []Value) ([]float64, []float64, error) {
+	var vectors [2][]float64
+	for i, arg := range args {
+		m, vector, err := toMatrix(arg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !vector {
+			return nil, nil, fmt.Errorf("expected a vector, got %s", arg)
+		}
+		vectors[i] = m.Data
+	}
+	return vectors[0], vectors[1], nil
+}
+
+// toFloats converts the elements of a list to float64
+func toFloats(l List) ([]float64, error) {
+	data := make([]float64, len(l))
+	for i, elem := range l {
+		f, err := ToFloat(elem)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = f
+	}
+	return data, nil
+}
+
+// tidy cleans up the result of an elimination, rounding the elements to 15
+// significant digits and those that are negligible beside the largest to zero,
+// so that the inverse of [[1, 2], [3, 4]] is exactly [[-2, 1], [1.5, -0.5]]
+func tidy(m calculator.Matrix) calculator.Matrix {
+	largest := 0.0
+	for _, f := range m.Data {
+		largest = math.Max(largest, math.Abs(f))
+	}
+	for i, f := range m.Data {
+		if math.Abs(f) < 1e-15*largest {
+			m.Data[i] = 0
+		} else {
+			m.Data[i] = roundSignificant(f, 15)
+		}
+	}
+	return m
+}
+
+// fromVector converts float64 elements to a list
+func fromVector(data []float64) List {
+	l := make(List, len(data))
+	for i, f := range data {
+		l[i] = Float(f)
+	}
+	return l
+}
+
+// fromMatrix converts a matrix to a list of rows
+func fromMatrix(m calculator.Matrix) List {
+	rows := make(List, m.Rows)
+	for i := range rows {
+		rows[i] = fromVector(m.Data[i*m.Cols : (i+1)*m.Cols])
+	}
+	return rows
+}
+
+// matMul computes the matrix product x @ y. A vector is a row on the left and a
+// column on the right, so the product of two vectors is their dot product and
+// a matrix times a vector is a vector.
+func (e *Evaluator) matMul(x, y Value) (Value, error) {
+	a, aVector, err := toMatrix(x)
+	if err != nil {
+		return nil, err
+	}
+	b, bVector, err := toMatrix(y)
+	if err != nil {
+		return nil, err
+	}
+	if aVector && bVector {
+		dot, err := e.calc.Dot(a.Data, b.Data)
+		if err != nil {
+			return nil, err
+		}
+		return e.checkElements("matrix product", Float(dot), x, y)
+	}
+	if bVector {
+		b = e.calc.Transpose(b)
+	}
+	product, err := e.calc.MatMultiply(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if aVector || bVector {
+		return e.checkElements("matrix product", fromVector(product.Data), x, y)
+	}
+	return e.checkElements("matrix product", fromMatrix(product), x, y)
+}
+
+// listArith applies an operator element by element when an operand is a list,
+// pairing the elements of two lists of the same length and repeating any other
+// operand, so [1, 2] * 3 is [3, 6]. Elements keep their own kind, so lists of
+// fractions stay exact and lists of quantities keep their units.
+func (e *Evaluator) listArith(op string, x, y Value) (Value, error) {
+	if op == "@" {
+		return e.matMul(x, y)
+	}
+	a, aList := x.(List)
+	b, bList := y.(List)
+	if aList && bList && len(a) != len(b) {
+		return nil, fmt.Errorf("cannot apply %s to lists of length %d and %d", op, len(a), len(b))
+	}
+	n := len(a)
+	if !aList {
+		n = len(b)
+	}
+	result := make(List, n)
+	for i := range result {
+		ai, bi := x, y
+		if aList {
+			ai = a[i]
+		}
+		if bList {
+			bi = b[i]
+		}
+		v, err := e.arith(op, ai, bi)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// isList reports whether v is a List
+func isList(v Value) bool {
+	_, ok := v.(List)
+	return ok
+}
@@ -0,0 +1,88 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestMatrices(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"A = [[1, 2], [3, 4]]", "[[1, 2], [3, 4]]"},
+		{"A + 1", "[[2, 3], [4, 5]]"},
+		{"2 * A", "[[2, 4], [6, 8]]"},
+		{"A * A", "[[1, 4], [9, 16]]"},
+		{"A - A", "[[0, 0], [0, 0]]"},
+		{"-A", "[[-1, -2], [-3, -4]]"},
+		{"A @ A", "[[7, 10], [15, 22]]"},
+		{"A @ [1, 2]", "[5, 11]"},
+		{"[1, 2] @ A", "[7, 10]"},
+		{"[1, 2, 3] @ [4, 5, 6]", "32"},
+		{"[1, 2] + [10, 20]", "[11, 22]"},
+		{"[1 m, 2 m] * 2", "[2 m, 4 m]"},
+		{"transpose(A)", "[[1, 3], [2, 4]]"},
+		{"transpose([1, 2])", "[[1], [2]]"},
+		{"transpose([[1, 2, 3]])", "[[1], [2], [3]]"},
+		{"det(A)", "-2"},
+		{"det([[1, 2, 3], [4, 5, 6], [7, 8, 10]])", "-3"},
+		{"det([[1, 2], [2, 4]])", "0"},
+		{"inv(A)", "[[-2, 1], [1.5, -0.5]]"},
+		{"A @ inv(A)", "[[1, 0], [0, 1]]"},
+		{"rank(A)", "2"},
+		{"rank([[1, 2, 3], [2, 4, 6]])", "1"},
+		{"linsolve(A, [5, 11])", "[1, 2]"},
+		{"linsolve(A, [[5], [11]])", "[[1], [2]]"},
+		{"linsolve([[2, 1, -1], [-3, -1, 2], [-2, 1, 2]], [8, -11, -3])", "[2, 3, -1]"},
+		{"dot([1, 2, 3], [4, 5, 6])", "32"},
+		{"cross([1, 0, 0], [0, 1, 0])", "[0, 0, 1]"},
+		{"sum(A)", "10"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{
+		"[1, 2] + [1]",
+		"3 @ 4",
+		"A @ [1, 2, 3]",
+		"[[1, 2], [3]] @ A",
+		"det([1, 2])",
+		"det([[1, 2, 3], [4, 5, 6]])",
+		"inv([[1, 2], [2, 4]])",
+		"linsolve(A, [1, 2, 3])",
+		"dot(A, A)",
+		"cross([1, 2], [3, 4])",
+		"det(3)",
+		"det([])",
+		"det(A, A)",
+		"[1, 2]%",
+	} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestMatricesInRationalMode(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.RationalMode)
+	e := NewEvaluator(calc, nil)
+	if v, err := e.Evaluate("[1/3, 1/2] * 3"); err != nil || v.String() != "[1, 3/2]" {
+		t.Errorf("Expected element-wise [1, 3/2] in rational mode, got %v (err: %v)", v, err)
+	}
+	if v, err := e.Evaluate("det([[1/2, 1], [1, 4]])"); err != nil || v.String() != "1" {
+		t.Errorf("Expected det 1 in rational mode, got %v (err: %v)", v, err)
+	}
+}
@@ -0,0 +1,146 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// maxExactFloat is 2^53, above which float64 cannot represent every integer
+var maxExactFloat = new(big.Int).Lsh(big.NewInt(1), 53)
+
+// intFunc describes a function of whole numbers, computed exactly in big
+// integers whatever the number mode
+type intFunc struct {
+	minArgs int
+	maxArgs int // variadic for no upper bound, in which case lists are spread
+	fn      func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error)
+	// list, when set instead of fn, returns several integers as a list
+	list func(calc *calculator.Calculator, args []*big.Int) ([]*big.Int, error)
+}
+
+// intFuncs holds the combinatorics and number theory functions, keyed by
+// lower-case name
+var intFuncs = map[string]intFunc{
+	"ncr": {2, 2, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.Choose(args[0], args[1])
+	}, nil},
+	"npr": {2, 2, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.Permutations(args[0], args[1])
+	}, nil},
+	"gcd": {1, variadic, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.GCD(args...), nil
+	}, nil},
+	"lcm": {1, variadic, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.LCM(args...), nil
+	}, nil},
+	// isprime is 1 for a prime and 0 otherwise, for use in if
+	"isprime": {1, 1, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		if calc.IsPrime(args[0]) {
+			return big.NewInt(1), nil
+		}
+		return new(big.Int), nil
+	}, nil},
+	"nextprime": {1, 1, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.NextPrime(args[0]), nil
+	}, nil},
+	"factor": {1, 1, nil, func(calc *calculator.Calculator, args []*big.Int) ([]*big.Int, error) {
+		return calc.Factor(args[0])
+	}},
+	"modpow": {3, 3, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.ModPow(args[0], args[1], args[2])
+	}, nil},
+	"modinv": {2, 2, func(calc *calculator.Calculator, args []*big.Int) (*big.Int, error) {
+		return calc.ModInverse(args[0], args[1])
+	}, nil},
+}
+
+// callInt evaluates the arguments of an integer function, which must be whole
+// numbers, and converts its exact result to the current number mode
+func (e *Evaluator) callInt(name string, fn intFunc, nodes []Node) (Value, error) {
+	if err := (builtin{minArgs: fn.minArgs, maxArgs: fn.maxArgs}).checkArity(name, len(nodes)); err != nil {
+		return nil, err
+	}
+	args, err := e.evalArgs(nodes)
+	if err != nil {
+		return nil, err
+	}
+	if fn.maxArgs == variadic {
+		if args = spread(args); len(args) == 0 {
+			return nil, fmt.Errorf("%s requires at least one value", name)
+		}
+	}
+	ints := make([]*big.Int, len(args))
+	for i, arg := range args {
+		if ints[i], err = toInt(arg); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		// A float64 above 2^53 may have been rounded, e.g. 2^120 + 1, so the
+		// function would silently answer for a different integer
+		if _, ok := arg.(Float); ok && new(big.Int).Abs(ints[i]).Cmp(maxExactFloat) > 0 {
+			return nil, fmt.Errorf("%s: %s is above 2^53 and may have been rounded; use rational mode for exact integers", name, arg)
+		}
+	}
+
+	if fn.list == nil {
+		return e.exactInt(fn.fn(e.calc, ints))
+	}
+	xs, err := fn.list(e.calc, ints)
+	if err != nil {
+		return nil, err
+	}
+	list := make(List, len(xs))
+	for i, x := range xs {
+		if list[i], err = e.exactInt(x, nil); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// evalFactorial computes n! exactly for a whole number n, and as gamma(n + 1)
+// for any other real number
+func (e *Evaluator) evalFactorial(n *Factorial) (Value, error) {
+	x, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	if hasQuantity(x) || isTemporal(x) || isList(x) || isPercent(x) {
+		return nil, fmt.Errorf("cannot take the factorial of %s", x)
+	}
+	if i, err := toInt(x); err == nil {
+		return e.exactInt(e.calc.Factorial(i))
+	}
+	f, err := e.toFloat(x)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.calc.Gamma(f + 1)
+	if err != nil {
+		return nil, err
+	}
+	return e.checkFloat("!", r, f)
+}
+
+// exactInt returns an integer result in the current number mode: exactly as an
+// integer, fraction or arbitrary precision number, and in float mode as a
+// float64 up to 2^53 and as an exact integer fraction above, which float64
+// would round
+func (e *Evaluator) exactInt(x *big.Int, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case e.integer():
+		return intResult(e.calc.Fit(x))
+	case e.rational():
+		return Rat{new(big.Rat).SetInt(x)}, nil
+	case e.precise():
+		return BigFloat{new(big.Float).SetPrec(e.calc.Precision()).SetMode(e.calc.RoundingMode()).SetInt(x)}, nil
+	case new(big.Int).Abs(x).Cmp(maxExactFloat) > 0:
+		return Rat{new(big.Rat).SetInt(x)}, nil
+	}
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return Float(f), nil
+}
@@ -0,0 +1,106 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestNumberTheory(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"5!", "120"},
+		{"0!", "1"},
+		{"-3!", "-6"},
+		{"2^3!", "64"},
+		{"3!!", "720"},
+		{"(2 + 1)! * 2", "12"},
+		{"0.5!", "0.8862269254527579"},
+		{"gamma(5)", "24"},
+		{"round(gamma(0.5)^2 * 1e12) / 1e12", "3.14159265359"},
+		{"nCr(10, 3)", "120"},
+		{"NPR(10, 3)", "720"},
+		{"ncr(4, 5)", "0"},
+		{"gcd(12, 18)", "6"},
+		{"gcd([12, 18, 8])", "2"},
+		{"lcm(4, 6, 10)", "60"},
+		{"isprime(97)", "1"},
+		{"isprime(91)", "0"},
+		{"nextprime(100)", "101"},
+		{"factor(360)", "[2, 2, 2, 3, 3, 5]"},
+		{"factor(1)", "[]"},
+		{"modpow(4, 13, 497)", "445"},
+		{"modpow(3, -1, 7)", "5"},
+		{"modinv(3, 11)", "4"},
+		{"f(n) = if(n, n * f(n - 1), 1)", ""},
+		{"f(6) - 6!", "0"},
+		{"simplify(2 * x! + x!)", "3 * x!"},
+		{"25!", "15511210043330985984000000"},
+		{"big = nCr(100, 50)", "100891344545564193334812497256"},
+		{"isprime(big)", "0"},
+		{"modpow(30!, 2, 1000003)", "136018"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if test.expected != "" && v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	errors := []struct {
+		src     string
+		message string
+	}{
+		{"(-2)!", "factorial of negative integer -2"},
+		{"(5 m)!", "cannot take the factorial of 5 m"},
+		{"gamma(-1)", "gamma is undefined at -1"},
+		{"gcd(1.5, 3)", "gcd: expected an integer, got 1.5"},
+		{"gcd([])", "gcd requires at least one value"},
+		{"ncr(5)", "ncr expects 2 argument(s), got 1"},
+		{"factor(0)", "can only factor positive integers"},
+		{"modinv(6, 9)", "6 has no inverse modulo 9"},
+		{"modpow(2, 3, 0)", "modulus must be positive"},
+		{"diff(x!, x)", "cannot differentiate x!"},
+		{"gcd(x) = x", "cannot redefine built-in function"},
+		{"factor(2^120 + 1)", "is above 2^53 and may have been rounded"},
+	}
+	for _, test := range errors {
+		_, err := e.Evaluate(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Evaluate(%q): expected error containing %q, got %v", test.src, test.message, err)
+		}
+	}
+}
+
+func TestNumberTheoryModes(t *testing.T) {
+	calc := calculator.New()
+	e := NewEvaluator(calc, nil)
+
+	calc.SetNumberMode(calculator.RationalMode)
+	v, err := e.Evaluate("25!")
+	if err != nil || v.String() != "15511210043330985984000000" {
+		t.Errorf("25! in rational mode: expected the exact factorial, got %v (%v)", v, err)
+	}
+	v, err = e.Evaluate("factor(2^64 + 1)")
+	if err != nil || v.String() != "[274177, 67280421310721]" {
+		t.Errorf("factor(2^64 + 1) in rational mode: expected [274177, 67280421310721], got %v (%v)", v, err)
+	}
+
+	calc.SetNumberMode(calculator.IntegerMode)
+	calc.SetOverflowMode(calculator.OverflowError)
+	if v, err = e.Evaluate("20!"); err != nil || v.String() != "2432902008176640000" {
+		t.Errorf("20! in integer mode: expected 2432902008176640000, got %v (%v)", v, err)
+	}
+	if _, err = e.Evaluate("21!"); err == nil || !strings.Contains(err.Error(), "overflow") {
+		t.Errorf("21! in integer mode: expected an overflow error, got %v", err)
+	}
+}
@@ -0,0 +1,528 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+// binaryPrecedence maps infix operators to their binding power; higher binds tighter.
+// Exponentiation is handled separately in parsePower because it is right-associative
+// and binds tighter than unary minus. The bitwise operators bind looser than
+// arithmetic, so 1 << 4 - 1 is 1 << 3 and x & 0xf + 1 is x & 0x10. "of" binds
+// tighter than multiplication, so 2 * 20% of 80 is 2 * 16.
+var binaryPrecedence = map[string]int{
+	"|":   1,
+	"xor": 2,
+	"&":   3,
+	"<<":  4,
+	">>":  4,
+	"+":   5,
+	"-":   5,
+	"*":   6,
+	"/":   6,
+	"//":  6,
+	"%":   6,
+	"mod": 6,
+	"@":   6,
+	"of":  7,
+}
+
+// Parse parses a complete statement, either an expression, an assignment of the
+// form name = expression or a function definition of the form name(params) = body,
+// and returns its syntax tree
+func Parse(src string) (Node, error) {
+	tokens, err := Tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind != TokenEOF {
+		err := unexpected(tok, "operator", TokenEOF.String())
+		switch {
+		case tok.Kind == TokenRParen:
+			err.Hint = "this ')' has no matching '('"
+		case tok.Kind == TokenIdent && p.tokens[p.pos-1].Kind == TokenNumber:
+			err.Hint = fmt.Sprintf("%s is not a known unit; write * to multiply", tok)
+		case startsOperand(tok):
+			err.Hint = "write an operator between the operands, such as * to multiply"
+		}
+		return nil, err
+	}
+	return node, nil
+}
+
+// parser is a recursive-descent parser using precedence climbing for binary operators
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseStatement parses an assignment when the input starts with "name =",
+// a function definition when it starts with "name(params) =", otherwise a
+// plain expression
+func (p *parser) parseStatement() (Node, error) {
+	if params, bodyStart, ok := p.definitionHeader(); ok {
+		name := p.tokens[0]
+		p.pos = bodyStart
+		body, err := p.parseFull()
+		if err != nil {
+			return nil, err
+		}
+		fn := &Function{Name: name.Text, Params: params, Body: body}
+		return &FuncDef{Func: fn, Offset: name.Pos}, nil
+	}
+
+	if len(p.tokens) > 2 && p.tokens[0].Kind == TokenIdent && p.tokens[1].Kind == TokenAssign {
+		name := p.next()
+		p.next() // consume '='
+		value, err := p.parseFull()
+		if err != nil {
+			return nil, err
+		}
+		return &Assign{Name: name.Text, Value: value, Offset: name.Pos}, nil
+	}
+	return p.parseFull()
+}
+
+// parseFull parses an expression followed by any number of unit conversions,
+// x to unit or x in unit, time zone changes, t in Europe/Paris, and percentage
+// ratios, x as % of y, which bind looser than every operator
+func (p *parser) parseFull() (Node, error) {
+	node, err := p.parseExpression(1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.Kind != TokenOperator || (tok.Text != "to" && tok.Text != "in" && tok.Text != "as") {
+			return node, nil
+		}
+		p.next()
+		if tok.Text == "as" {
+			if node, err = p.parseAsPercent(node, tok); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if zone, ok := p.parseZone(); ok {
+			node = &InZone{X: node, Zone: zone, Offset: tok.Pos}
+			continue
+		}
+		unit, err := p.parseUnit(true)
+		if err != nil {
+			return nil, err
+		}
+		node = &Convert{X: node, Unit: unit, Offset: tok.Pos}
+	}
+}
+
+// parseAsPercent parses the remainder of x as % or x as % of y
+func (p *parser) parseAsPercent(x Node, as Token) (Node, error) {
+	if tok := p.next(); tok.Kind != TokenOperator || tok.Text != "%" {
+		err := syntaxError(tok.Pos, len(tok.Text), "expected '%%' after as but found %s", tok)
+		err.Expected = []string{"'%'"}
+		return nil, err
+	}
+	node := &AsPercent{X: x, Offset: as.Pos}
+	if tok := p.peek(); tok.Kind == TokenOperator && tok.Text == "of" {
+		p.next()
+		of, err := p.parseExpression(1)
+		if err != nil {
+			return nil, err
+		}
+		node.Of = of
+	}
+	return node, nil
+}
+
+// parseZone parses a time zone name such as UTC or America/New_York when one
+// follows and is not also a unit. It leaves the position unchanged when there
+// is none.
+func (p *parser) parseZone() (string, bool) {
+	if p.peek().Kind != TokenIdent || p.atUnit(p.pos) {
+		return "", false
+	}
+	start := p.pos
+	zone := p.next().Text
+	for p.peek().Kind == TokenOperator && p.peek().Text == "/" && p.tokens[p.pos+1].Kind == TokenIdent {
+		p.next()
+		zone += "/" + p.next().Text
+	}
+	if _, err := loadLocation(zone); err != nil {
+		p.pos = start
+		return "", false
+	}
+	return zone, true
+}
+
+// definitionHeader reports whether the input begins with a function definition
+// header name(a, b, ...) = and returns the parameter names along with the index
+// of the first token of the body
+func (p *parser) definitionHeader() ([]string, int, bool) {
+	toks := p.tokens
+	if len(toks) < 4 || toks[0].Kind != TokenIdent || toks[1].Kind != TokenLParen {
+		return nil, 0, false
+	}
+	params := []string{}
+	i := 2
+	if toks[i].Kind != TokenRParen {
+		for {
+			if toks[i].Kind != TokenIdent {
+				return nil, 0, false
+			}
+			params = append(params, toks[i].Text)
+			i++
+			if toks[i].Kind == TokenComma {
+				i++
+				continue
+			}
+			if toks[i].Kind != TokenRParen {
+				return nil, 0, false
+			}
+			break
+		}
+	}
+	if toks[i+1].Kind != TokenAssign {
+		return nil, 0, false
+	}
+	return params, i + 2, true
+}
+
+// parseExpression parses a chain of binary operators whose precedence is at least minPrec
+func (p *parser) parseExpression(minPrec int) (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.Kind != TokenOperator {
+			return left, nil
+		}
+		prec, ok := binaryPrecedence[tok.Text]
+		if !ok || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+
+		// All operators handled here are left-associative, so the right operand
+		// may only contain operators that bind strictly tighter
+		right, err := p.parseExpression(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = minutesBesideTimes(&Binary{Op: tok.Text, X: left, Y: right, Offset: tok.Pos})
+	}
+}
+
+// minutesBesideTimes reads a number of m written against it, as in 15m, as
+// minutes rather than metres beside a duration literal such as 1h30m, so that
+// 1h30m + 15m is 1h45m. Only the divisor of a duration reads so, as 15m / 1h30m
+// is a speed. Anywhere else m is metres, so 1 m + 1 s is an error.
+func minutesBesideTimes(n *Binary) *Binary {
+	switch n.Op {
+	case "+", "-", "%", "//", "mod":
+		if isDurationLit(n.Y) {
+			n.X = asMinutes(n.X)
+		}
+		if isDurationLit(n.X) {
+			n.Y = asMinutes(n.Y)
+		}
+	case "/":
+		if isDurationLit(n.X) {
+			n.Y = asMinutes(n.Y)
+		}
+	}
+	return n
+}
+
+// isDurationLit reports whether n is a duration literal, possibly negated
+func isDurationLit(n Node) bool {
+	switch n := n.(type) {
+	case *DurationLit:
+		return true
+	case *Unary:
+		return isDurationLit(n.X)
+	}
+	return false
+}
+
+// asMinutes rewrites a number of metres written as in 15m, possibly negated,
+// as minutes
+func asMinutes(n Node) Node {
+	switch n := n.(type) {
+	case *Measure:
+		if n.Unit == "m" && n.Joined {
+			return &Measure{X: n.X, Unit: "min", Joined: true, Offset: n.Offset}
+		}
+	case *Unary:
+		return &Unary{Op: n.Op, X: asMinutes(n.X), Offset: n.Offset}
+	}
+	return n
+}
+
+// parseUnary parses optional prefix signs and bitwise complement; -2^2 is -(2^2)
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.Kind == TokenOperator && (tok.Text == "-" || tok.Text == "+" || tok.Text == "~") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: tok.Text, X: operand, Offset: tok.Pos}, nil
+	}
+	return p.parsePower()
+}
+
+// parsePower parses right-associative exponentiation; 2^3^2 is 2^(3^2)
+func (p *parser) parsePower() (Node, error) {
+	base, err := p.parsePercentage()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.Kind != TokenOperator || tok.Text != "^" {
+		return base, nil
+	}
+	p.next()
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &Binary{Op: "^", X: base, Y: exponent, Offset: tok.Pos}, nil
+}
+
+// parsePercentage parses a primary expression with optional factorial signs and
+// an optional percent sign. A % followed by something that can start an
+// operand, as in 7 % 4, is the remainder operator; anywhere else, as in
+// 50 + 10% or 10% - 5, it makes a percentage. A negative divisor therefore
+// needs mod: 7 mod -4.
+func (p *parser) parsePercentage() (Node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	// Factorials bind tighter than powers, so 2^3! is 2^6 and -3! is -6
+	for tok := p.peek(); tok.Kind == TokenOperator && tok.Text == "!"; tok = p.peek() {
+		p.next()
+		x = &Factorial{X: x, Offset: tok.Pos}
+	}
+	if tok := p.peek(); tok.Kind == TokenOperator && tok.Text == "%" && !startsOperand(p.tokens[p.pos+1]) {
+		p.next()
+		return &Percentage{X: x, Offset: tok.Pos}, nil
+	}
+	return x, nil
+}
+
+// startsOperand reports whether tok can begin a primary expression
+func startsOperand(tok Token) bool {
+	switch tok.Kind {
+	case TokenNumber, TokenImaginary, TokenIdent, TokenLParen, TokenLBracket, TokenHistory, TokenDate, TokenTime, TokenDuration:
+		return true
+	}
+	return false
+}
+
+// parsePrimary parses literals, identifiers, function calls and parenthesized expressions
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.Kind {
+	case TokenNumber:
+		return p.parseMeasure(&Number{Value: tok.Value, Text: tok.Text, Offset: tok.Pos})
+	case TokenImaginary:
+		return &Number{Value: tok.Value, Text: strings.TrimSuffix(tok.Text, "i"), Imag: true, Offset: tok.Pos}, nil
+	case TokenHistory:
+		return &HistoryRef{Index: int(tok.Value), Offset: tok.Pos}, nil
+	case TokenIdent:
+		if p.peek().Kind == TokenLParen {
+			return p.parseCall(tok)
+		}
+		return &Ident{Name: tok.Text, Offset: tok.Pos}, nil
+	case TokenDate:
+		// A date followed by a time of day, as in 2026-10-16 14:30, is one moment
+		if next := p.peek(); next.Kind == TokenTime {
+			p.next()
+			return &DateLit{Text: tok.Text + " " + next.Text, Offset: tok.Pos}, nil
+		}
+		return &DateLit{Text: tok.Text, Offset: tok.Pos}, nil
+	case TokenTime:
+		return &DateLit{Text: tok.Text, Offset: tok.Pos}, nil
+	case TokenDuration:
+		return &DurationLit{Text: tok.Text, Offset: tok.Pos}, nil
+	case TokenLBracket:
+		return p.parseList(tok)
+	case TokenLParen:
+		inner, err := p.parseFull()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.next(); next.Kind != TokenRParen {
+			err := expectedToken(next, TokenRParen.String())
+			err.Hint = unclosed(tok, next)
+			return nil, err
+		}
+		return inner, nil
+	default:
+		err := unexpected(tok, operandTokens...)
+		switch tok.Kind {
+		case TokenEOF:
+			err.Hint = "the expression is incomplete"
+		case TokenOperator:
+			err.Hint = fmt.Sprintf("%s needs an operand before it", tok)
+		}
+		return nil, err
+	}
+}
+
+// unclosed hints at the bracket left open when the input ends before it is
+// closed
+func unclosed(open, found Token) string {
+	if found.Kind != TokenEOF {
+		return ""
+	}
+	return fmt.Sprintf("close the %s at position %d", open, open.Pos)
+}
+
+// parseCall parses the parenthesized argument list following a function name
+func (p *parser) parseCall(name Token) (Node, error) {
+	open := p.next()
+	call := &Call{Name: name.Text, Offset: name.Pos}
+	if p.peek().Kind == TokenRParen {
+		p.next()
+		return call, nil
+	}
+	for {
+		arg, err := p.parseFull()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+
+		tok := p.next()
+		switch tok.Kind {
+		case TokenComma:
+			continue
+		case TokenRParen:
+			return call, nil
+		default:
+			err := expectedToken(tok, TokenComma.String(), TokenRParen.String())
+			err.Hint = unclosed(open, tok)
+			return nil, err
+		}
+	}
+}
+
+// parseList parses the elements of a list literal following its '['
+func (p *parser) parseList(open Token) (Node, error) {
+	list := &ListLit{Offset: open.Pos}
+	if p.peek().Kind == TokenRBracket {
+		p.next()
+		return list, nil
+	}
+	for {
+		elem, err := p.parseFull()
+		if err != nil {
+			return nil, err
+		}
+		list.Elems = append(list.Elems, elem)
+
+		tok := p.next()
+		switch tok.Kind {
+		case TokenComma:
+			continue
+		case TokenRBracket:
+			return list, nil
+		default:
+			err := expectedToken(tok, TokenComma.String(), TokenRBracket.String())
+			err.Hint = unclosed(open, tok)
+			return nil, err
+		}
+	}
+}
+
+// parseMeasure attaches a unit to a number literal when a unit name follows it,
+// as in 5 km or 9.81 m/s^2
+func (p *parser) parseMeasure(num *Number) (Node, error) {
+	if !p.atUnit(p.pos) {
+		return num, nil
+	}
+	joined := p.peek().Pos == num.Offset+len(num.Text)
+	unit, err := p.parseUnit(false)
+	if err != nil {
+		return nil, err
+	}
+	return &Measure{X: num, Unit: unit, Joined: joined, Offset: num.Offset}, nil
+}
+
+// atUnit reports whether the token at index i is a unit name rather than, say,
+// the name of a function being called
+func (p *parser) atUnit(i int) bool {
+	tok := p.tokens[i]
+	if tok.Kind != TokenIdent || !units.IsUnit(tok.Text) {
+		return false
+	}
+	return p.tokens[i+1].Kind != TokenLParen
+}
+
+// parseUnit parses a unit such as km, m/s^2 or kW h and returns it in the form
+// accepted by units.Parse. Juxtaposed units and / always belong to the unit;
+// * only does when allowStar is set, as after a conversion keyword, because
+// 60 W * 3 h multiplies two quantities.
+func (p *parser) parseUnit(allowStar bool) (string, error) {
+	var b strings.Builder
+	for {
+		tok := p.next()
+		if tok.Kind != TokenIdent {
+			return "", expectedToken(tok, "unit")
+		}
+		if !units.IsUnit(tok.Text) {
+			return "", syntaxError(tok.Pos, len(tok.Text), "unknown unit %q", tok.Text)
+		}
+		b.WriteString(tok.Text)
+
+		if next := p.peek(); next.Kind == TokenOperator && next.Text == "^" {
+			p.next()
+			sign := ""
+			if tok := p.peek(); tok.Kind == TokenOperator && (tok.Text == "-" || tok.Text == "+") {
+				sign = p.next().Text
+			}
+			exp := p.next()
+			if exp.Kind != TokenNumber || exp.Value != float64(int(exp.Value)) {
+				err := syntaxError(exp.Pos, len(exp.Text), "expected an integer unit exponent but found %s", exp)
+				err.Expected = []string{"integer"}
+				return "", err
+			}
+			b.WriteString("^" + strings.TrimPrefix(sign, "+") + exp.Text)
+		}
+
+		next := p.peek()
+		switch {
+		case p.atUnit(p.pos):
+			b.WriteString("*")
+		case next.Kind == TokenOperator && (next.Text == "/" || (next.Text == "*" && allowStar)) && p.atUnit(p.pos+1):
+			b.WriteString(p.next().Text)
+		default:
+			return b.String(), nil
+		}
+	}
+}
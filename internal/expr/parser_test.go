@@ -0,0 +1,115 @@
+package expr
+
+import "testing"
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"10 - 4 - 3", "((10 - 4) - 3)"},
+		{"8 / 4 / 2", "((8 / 4) / 2)"},
+		{"2 ^ 3 ^ 2", "(2 ^ (3 ^ 2))"},
+		{"-2 ^ 2", "(-(2 ^ 2))"},
+		{"2 ^ -1", "(2 ^ (-1))"},
+		{"--3", "(-(-3))"},
+		{"+4 % 3", "((+4) % 3)"},
+		{"sqrt(1 + 3) * 2", "(sqrt((1 + 3)) * 2)"},
+		{"f(1, 2, x)", "f(1, 2, x)"},
+		{"f()", "f()"},
+		{"total = 1200 * (1 + rate)", "total = (1200 * (1 + rate))"},
+		{"f(x, y) = x^2 + y", "f(x, y) = ((x ^ 2) + y)"},
+		{"k() = 4", "k() = 4"},
+		{"f(x, 2)", "f(x, 2)"},
+		{"1 << 4 - 1", "(1 << (4 - 1))"},
+		{"a | b xor c & d", "(a | (b xor (c & d)))"},
+		{"~x & 0xff", "((~x) & 255)"},
+		{"7 // 2 * 3", "((7 // 2) * 3)"},
+		{"5 km + 300 m to mi", "(((5 km) + (300 m)) to mi)"},
+		{"9.81 m/s^2 * 2 kg", "((9.81 m/s^2) * (2 kg))"},
+		{"60 W * 3 h in kWh", "(((60 W) * (3 h)) to kWh)"},
+		{"2 kW h to J", "((2 kW*h) to J)"},
+		{"x to km/h to m * s^-1", "((x to km/h) to m*s^-1)"},
+		{"sqrt(4 m^2 to cm^2)", "sqrt(((4 m^2) to cm^2))"},
+		{"2026-12-25 - today", "(2026-12-25 - today)"},
+		{"2026-10-16 14:30 + 1h30m", "(2026-10-16 14:30 + 1h30m)"},
+		{"now + 90 d in America/New_York", "((now + (90 d)) in America/New_York)"},
+		{"3h20m * 4 to min", "((3h20m * 4) to min)"},
+		{"1h30m + 15m", "(1h30m + (15 min))"},
+		{"1h30m + 15 m", "(1h30m + (15 m))"},
+		{"5m - 2 * 1h", "((5 m) - (2 * (1 h)))"},
+		{"15m / 1h30m", "((15 m) / 1h30m)"},
+		{"50 + 10%", "(50 + (10%))"},
+		{"7 % 4", "(7 % 4)"},
+		{"x% - y", "((x%) - y)"},
+		{"20% of 80 + 1", "(((20%) of 80) + 1)"},
+		{"-3!", "(-(3!))"},
+		{"2 ^ 3!", "(2 ^ (3!))"},
+		{"n!! * 2", "(((n!)!) * 2)"},
+		{"5!%", "((5!)%)"},
+		{"2 * 20% of 80", "(2 * ((20%) of 80))"},
+		{"7 mod 4 * 2", "((7 mod 4) * 2)"},
+		{"30 as % of 120", "(30 as % of 120)"},
+		{"1 / 8 as %", "((1 / 8) as %)"},
+		{"(5%)^2", "((5%) ^ 2)"},
+		{"[1, 2 + 3, x]", "[1, (2 + 3), x]"},
+		{"mean([])", "mean([])"},
+		{"sum([1, [2, 3]]) * 2", "(sum([1, [2, 3]]) * 2)"},
+		{"7 % [1]", "(7 % [1])"},
+		{"A @ x + b", "((A @ x) + b)"},
+		{"[[1, 2], [3, 4]] @ [1, 2]", "([[1, 2], [3, 4]] @ [1, 2])"},
+	}
+
+	for _, test := range tests {
+		node, err := Parse(test.src)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.src, err)
+			continue
+		}
+		if got := node.String(); got != test.expected {
+			t.Errorf("Parse(%q): expected %s, got %s", test.src, test.expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"5 /",
+		"(1 + 2",
+		"1 + 2)",
+		"3 4",
+		"sqrt(1,",
+		"sqrt(1 2)",
+		"* 3",
+		"x =",
+		"= 3",
+		"1 = 2",
+		"x = y = 3",
+		"f(x) =",
+		"f(1) = 2",
+		"f(x,) = 2",
+		"5 km to",
+		"5 km to 3",
+		"5 km to parsec",
+		"5 m^x",
+		"5 m^1.5",
+		"2 min(1, 3)",
+		"5 as 3",
+		"5 as % of",
+		"10% x 5",
+		"[1, 2",
+		"[1 2]",
+		"[1,]",
+		"1, 2]",
+		"% 5",
+	}
+
+	for _, src := range tests {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Expected parse error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package expr
+
+import "fmt"
+
+// pctChangeFunc is the percent change pctchange(old, new). Like if it is handled
+// by the evaluator rather than the builtins table, because its result is a
+// Percent in the current number mode rather than a float64.
+const pctChangeFunc = "pctchange"
+
+// Percent is a percentage such as 10%. X holds the number of percent, so its
+// value as a plain number is X/100.
+type Percent struct {
+	X Value
+}
+
+// String formats the percentage with a percent sign
+func (p Percent) String() string {
+	return p.X.String() + "%"
+}
+
+// hundred returns 100 in the current number mode
+func (e *Evaluator) hundred() (Value, error) {
+	return e.evalNumber(&Number{Value: 100, Text: "100"})
+}
+
+// fraction returns the value of a percentage as a plain number, e.g. 0.1 for 10%
+func (e *Evaluator) fraction(p Percent) (Value, error) {
+	hundred, err := e.hundred()
+	if err != nil {
+		return nil, err
+	}
+	return e.arith("/", p.X, hundred)
+}
+
+// percentArith applies an operator when an operand is a percentage. Adding or
+// subtracting a percentage changes the other operand by that share of itself,
+// so 50 + 10% is 55; two percentages add as percentage points. Scaling by a
+// plain number keeps a percentage, so 10% * 2 is 20% and 10% / 4 is 2.5%. In
+// every other case a percentage stands for its fraction, so 20 / 10% is 200.
+func (e *Evaluator) percentArith(op string, x, y Value) (Value, error) {
+	p, xPercent := x.(Percent)
+	q, yPercent := y.(Percent)
+	if op == "+" || op == "-" {
+		switch {
+		case xPercent && yPercent:
+			sum, err := e.arith(op, p.X, q.X)
+			if err != nil {
+				return nil, err
+			}
+			return Percent{sum}, nil
+		case yPercent:
+			// Multiply before dividing so that integer mode keeps 50 + 10% exact
+			share, err := e.arith("*", x, q.X)
+			if err != nil {
+				return nil, err
+			}
+			hundred, err := e.hundred()
+			if err != nil {
+				return nil, err
+			}
+			if share, err = e.arith("/", share, hundred); err != nil {
+				return nil, err
+			}
+			return e.arith(op, x, share)
+		}
+	}
+
+	switch {
+	case xPercent && isNumeric(y) && (op == "*" || op == "/"):
+		return percentResult(e.arith(op, p.X, y))
+	case yPercent && isNumeric(x) && op == "*":
+		return percentResult(e.arith(op, x, q.X))
+	}
+
+	var err error
+	if xPercent {
+		if x, err = e.fraction(p); err != nil {
+			return nil, err
+		}
+	}
+	if yPercent {
+		if y, err = e.fraction(q); err != nil {
+			return nil, err
+		}
+	}
+	return e.arith(op, x, y)
+}
+
+// percentResult wraps the result of arithmetic on a number of percent
+func percentResult(v Value, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Percent{v}, nil
+}
+
+// evalAsPercent expresses x, or the ratio of x to the Of operand, as a percentage
+func (e *Evaluator) evalAsPercent(n *AsPercent) (Value, error) {
+	x, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	if n.Of == nil {
+		return e.asPercent(x, nil)
+	}
+	of, err := e.Eval(n.Of)
+	if err != nil {
+		return nil, err
+	}
+	return e.asPercent(x, of)
+}
+
+// asPercent returns the ratio x/of as a Percent, e.g. 25% for 30 and 120, or x
+// itself as a Percent when of is nil. It multiplies before dividing so that
+// integer mode does not truncate the ratio to zero.
+func (e *Evaluator) asPercent(x, of Value) (Value, error) {
+	if p, ok := x.(Percent); ok && of == nil {
+		return p, nil
+	}
+	hundred, err := e.hundred()
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.arith("*", x, hundred)
+	if err != nil {
+		return nil, err
+	}
+	if of != nil {
+		if r, err = e.arith("/", r, of); err != nil {
+			return nil, err
+		}
+	}
+	if hasQuantity(r) || isTemporal(r) {
+		return nil, fmt.Errorf("cannot express %s as a percentage", r)
+	}
+	return Percent{r}, nil
+}
+
+// evalPctChange computes the percent change from old to new, (new - old) / old
+func (e *Evaluator) evalPctChange(n *Call) (Value, error) {
+	if len(n.Args) != 2 {
+		return nil, fmt.Errorf("%s expects 2 argument(s), got %d", n.Name, len(n.Args))
+	}
+	args, err := e.evalArgs(n.Args)
+	if err != nil {
+		return nil, err
+	}
+	if truthy, err := isTruthy(args[0]); err == nil && !truthy {
+		return nil, fmt.Errorf("percent change from zero is undefined")
+	}
+	diff, err := e.arith("-", args[1], args[0])
+	if err != nil {
+		return nil, err
+	}
+	return e.asPercent(diff, args[0])
+}
+
+// isPercent reports whether v is a Percent
+func isPercent(v Value) bool {
+	_, ok := v.(Percent)
+	return ok
+}
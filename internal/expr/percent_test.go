@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestPercentages(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"10%", "10%"},
+		{"50 + 10%", "55"},
+		{"50 - 10%", "45"},
+		{"200 + 12.5%", "225"},
+		{"20% of 80", "16"},
+		{"10% * 2", "20%"},
+		{"3 * 10%", "30%"},
+		{"10% / 4", "2.5%"},
+		{"20 / 10%", "200"},
+		{"10% * 80 + 1", "9"},
+		{"10% * 2 km", "0.2 km"},
+		{"10% + 5%", "15%"},
+		{"-10%", "-10%"},
+		{"100 - -10%", "110"},
+		{"30 as % of 120", "25%"},
+		{"0.125 as %", "12.5%"},
+		{"1 + 2 as % of 12", "25%"},
+		{"5 km + 10%", "5.5 km"},
+		{"25% of 2 h", "0.5 h"},
+		{"pctchange(80, 100)", "25%"},
+		{"pctchange(100, 80)", "-20%"},
+		{"sqrt(25%)", "0.5"},
+		{"x = 15%", "15%"},
+		{"40 + x", "46"},
+		{"7 % 4", "3"},
+		{"7 %4", "3"},
+		{"7 % (2 + 2)", "3"},
+		{"7 mod 4", "3"},
+		{"-7 mod 4", "-3"},
+		{"7 mod -4", "3"},
+		{"2 * 3 mod 4", "2"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{
+		"pctchange(0, 5)",
+		"pctchange(1)",
+		"5 km as % of 1 s",
+		"(2 m)%",
+		"5 as 3",
+		"5 as %%",
+	} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestPercentagesInOtherModes(t *testing.T) {
+	tests := []struct {
+		mode     calculator.NumberMode
+		src      string
+		expected string
+	}{
+		{calculator.IntegerMode, "50 + 10%", "55"},
+		{calculator.IntegerMode, "30 as % of 120", "25%"},
+		{calculator.RationalMode, "1/3 as %", "100/3%"},
+		{calculator.RationalMode, "20% of 1/2", "1/10"},
+	}
+	for _, test := range tests {
+		calc := calculator.New()
+		calc.SetNumberMode(test.mode)
+		e := NewEvaluator(calc, nil)
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	calc := calculator.New()
+	calc.SetPrecision(calculator.BitsForDigits(30))
+	e := NewEvaluator(calc, nil)
+	if v, err := e.Evaluate("1 + 0.1%"); err != nil || v.String() != "1.001" {
+		t.Errorf("Expected 1.001 in precision mode, got %v (err: %v)", v, err)
+	}
+}
+
+func TestRenderPercent(t *testing.T) {
+	calc := calculator.New()
+	e := NewEvaluator(calc, nil)
+	v, err := e.Evaluate("2 as % of 3")
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	f := Format{Notation: NotationFixed, Digits: 2}
+	if s, err := f.Render(calc, v); err != nil || s != "66.67%" {
+		t.Errorf("Expected 66.67%%, got %s (err: %v)", s, err)
+	}
+}
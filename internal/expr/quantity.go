@@ -0,0 +1,286 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+// Quantity is a magnitude with a unit of measure, such as 5 km. Quantities are
+// always float64, whatever the number mode.
+type Quantity struct {
+	Value float64
+	Unit  units.Unit
+}
+
+// String formats the magnitude followed by the unit, e.g. 5.3 km
+func (q Quantity) String() string {
+	return Float(q.Value).String() + " " + q.Unit.String()
+}
+
+// quantity combines a magnitude and a unit, collapsing results without a
+// dimension, such as m/km, to a plain number
+func quantity(x float64, u units.Unit) Value {
+	if u.Dim.IsZero() {
+		return Float(u.ToSI(x))
+	}
+	return Quantity{Value: x, Unit: u}
+}
+
+// dimensionless is the unit of a plain number
+var dimensionless = units.Unit{Factor: 1}
+
+// toQuantity converts a number to a dimensionless quantity and a duration to a
+// number of seconds
+func toQuantity(v Value) (Quantity, error) {
+	switch x := v.(type) {
+	case Quantity:
+		return x, nil
+	case Duration:
+		return Quantity{Value: time.Duration(x).Seconds(), Unit: second}, nil
+	}
+	f, err := ToFloat(v)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: f, Unit: dimensionless}, nil
+}
+
+// hasQuantity reports whether any of values carries a unit
+func hasQuantity(values ...Value) bool {
+	for _, v := range values {
+		if _, ok := v.(Quantity); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// in returns the magnitude of q expressed in unit u, which must be compatible.
+// Results are rounded to 15 significant digits so that 3 ft is 36 inch rather
+// than 36.00000000000001. When an offset is involved, as between degC and degF,
+// the subtraction leaves noise relative to the magnitudes it cancels rather than
+// to the result, so 32 degF is 0 degC rather than 5.7e-14 degC; such results
+// are rounded to 12 digits of the larger magnitude.
+func (q Quantity) in(u units.Unit) float64 {
+	si := q.Unit.ToSI(q.Value)
+	x := u.FromSI(si)
+	if q.Unit.Offset == 0 && u.Offset == 0 {
+		return roundSignificant(x, 15)
+	}
+	scale := math.Max(math.Abs(x), math.Abs(si/u.Factor))
+	if math.Abs(x) < scale*1e-12 {
+		return 0
+	}
+	return roundSignificant(x, max(12-int(math.Floor(math.Log10(scale/math.Abs(x)))), 1))
+}
+
+// by returns the magnitude of q as a difference in unit u, which must be
+// compatible, scaling without the offset: an increase of 5 K is one of 5 degC
+func (q Quantity) by(u units.Unit) float64 {
+	return roundSignificant(q.Value*q.Unit.Factor/u.Factor, 15)
+}
+
+// roundSignificant rounds x to the given number of significant digits, which
+// removes the noise left in the last bits by a chain of float64 operations
+func roundSignificant(x float64, digits int) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(x, 'g', digits, 64), 64)
+	if err != nil || math.IsInf(x, 0) || math.IsNaN(x) {
+		return x
+	}
+	return rounded
+}
+
+// quantityArith applies an arithmetic operator when at least one operand has a
+// unit. Sums, differences and remainders need operands of the same dimension and
+// are expressed in the left operand's unit, taking the right operand as a
+// difference without any offset; products and quotients combine units;
+// powers need a whole, dimensionless exponent.
+func (e *Evaluator) quantityArith(op string, x, y Value) (Value, error) {
+	a, err := toQuantity(x)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toQuantity(y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+", "-", "%", "//":
+		if !a.Unit.Compatible(b.Unit) {
+			return nil, fmt.Errorf("cannot apply %s to %s and %s: incompatible dimensions %s and %s", op, x, y, a.Unit.Dim, b.Unit.Dim)
+		}
+		if (op == "%" || op == "//") && a.Unit.Compatible(second) {
+			if v, ok := wholeNanoseconds(op, a, b); ok {
+				return v, nil
+			}
+		}
+		// The right operand is an increment, so 10 degC + 5 K is 15 degC
+		bv := b.by(a.Unit)
+		switch op {
+		case "+":
+			return e.checkQuantity(op, e.calc.Add(a.Value, bv), a.Unit, a.Value, bv)
+		case "-":
+			return e.checkQuantity(op, e.calc.Subtract(a.Value, bv), a.Unit, a.Value, bv)
+		case "%":
+			r, err := e.calc.ModFloat(a.Value, bv)
+			if err != nil {
+				return nil, err
+			}
+			return e.checkQuantity(op, r, a.Unit, a.Value, bv)
+		default:
+			// A whole number of times b fits into a, which has no unit
+			r, err := e.calc.Quotient(a.Value, bv)
+			if err != nil {
+				return nil, err
+			}
+			return e.checkFloat(op, r, a.Value, bv)
+		}
+	case "*":
+		// Scaling by a plain number keeps the unit as written, including any offset
+		r := e.calc.Multiply(a.Value, b.Value)
+		switch {
+		case !hasQuantity(y):
+			return e.checkQuantity(op, r, a.Unit, a.Value, b.Value)
+		case !hasQuantity(x):
+			return e.checkQuantity(op, r, b.Unit, a.Value, b.Value)
+		}
+		return e.checkQuantity(op, r, a.Unit.Mul(b.Unit), a.Value, b.Value)
+	case "/":
+		r, err := e.calc.Divide(a.Value, b.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !hasQuantity(y) {
+			return e.checkQuantity(op, r, a.Unit, a.Value, b.Value)
+		}
+		return e.checkQuantity(op, r, a.Unit.Div(b.Unit), a.Value, b.Value)
+	case "^":
+		if hasQuantity(y) {
+			return nil, fmt.Errorf("exponent %s must be a plain number", y)
+		}
+		if b.Value != math.Trunc(b.Value) || math.Abs(b.Value) > math.MaxInt32 {
+			return nil, fmt.Errorf("cannot raise %s to the power %s: exponent must be an integer", x, y)
+		}
+		return e.checkQuantity(op, e.calc.Power(a.Value, b.Value), a.Unit.Pow(int(b.Value)), a.Value, b.Value)
+	default:
+		return nil, fmt.Errorf("operator %s is not defined for quantities", op)
+	}
+}
+
+// evalMeasure attaches a unit to a number
+func (e *Evaluator) evalMeasure(n *Measure) (Value, error) {
+	v, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ToFloat(v)
+	if err != nil {
+		return nil, err
+	}
+	u, err := units.Parse(n.Unit)
+	if err != nil {
+		return nil, err
+	}
+	return quantity(f, u), nil
+}
+
+// evalConvert expresses a quantity in another unit of the same dimension
+func (e *Evaluator) evalConvert(n *Convert) (Value, error) {
+	v, err := e.Eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	target, err := units.Parse(n.Unit)
+	if err != nil {
+		return nil, err
+	}
+	q, err := toQuantity(v)
+	if err != nil {
+		return nil, err
+	}
+	if !q.Unit.Compatible(target) {
+		return nil, fmt.Errorf("cannot convert %s to %s: incompatible dimensions %s and %s", v, n.Unit, q.Unit.Dim, target.Dim)
+	}
+	x := q.in(target)
+	if math.IsInf(q.Value, 0) || math.IsNaN(q.Value) {
+		return Quantity{Value: x, Unit: target}, nil
+	}
+	x, err = e.check(fmt.Sprintf("%s to %s", v, n.Unit), x)
+	if err != nil {
+		return nil, err
+	}
+	return Quantity{Value: x, Unit: target}, nil
+}
+
+// callQuantity invokes a builtin with at least one quantity argument. Functions
+// that preserve the unit of their argument, such as abs and round, apply to the
+// magnitude; min, max, hypot and the statistics first express every argument in
+// the unit of the first, and variances are in its square; sqrt and cbrt take
+// the root of the unit. Other functions need plain numbers.
+func (e *Evaluator) callQuantity(name string, fn builtin, args []Value) (Value, error) {
+	switch name {
+	case "abs", "floor", "ceil", "round", "trunc", "min", "max", "hypot",
+		"sum", "mean", "median", "mode", "stddev", "pstddev", "variance", "pvariance", "percentile":
+		values, params := args, []float64(nil)
+		if name == "percentile" {
+			p := args[len(args)-1]
+			if hasQuantity(p) {
+				return nil, fmt.Errorf("%s expects a plain number, got %s", name, p)
+			}
+			f, err := ToFloat(p)
+			if err != nil {
+				return nil, err
+			}
+			values, params = args[:len(args)-1], []float64{f}
+		}
+		first, err := toQuantity(values[0])
+		if err != nil {
+			return nil, err
+		}
+		floatArgs := make([]float64, 0, len(args))
+		for _, arg := range values {
+			q, err := toQuantity(arg)
+			if err != nil {
+				return nil, err
+			}
+			if !q.Unit.Compatible(first.Unit) {
+				return nil, fmt.Errorf("%s: incompatible dimensions %s and %s", name, first.Unit.Dim, q.Unit.Dim)
+			}
+			floatArgs = append(floatArgs, q.in(first.Unit))
+		}
+		r, err := fn.fn(e.calc, append(floatArgs, params...))
+		if err != nil {
+			return nil, err
+		}
+		if name == "variance" || name == "pvariance" {
+			return e.checkQuantity(name, r, first.Unit.Pow(2), floatArgs...)
+		}
+		return e.checkQuantity(name, r, first.Unit, floatArgs...)
+	case "count":
+		return Float(len(args)), nil
+	case "sqrt", "cbrt":
+		q := args[0].(Quantity)
+		n := map[string]int{"sqrt": 2, "cbrt": 3}[name]
+		root, ok := q.Unit.Root(n)
+		if !ok {
+			return nil, fmt.Errorf("%s: unit %s has no root of degree %d", name, q.Unit, n)
+		}
+		r, err := fn.fn(e.calc, []float64{q.Value})
+		if err != nil {
+			return nil, err
+		}
+		return e.checkQuantity(name, r, root, q.Value)
+	default:
+		for _, arg := range args {
+			if q, ok := arg.(Quantity); ok {
+				return nil, fmt.Errorf("%s expects a plain number, got %s", name, q)
+			}
+		}
+		return e.callBuiltin(name, fn, args)
+	}
+}
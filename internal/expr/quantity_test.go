@@ -0,0 +1,103 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestQuantities(t *testing.T) {
+	env := NewEnv()
+	e := NewEvaluator(calculator.New(), env)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"5 km", "5 km"},
+		{"5 km + 300 m", "5.3 km"},
+		{"5 km + 300 m to m", "5300 m"},
+		{"60 W * 3 h in kWh", "0.18 kWh"},
+		{"9.81 m/s^2 * 2 kg to N", "19.62 N"},
+		{"100 km / 2 h to km/h", "50 km/h"},
+		{"1 km / 1 m", "1000"},
+		{"3 m * 4", "12 m"},
+		{"(2 m)^3 to L", "8000 L"},
+		{"-(3 ft) to inch", "-36 inch"},
+		{"20 degC to degF", "68 degF"},
+		{"0 K to °C", "-273.15 °C"},
+		{"32 degF to degC", "0 degC"},
+		{"32.0001 degF to degC", "5.55556e-05 degC"},
+		{"-40 degC to degF", "-40 degF"},
+		{"10 degC + 5 K", "15 degC"},
+		{"300 K - 1 degC", "299 K"},
+		{"50 degF - 10 degC", "32 degF"},
+		{"1 mi to km", "1.609344 km"},
+		{"10 m % 3 m", "1 m"},
+		{"10 m // 300 cm", "3"},
+		{"sqrt(16 m^2)", "4 m"},
+		{"abs(-2 kg)", "2 kg"},
+		{"max(1 m, 2 ft) to cm", "100 cm"},
+		{"hypot(3 m, 400 cm)", "5 m"},
+		{"d = 42 km", "42 km"},
+		{"d / 2 h to mph", "13.048795036984 mph"},
+		{"kg * m / s^2 to N", "1 N"},
+		{"2 N m to J", "2 J"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{
+		"5 km + 3 s",
+		"5 km to kg",
+		"2 m ^ 1.5",
+		"2 ^ (3 m)",
+		"sqrt(2 m)",
+		"sin(2 m)",
+		"max(1 m, 1 s)",
+		"1 m & 1",
+		"3 to m",
+	} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+
+	// m beside a time is metres unless a duration literal makes it minutes
+	for _, src := range []string{"1 m + 1 s", "5 m - 2 min", "5m - 2 min", "1h + 15m"} {
+		if _, err := e.Evaluate(src); err == nil || !strings.Contains(err.Error(), "incompatible dimensions") {
+			t.Errorf("Evaluate(%q): expected incompatible dimensions, got %v", src, err)
+		}
+	}
+}
+
+func TestQuantitiesInOtherModes(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.RationalMode)
+	e := NewEvaluator(calc, nil)
+	if v, err := e.Evaluate("3 km / 4 to m"); err != nil || v.String() != "750 m" {
+		t.Errorf("Expected 750 m in rational mode, got %v (err: %v)", v, err)
+	}
+}
+
+func TestRenderQuantity(t *testing.T) {
+	calc := calculator.New()
+	e := NewEvaluator(calc, nil)
+	v, err := e.Evaluate("1234.5678 km")
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	f := Format{Notation: NotationFixed, Digits: 1, Group: true}
+	if s, err := f.Render(calc, v); err != nil || s != "1,234.6 km" {
+		t.Errorf("Expected 1,234.6 km, got %s (err: %v)", s, err)
+	}
+}
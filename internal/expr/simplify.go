@@ -0,0 +1,453 @@
+package expr
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// The symbolic engine rewrites expression trees into a canonical form: a sum
+// of terms, each a numeric coefficient times a product of factors, each a base
+// raised to an exponent. Like terms and like factors are collected, numbers are
+// folded and the identities below are applied, so x + x is 2 * x, x * x^2 is
+// x^3 and ln(exp(x)) is x. Trees are built with the constructors sumOf,
+// productOf, power and callOf, which keep them in that form.
+
+// term is a summand, a coefficient times a product of factors
+type term struct {
+	coef    float64
+	factors []factor
+}
+
+// factor is a base raised to an exponent
+type factor struct {
+	base, exp Node
+}
+
+// num returns a number node
+func num(x float64) Node {
+	return &Number{Value: x, Text: Float(x).String()}
+}
+
+// numberValue returns the value of a real number node
+func numberValue(n Node) (float64, bool) {
+	if number, ok := n.(*Number); ok && !number.Imag {
+		return number.Value, true
+	}
+	return 0, false
+}
+
+// isNumber reports whether n is the real number x
+func isNumber(n Node, x float64) bool {
+	v, ok := numberValue(n)
+	return ok && v == x
+}
+
+// isInteger reports whether x is a whole number
+func isInteger(x float64) bool {
+	return x == math.Trunc(x) && !math.IsInf(x, 0)
+}
+
+// fold rounds the result of arithmetic on coefficients to 15 significant
+// digits, so that 0.1 + 0.2 collects to 0.3
+func fold(x float64) float64 {
+	return roundSignificant(x, 15)
+}
+
+// termsOf splits a canonical tree into its terms
+func termsOf(n Node) []term {
+	switch n := n.(type) {
+	case *Binary:
+		switch n.Op {
+		case "+":
+			return append(termsOf(n.X), termsOf(n.Y)...)
+		case "-":
+			return append(termsOf(n.X), negateTerms(termsOf(n.Y))...)
+		}
+	case *Unary:
+		if n.Op == "-" {
+			return negateTerms(termsOf(n.X))
+		}
+	}
+	coef, factors := factorsOf(n)
+	return []term{{coef, factors}}
+}
+
+// negateTerms changes the sign of each term
+func negateTerms(terms []term) []term {
+	for i := range terms {
+		terms[i].coef = -terms[i].coef
+	}
+	return terms
+}
+
+// factorsOf splits a canonical product into its coefficient and factors.
+// Sums and other nodes are single factors with exponent 1.
+func factorsOf(n Node) (float64, []factor) {
+	if v, ok := numberValue(n); ok {
+		return v, nil
+	}
+	switch n := n.(type) {
+	case *Unary:
+		if n.Op == "-" {
+			coef, factors := factorsOf(n.X)
+			return -coef, factors
+		}
+	case *Binary:
+		switch n.Op {
+		case "*":
+			c1, f1 := factorsOf(n.X)
+			c2, f2 := factorsOf(n.Y)
+			return fold(c1 * c2), append(f1, f2...)
+		case "/":
+			c1, f1 := factorsOf(n.X)
+			c2, f2 := factorsOf(n.Y)
+			if c2 == 0 {
+				break
+			}
+			for _, f := range f2 {
+				f1 = append(f1, factor{f.base, productOf(num(-1), f.exp)})
+			}
+			return fold(c1 / c2), f1
+		case "^":
+			return 1, []factor{{n.X, n.Y}}
+		}
+	}
+	return 1, []factor{{n, num(1)}}
+}
+
+// sumOf returns the canonical sum of nodes, collecting like terms in the order
+// in which they first appear
+func sumOf(nodes ...Node) Node {
+	var collected []term
+	index := map[string]int{}
+	for _, n := range nodes {
+		for _, t := range termsOf(n) {
+			// Collect the factors first, so that x * x and x^2 are like terms
+			coef, factors := collectFactors(t.coef, t.factors)
+			key := termKey(factors)
+			if i, ok := index[key]; ok {
+				collected[i].coef = fold(collected[i].coef + coef)
+				continue
+			}
+			index[key] = len(collected)
+			collected = append(collected, term{coef, factors})
+		}
+	}
+
+	var sum Node
+	for _, t := range collected {
+		switch {
+		case t.coef == 0:
+		case sum == nil:
+			sum = buildProduct(t.coef, t.factors)
+		case t.coef < 0:
+			sum = &Binary{Op: "-", X: sum, Y: buildProduct(-t.coef, t.factors)}
+		default:
+			sum = &Binary{Op: "+", X: sum, Y: buildProduct(t.coef, t.factors)}
+		}
+	}
+	if sum == nil {
+		return num(0)
+	}
+	return sum
+}
+
+// termKey identifies the factors of a term regardless of their order
+func termKey(factors []factor) string {
+	keys := make([]string, len(factors))
+	for i, f := range factors {
+		keys[i] = f.base.String() + "^" + f.exp.String()
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "*")
+}
+
+// productOf returns the canonical product of nodes, collecting like factors
+func productOf(nodes ...Node) Node {
+	coef := 1.0
+	var factors []factor
+	for _, n := range nodes {
+		c, f := factorsOf(n)
+		coef = fold(coef * c)
+		factors = append(factors, f...)
+	}
+	return buildProduct(collectFactors(coef, factors))
+}
+
+// collectFactors combines factors with the same base by adding their exponents,
+// keeping the order in which the bases first appear. Factors that simplify to
+// numbers move into the coefficient.
+func collectFactors(coef float64, factors []factor) (float64, []factor) {
+	var collected []factor
+	index := map[string]int{}
+	for _, f := range factors {
+		key := f.base.String()
+		if i, ok := index[key]; ok {
+			collected[i].exp = sumOf(collected[i].exp, f.exp)
+			continue
+		}
+		index[key] = len(collected)
+		collected = append(collected, f)
+	}
+
+	var result []factor
+	for _, f := range collected {
+		p := power(f.base, f.exp)
+		if v, ok := numberValue(p); ok {
+			coef = fold(coef * v)
+			continue
+		}
+		// A power that simplified to a product, such as (2 * x)^2, contributes
+		// its own coefficient and factors
+		c, fs := factorsOf(p)
+		coef = fold(coef * c)
+		result = append(result, fs...)
+	}
+	if coef == 0 {
+		return 0, nil
+	}
+	return coef, result
+}
+
+// buildProduct assembles a coefficient and collected factors into a tree,
+// writing factors with negative exponents and coefficients such as 0.5 as a
+// denominator, so that 0.5 * x^-1 is 1 / (2 * x)
+func buildProduct(coef float64, factors []factor) Node {
+	if len(factors) == 0 {
+		return num(coef)
+	}
+	if coef < 0 {
+		return &Unary{Op: "-", X: buildProduct(-coef, factors)}
+	}
+	var numerator, denominator []Node
+	switch {
+	case coef == 1:
+	case coef < 1 && isInteger(1/coef):
+		denominator = append(denominator, num(1/coef))
+	default:
+		numerator = append(numerator, num(coef))
+	}
+	for _, f := range factors {
+		if v, ok := numberValue(f.exp); ok && v < 0 {
+			denominator = append(denominator, power(f.base, num(-v)))
+		} else {
+			numerator = append(numerator, power(f.base, f.exp))
+		}
+	}
+	if len(numerator) == 0 {
+		numerator = append(numerator, num(1))
+	}
+	product := chain("*", numerator)
+	if len(denominator) > 0 {
+		product = &Binary{Op: "/", X: product, Y: chain("*", denominator)}
+	}
+	return product
+}
+
+// chain joins nodes with a left-associative operator
+func chain(op string, nodes []Node) Node {
+	n := nodes[0]
+	for _, next := range nodes[1:] {
+		n = &Binary{Op: op, X: n, Y: next}
+	}
+	return n
+}
+
+// power returns the canonical form of base^exp. Numbers with whole exponents
+// are folded, x^0 is 1, x^1 is x, (x^a)^n is x^(a*n) and (x*y)^n is x^n * y^n
+// for whole n, and sqrt(x)^2 is x.
+func power(base, exp Node) Node {
+	e, numericExp := numberValue(exp)
+	if b, ok := numberValue(base); ok && numericExp && isInteger(e) {
+		if p := math.Pow(b, e); !math.IsInf(p, 0) && !math.IsNaN(p) {
+			return num(fold(p))
+		}
+	}
+	switch {
+	case numericExp && e == 0, isNumber(base, 1):
+		return num(1)
+	case numericExp && e == 1:
+		return base
+	case isNumber(base, 0) && numericExp && e > 0:
+		return num(0)
+	}
+	if _, numericBase := numberValue(base); numericBase || !numericExp || !isInteger(e) {
+		return &Binary{Op: "^", X: base, Y: exp}
+	}
+
+	switch b := base.(type) {
+	case *Binary:
+		if b.Op == "^" {
+			return power(b.X, productOf(b.Y, exp))
+		}
+	case *Call:
+		if strings.ToLower(b.Name) == "sqrt" && len(b.Args) == 1 && int(e)%2 == 0 {
+			return power(b.Args[0], num(e/2))
+		}
+	}
+	if coef, factors := factorsOf(base); coef != 1 || len(factors) != 1 || !isNumber(factors[0].exp, 1) {
+		for i, f := range factors {
+			factors[i].exp = productOf(f.exp, exp)
+		}
+		return buildProduct(collectFactors(fold(math.Pow(coef, e)), factors))
+	}
+	return &Binary{Op: "^", X: base, Y: exp}
+}
+
+// exactCalls gives the values of functions at the arguments where they are
+// whole numbers in every angle mode, such as sin(0) and ln(1)
+var exactCalls = map[string]map[float64]float64{
+	"sin":   {0: 0},
+	"cos":   {0: 1},
+	"tan":   {0: 0},
+	"asin":  {0: 0},
+	"atan":  {0: 0},
+	"sinh":  {0: 0},
+	"cosh":  {0: 1},
+	"tanh":  {0: 0},
+	"exp":   {0: 1},
+	"ln":    {1: 0},
+	"log":   {1: 0},
+	"log10": {1: 0, 10: 1},
+}
+
+// callOf returns the canonical form of a function call. Calls with exact
+// results are folded, as are the square roots of perfect squares and absolute
+// values of numbers, and ln(exp(x)) and exp(ln(x)) are x.
+func callOf(name string, args []Node) Node {
+	lower := strings.ToLower(name)
+	if len(args) == 1 {
+		if v, ok := numberValue(args[0]); ok {
+			if result, ok := exactCalls[lower][v]; ok {
+				return num(result)
+			}
+			switch lower {
+			case "abs":
+				return num(math.Abs(v))
+			case "sqrt":
+				if root := math.Sqrt(v); isInteger(root) {
+					return num(root)
+				}
+			}
+		}
+		if inner, ok := args[0].(*Call); ok && len(inner.Args) == 1 {
+			innerName := strings.ToLower(inner.Name)
+			if (lower == "ln" || lower == "log") && innerName == "exp" || lower == "exp" && (innerName == "ln" || innerName == "log") {
+				return inner.Args[0]
+			}
+		}
+	}
+	return &Call{Name: name, Args: args}
+}
+
+// simplify rewrites a tree into canonical form, bottom up
+func simplify(n Node) Node {
+	switch n := n.(type) {
+	case *Unary:
+		x := simplify(n.X)
+		switch n.Op {
+		case "-":
+			return productOf(num(-1), x)
+		case "+":
+			return x
+		}
+		return &Unary{Op: n.Op, X: x}
+	case *Binary:
+		x, y := simplify(n.X), simplify(n.Y)
+		switch n.Op {
+		case "+":
+			return sumOf(x, y)
+		case "-":
+			return sumOf(x, productOf(num(-1), y))
+		case "*":
+			return productOf(x, y)
+		case "/":
+			if isNumber(y, 0) {
+				break
+			}
+			return productOf(x, power(y, num(-1)))
+		case "^":
+			return power(x, y)
+		}
+		return &Binary{Op: n.Op, X: x, Y: y}
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = simplify(arg)
+		}
+		return callOf(n.Name, args)
+	case *Factorial:
+		return &Factorial{X: simplify(n.X)}
+	}
+	return n
+}
+
+// Precedence levels for pretty printing, following the parser: unary minus
+// binds tighter than multiplication but looser than powers, so -x^2 is -(x^2)
+const (
+	precSum = iota + 1
+	precProduct
+	precUnary
+	precPower
+	precAtom
+)
+
+// pretty renders a tree in conventional notation with only the parentheses
+// it needs, such as 3 * x^2 * sin(x) + x^3 * cos(x)
+func pretty(n Node) string {
+	s, _ := prettyPrec(n)
+	return s
+}
+
+// prettyPrec renders a tree and returns the precedence of its outermost operator
+func prettyPrec(n Node) (string, int) {
+	switch n := n.(type) {
+	case *Number:
+		s := n.String()
+		if n.Value < 0 {
+			return s, precUnary
+		}
+		return s, precAtom
+	case *Unary:
+		if n.Op == "-" {
+			// -(a * b) is (-a) * b, so a product needs no parentheses
+			s, p := prettyPrec(n.X)
+			if p == precProduct {
+				return "-" + s, precProduct
+			}
+		}
+		return n.Op + operand(n.X, precUnary, false), precUnary
+	case *Binary:
+		switch n.Op {
+		case "+", "-":
+			return operand(n.X, precSum, false) + " " + n.Op + " " + operand(n.Y, precSum, n.Op == "-"), precSum
+		case "*", "/":
+			return operand(n.X, precProduct, false) + " " + n.Op + " " + operand(n.Y, precProduct, n.Op == "/"), precProduct
+		case "^":
+			// Powers group to the right, so only a power on the left needs parentheses
+			return operand(n.X, precPower, true) + "^" + operand(n.Y, precPower, false), precPower
+		}
+	case *Call:
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = pretty(arg)
+		}
+		return n.Name + "(" + strings.Join(args, ", ") + ")", precAtom
+	case *Ident:
+		return n.Name, precAtom
+	case *Factorial:
+		return operand(n.X, precAtom, false) + "!", precAtom
+	}
+	return n.String(), precAtom
+}
+
+// operand renders an operand of an operator with precedence prec, in
+// parentheses when it binds more loosely, or equally loosely when strict
+func operand(n Node, prec int, strict bool) string {
+	s, p := prettyPrec(n)
+	if p < prec || strict && p == prec {
+		return "(" + s + ")"
+	}
+	return s
+}
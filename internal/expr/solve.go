@@ -0,0 +1,222 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+	"github.com/jondkelley/cicd_golang_calculator/internal/units"
+)
+
+const (
+	// solveFunc finds a root of an expression near a guess, solve(expr, x, guess)
+	solveFunc = "solve"
+	// rootsFunc finds the roots of an expression in an interval, roots(expr, x, a, b)
+	rootsFunc = "roots"
+)
+
+// rootScanIntervals is the number of subintervals roots searches for sign changes
+const rootScanIntervals = 200
+
+// evalSolve finds a value of the variable named by the second argument at
+// which the first argument is zero, starting from the guess. A guess with a
+// unit, such as 500 req/s, binds the variable in that unit.
+func (e *Evaluator) evalSolve(n *Call) (Value, error) {
+	if len(n.Args) != 3 {
+		return nil, fmt.Errorf("%s expects 3 argument(s), got %d", n.Name, len(n.Args))
+	}
+	name, err := variableArg(n, 1)
+	if err != nil {
+		return nil, err
+	}
+	guess, err := e.Eval(n.Args[2])
+	if err != nil {
+		return nil, err
+	}
+	x0, unit, err := magnitude(guess)
+	if err != nil {
+		return nil, err
+	}
+
+	f := e.newExprFunc(n.Args[0], name, unit).eval
+	r, err := e.calc.FindRoot(f, x0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.Name, err)
+	}
+	r = polishRoot(f, r)
+	e.note("%s: %s's method converged in %d iterations, |f(%s)| = %g", n.Name, r.Method, r.Iterations, name, math.Abs(r.Residual))
+	return withUnit(r.Root, unit), nil
+}
+
+// evalRoots finds every value of the variable between two bounds at which the
+// expression changes sign, returning them as a list in increasing order
+func (e *Evaluator) evalRoots(n *Call) (Value, error) {
+	if len(n.Args) != 4 {
+		return nil, fmt.Errorf("%s expects 4 argument(s), got %d", n.Name, len(n.Args))
+	}
+	name, err := variableArg(n, 1)
+	if err != nil {
+		return nil, err
+	}
+	a, b, unit, err := e.evalInterval(n, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	f := e.newExprFunc(n.Args[0], name, unit).eval
+	found, err := e.calc.FindRoots(f, a, b, rootScanIntervals)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.Name, err)
+	}
+	roots := make(List, len(found))
+	worst := 0.0
+	for i, r := range found {
+		r = polishRoot(f, r)
+		roots[i] = withUnit(r.Root, unit)
+		worst = math.Max(worst, math.Abs(r.Residual))
+	}
+	e.note("%s: %d root(s) from sign changes over %d subintervals, refined by Brent's method, largest |f(%s)| = %g", n.Name, len(roots), rootScanIntervals, name, worst)
+	return roots, nil
+}
+
+// variableArg returns the variable name passed as argument i of a call
+func variableArg(n *Call, i int) (string, error) {
+	ident, ok := n.Args[i].(*Ident)
+	if !ok || isReserved(ident.Name) {
+		return "", fmt.Errorf("%s: argument %d must be a variable name, got %s", n.Name, i+1, n.Args[i])
+	}
+	return ident.Name, nil
+}
+
+// evalInterval evaluates the bounds passed as arguments i and i+1 of a call,
+// returning them as magnitudes in the unit of the lower bound
+func (e *Evaluator) evalInterval(n *Call, i int) (float64, float64, units.Unit, error) {
+	bounds, err := e.evalArgs(n.Args[i : i+2])
+	if err != nil {
+		return 0, 0, units.Unit{}, err
+	}
+	a, unit, err := magnitude(bounds[0])
+	if err != nil {
+		return 0, 0, units.Unit{}, err
+	}
+	upper, err := toQuantity(bounds[1])
+	if err != nil {
+		return 0, 0, units.Unit{}, err
+	}
+	if !upper.Unit.Compatible(unit) {
+		return 0, 0, units.Unit{}, fmt.Errorf("%s: bounds %s and %s have incompatible dimensions", n.Name, bounds[0], bounds[1])
+	}
+	return a, upper.in(unit), unit, nil
+}
+
+// magnitude splits a plain number or quantity into its magnitude and unit
+func magnitude(v Value) (float64, units.Unit, error) {
+	q, err := toQuantity(v)
+	if err != nil {
+		return 0, units.Unit{}, err
+	}
+	return q.Value, q.Unit, nil
+}
+
+// withUnit returns x as a plain number, or as a quantity when unit has a dimension
+func withUnit(x float64, unit units.Unit) Value {
+	if unit.Dim.IsZero() && unit.Factor == 1 {
+		return Float(x)
+	}
+	return quantity(x, unit)
+}
+
+// exprFunc is an expression viewed as a real function of one of its variables
+// for the numerical methods. The variable is bound in unit, and results are
+// expressed as magnitudes in the unit of the first result, which is kept in
+// result. Plain numeric expressions are compiled to a program, which the
+// methods can evaluate many times over without allocating.
+type exprFunc struct {
+	e       *Evaluator
+	body    Node
+	name    string
+	unit    units.Unit
+	result  units.Unit
+	seen    bool
+	program *Program
+}
+
+// newExprFunc returns body as a function of the variable name bound in unit
+func (e *Evaluator) newExprFunc(body Node, name string, unit units.Unit) *exprFunc {
+	f := &exprFunc{e: e, body: body, name: name, unit: unit}
+	if _, plain := withUnit(1, unit).(Float); plain {
+		// Anything the program cannot compute is left to the evaluator
+		f.program, _ = e.Compile(body, name)
+	}
+	return f
+}
+
+// eval evaluates the expression at x, in the calculator.Func signature
+func (f *exprFunc) eval(x float64) (float64, error) {
+	if f.program != nil {
+		fx, err := f.program.Run(x)
+		if err == nil && math.IsNaN(fx) {
+			return 0, fmt.Errorf("%s is undefined at %s = %g", f.body, f.name, x)
+		}
+		return fx, err
+	}
+	v, err := f.e.evalWith(f.body, f.name, withUnit(x, f.unit))
+	if err != nil {
+		return 0, err
+	}
+	q, err := toQuantity(v)
+	if err != nil {
+		return 0, err
+	}
+	if !f.seen {
+		f.result, f.seen = q.Unit, true
+	}
+	if !q.Unit.Compatible(f.result) {
+		return 0, fmt.Errorf("%s changes dimension between %s and %s", f.body, f.result, q.Unit)
+	}
+	fx := f.result.FromSI(q.Unit.ToSI(q.Value))
+	if math.IsNaN(fx) {
+		return 0, fmt.Errorf("%s is undefined at %s = %g", f.body, f.name, x)
+	}
+	return fx, nil
+}
+
+// evalWith evaluates node with name bound to value, on top of the bindings of
+// the innermost user function call so that its parameters stay visible
+func (e *Evaluator) evalWith(node Node, name string, value Value) (Value, error) {
+	frame := map[string]Value{name: value}
+	if len(e.frames) > 0 {
+		for k, v := range e.frames[len(e.frames)-1] {
+			if k != name {
+				frame[k] = v
+			}
+		}
+	}
+	e.frames = append(e.frames, frame)
+	e.sampling++
+	defer func() {
+		e.frames = e.frames[:len(e.frames)-1]
+		e.sampling--
+	}()
+	return e.Eval(node)
+}
+
+// polishRoot replaces a root by a shorter number that fits f as well, rounding
+// it to 15 significant digits or to zero when it is within the tolerance of the
+// root finders, so that the root of x^3 - 8 shows as 2 rather than
+// 2.0000000000000004 and the root of sin(x) near 0 as 0
+func polishRoot(f calculator.Func, r calculator.RootResult) calculator.RootResult {
+	candidates := []float64{roundSignificant(r.Root, 15)}
+	if math.Abs(r.Root) < 1e-12 {
+		candidates = []float64{0}
+	}
+	for _, x := range candidates {
+		if x == r.Root {
+			continue
+		}
+		if fx, err := f(x); err == nil && (fx == 0 || math.Abs(fx) < math.Abs(r.Residual)) {
+			r.Root, r.Residual = x, fx
+		}
+	}
+	return r
+}
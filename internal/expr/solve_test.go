@@ -0,0 +1,78 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestSolve(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"solve(x^3 - 8, x, 1)", "2"},
+		{"solve(x^2 - 2, x, 0)", "1.414213562373095"},
+		{"solve(cos(x) - x, x, 1)", "0.7390851332151607"},
+		{"solve(ln(t) - 1, t, 5)", "2.718281828459045"},
+		{"solve(x - 2 m, x, 1 m)", "2 m"},
+		{"lat(load) = 20 + 0.5 * load", ""},
+		{"solve(lat(n) - 200, n, 10)", "360"},
+		{"f(a) = solve(x^2 - a, x, 1)", ""},
+		{"f(9)", "3"},
+		{"roots(x^3 - 6*x^2 + 11*x - 6, x, 0, 5)", "[1, 2, 3]"},
+		{"roots(sin(x), x, -1, 7)", "[0, 3.141592653589793, 6.283185307179586]"},
+		{"roots(x - 0.5 s, x, 0 s, 2 s)", "[0.5 s]"},
+		{"roots(x - 500 ms, x, 0 s, 2000 ms)", "[0.5 s]"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if test.expected != "" && v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	errors := []struct {
+		src     string
+		message string
+	}{
+		{"solve(x^2 + 1, x, 0)", "no root found near 0"},
+		{"roots(x^2 + 1, x, -5, 5)", "no root found in [-5, 5]"},
+		{"roots(x, x, 5, -5)", "invalid interval"},
+		{"solve(x - 1, 2, 3)", "argument 2 must be a variable name"},
+		{"solve(x - 1, ans, 3)", "argument 2 must be a variable name"},
+		{"solve(x - 1, x)", "expects 3 argument(s)"},
+		{"roots(x, x, 0 m, 1 s)", "incompatible dimensions"},
+		{"solve(y - 1, x, 0)", "undefined identifier"},
+	}
+	for _, test := range errors {
+		_, err := e.Evaluate(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Evaluate(%q): expected error containing %q, got %v", test.src, test.message, err)
+		}
+	}
+}
+
+func TestSolveNotes(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+	if _, err := e.Evaluate("solve(x^3 - 8, x, 1)"); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	notes := e.Notes()
+	if len(notes) != 1 || !strings.HasPrefix(notes[0], "solve: Newton's method converged in ") || !strings.HasSuffix(notes[0], "|f(x)| = 0") {
+		t.Errorf("Expected a note on Newton's convergence, got %q", notes)
+	}
+	if _, err := e.Evaluate("roots(x^2 - 1, x, -2, 2)"); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if notes := e.Notes(); len(notes) != 2 || !strings.HasPrefix(notes[1], "roots: 2 root(s)") {
+		t.Errorf("Expected a note on the roots found, got %q", notes)
+	}
+}
@@ -0,0 +1,373 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+const (
+	// diffFunc differentiates an expression symbolically, diff(expr, x) or
+	// diff(expr, x, n) for the n-th derivative
+	diffFunc = "diff"
+	// simplifyFunc simplifies an expression symbolically, simplify(expr)
+	simplifyFunc = "simplify"
+)
+
+// maxDiffOrder bounds the order of a symbolic derivative
+const maxDiffOrder = 10
+
+// maxSymbolicNodes bounds the size of a formula handled by diff and simplify,
+// as each derivative of x^x^x^x^x is several times larger than the last
+const maxSymbolicNodes = 10000
+
+// Symbolic is an expression kept as a formula rather than evaluated, produced
+// by diff and simplify
+type Symbolic struct {
+	Node Node
+}
+
+// String formats the formula in conventional notation
+func (s Symbolic) String() string {
+	return pretty(s.Node)
+}
+
+// evalDiff differentiates the first argument with respect to the variable named
+// by the second, as many times as the optional third argument says. Inside a
+// user function whose parameter is the variable, the derivative is evaluated at
+// the argument instead, so that df(x) = diff(x^3 * sin(x), x) defines the
+// derivative as a function.
+func (e *Evaluator) evalDiff(n *Call) (Value, error) {
+	if len(n.Args) != 2 && len(n.Args) != 3 {
+		return nil, fmt.Errorf("%s expects 2 to 3 arguments, got %d", n.Name, len(n.Args))
+	}
+	name, err := variableArg(n, 1)
+	if err != nil {
+		return nil, err
+	}
+	order := 1
+	if len(n.Args) == 3 {
+		v, err := e.Eval(n.Args[2])
+		if err != nil {
+			return nil, err
+		}
+		f, err := ToFloat(v)
+		if err != nil || !isInteger(f) || f < 1 || f > maxDiffOrder {
+			return nil, fmt.Errorf("%s: order must be a whole number from 1 to %d, got %s", n.Name, maxDiffOrder, v)
+		}
+		order = int(f)
+	}
+
+	node, err := e.expand(n.Args[0], name, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.Name, err)
+	}
+	if treeSize(node, maxSymbolicNodes) > maxSymbolicNodes {
+		return nil, fmt.Errorf("%s: expression has more than %d nodes", n.Name, maxSymbolicNodes)
+	}
+	node = simplify(node)
+	for i := 0; i < order; i++ {
+		if node, err = e.derivative(node, name); err != nil {
+			return nil, fmt.Errorf("%s: %v", n.Name, err)
+		}
+		if treeSize(node, maxSymbolicNodes) > maxSymbolicNodes {
+			return nil, fmt.Errorf("%s: derivative of order %d has more than %d nodes", n.Name, i+1, maxSymbolicNodes)
+		}
+	}
+	if len(e.frames) > 0 {
+		if _, bound := e.frames[len(e.frames)-1][name]; bound {
+			return e.evalDetached(node)
+		}
+	}
+	return Symbolic{node}, nil
+}
+
+// evalSimplify simplifies its argument without evaluating it
+func (e *Evaluator) evalSimplify(n *Call) (Value, error) {
+	if len(n.Args) != 1 {
+		return nil, fmt.Errorf("%s expects 1 argument(s), got %d", n.Name, len(n.Args))
+	}
+	node, err := e.expand(n.Args[0], "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.Name, err)
+	}
+	if treeSize(node, maxSymbolicNodes) > maxSymbolicNodes {
+		return nil, fmt.Errorf("%s: expression has more than %d nodes", n.Name, maxSymbolicNodes)
+	}
+	return Symbolic{simplify(node)}, nil
+}
+
+// expand prepares a tree for symbolic work. Calls of user functions are
+// replaced by their bodies with the arguments substituted, and variables bound
+// to numbers or formulas by their values, except for the variable keep and the
+// built-in constants, which stay symbols. Undefined names are symbols too.
+func (e *Evaluator) expand(n Node, keep string, depth int) (Node, error) {
+	if depth >= e.env.MaxDepth() {
+		return nil, fmt.Errorf("maximum call depth %d exceeded", e.env.MaxDepth())
+	}
+	switch n := n.(type) {
+	case *Ident:
+		if n.Name == keep || isConstant(n.Name) {
+			return n, nil
+		}
+		v, err := e.lookup(n.Name)
+		if err != nil {
+			return n, nil
+		}
+		switch v := v.(type) {
+		case Symbolic:
+			return v.Node, nil
+		case Float, Rat, Int, BigFloat:
+			f, _ := ToFloat(v)
+			return num(f), nil
+		}
+		return n, nil
+	case *Unary:
+		x, err := e.expand(n.X, keep, depth)
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: n.Op, X: x, Offset: n.Offset}, nil
+	case *Factorial:
+		x, err := e.expand(n.X, keep, depth)
+		if err != nil {
+			return nil, err
+		}
+		return &Factorial{X: x, Offset: n.Offset}, nil
+	case *Binary:
+		x, err := e.expand(n.X, keep, depth)
+		if err != nil {
+			return nil, err
+		}
+		y, err := e.expand(n.Y, keep, depth)
+		if err != nil {
+			return nil, err
+		}
+		return &Binary{Op: n.Op, X: x, Y: y, Offset: n.Offset}, nil
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			a, err := e.expand(arg, keep, depth)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = a
+		}
+		fn, ok := e.env.Func(n.Name)
+		if !ok {
+			return &Call{Name: n.Name, Args: args, Offset: n.Offset}, nil
+		}
+		if len(args) != len(fn.Params) {
+			return nil, fmt.Errorf("%s expects %d argument(s), got %d", fn.Signature(), len(fn.Params), len(args))
+		}
+		bindings := make(map[string]Node, len(args))
+		for i, param := range fn.Params {
+			bindings[param] = args[i]
+		}
+		body := substitute(fn.Body, bindings)
+		if treeSize(body, maxSymbolicNodes) > maxSymbolicNodes {
+			return nil, fmt.Errorf("expansion of %s has more than %d nodes", fn.Signature(), maxSymbolicNodes)
+		}
+		return e.expand(body, keep, depth+1)
+	}
+	return n, nil
+}
+
+// substitute replaces the identifiers in bindings by their nodes
+func substitute(n Node, bindings map[string]Node) Node {
+	switch n := n.(type) {
+	case *Ident:
+		if b, ok := bindings[n.Name]; ok {
+			return b
+		}
+	case *Unary:
+		return &Unary{Op: n.Op, X: substitute(n.X, bindings), Offset: n.Offset}
+	case *Factorial:
+		return &Factorial{X: substitute(n.X, bindings), Offset: n.Offset}
+	case *Binary:
+		return &Binary{Op: n.Op, X: substitute(n.X, bindings), Y: substitute(n.Y, bindings), Offset: n.Offset}
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = substitute(arg, bindings)
+		}
+		return &Call{Name: n.Name, Args: args, Offset: n.Offset}
+	}
+	return n
+}
+
+// treeSize counts the nodes of a tree, stopping once the count exceeds limit, as
+// a tree whose subtrees are shared can be far larger than the memory it takes
+func treeSize(n Node, limit int) int {
+	size := 1
+	var children []Node
+	switch n := n.(type) {
+	case *Unary:
+		children = []Node{n.X}
+	case *Factorial:
+		children = []Node{n.X}
+	case *Binary:
+		children = []Node{n.X, n.Y}
+	case *Call:
+		children = n.Args
+	}
+	for _, child := range children {
+		if size > limit {
+			break
+		}
+		size += treeSize(child, limit-size)
+	}
+	return size
+}
+
+// dependsOn reports whether the tree refers to the variable x
+func dependsOn(n Node, x string) bool {
+	switch n := n.(type) {
+	case *Ident:
+		return n.Name == x
+	case *Unary:
+		return dependsOn(n.X, x)
+	case *Factorial:
+		return dependsOn(n.X, x)
+	case *Binary:
+		return dependsOn(n.X, x) || dependsOn(n.Y, x)
+	case *Call:
+		for _, arg := range n.Args {
+			if dependsOn(arg, x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// derivative returns the simplified derivative of a tree with respect to x by
+// the sum, product, quotient, power and chain rules
+func (e *Evaluator) derivative(n Node, x string) (Node, error) {
+	if !dependsOn(n, x) {
+		return num(0), nil
+	}
+	switch n := n.(type) {
+	case *Ident:
+		return num(1), nil
+	case *Unary:
+		dx, err := e.derivative(n.X, x)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case "-":
+			return productOf(num(-1), dx), nil
+		case "+":
+			return dx, nil
+		}
+	case *Binary:
+		return e.binaryDerivative(n, x)
+	case *Call:
+		return e.callDerivative(n, x)
+	}
+	return nil, fmt.Errorf("cannot differentiate %s", pretty(n))
+}
+
+// binaryDerivative differentiates an arithmetic operation
+func (e *Evaluator) binaryDerivative(n *Binary, x string) (Node, error) {
+	du, err := e.derivative(n.X, x)
+	if err != nil {
+		return nil, err
+	}
+	dv, err := e.derivative(n.Y, x)
+	if err != nil {
+		return nil, err
+	}
+	u, v := n.X, n.Y
+	switch n.Op {
+	case "+":
+		return sumOf(du, dv), nil
+	case "-":
+		return sumOf(du, productOf(num(-1), dv)), nil
+	case "*":
+		return sumOf(productOf(du, v), productOf(u, dv)), nil
+	case "/":
+		// (u/v)' = u'/v - u v'/v^2
+		return sumOf(productOf(du, power(v, num(-1))), productOf(num(-1), u, dv, power(v, num(-2)))), nil
+	case "^":
+		switch {
+		case !dependsOn(v, x):
+			// (u^c)' = c u^(c-1) u'
+			return productOf(v, power(u, sumOf(v, num(-1))), du), nil
+		case !dependsOn(u, x):
+			// (c^v)' = c^v ln(c) v'
+			return productOf(n, callOf("ln", []Node{u}), dv), nil
+		default:
+			// (u^v)' = u^v (v' ln(u) + v u'/u)
+			return productOf(n, sumOf(productOf(dv, callOf("ln", []Node{u})), productOf(v, du, power(u, num(-1))))), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot differentiate %s", pretty(n))
+}
+
+// trigFuncs are the functions whose arguments are angles in the calculator's
+// angle mode
+var trigFuncs = map[string]bool{"sin": true, "cos": true, "tan": true}
+
+// callDerivative differentiates a call of a built-in function by the chain rule
+func (e *Evaluator) callDerivative(n *Call, x string) (Node, error) {
+	name := strings.ToLower(n.Name)
+	if trigFuncs[name] && e.calc.AngleMode() != calculator.Radians {
+		return nil, fmt.Errorf("derivatives of %s need radians, but the angle mode is %s", name, e.calc.AngleMode())
+	}
+	if name == "log" && len(n.Args) == 2 {
+		// log(u, b) = ln(u) / ln(b)
+		return e.derivative(productOf(callOf("ln", n.Args[:1]), power(callOf("ln", n.Args[1:]), num(-1))), x)
+	}
+	if len(n.Args) != 1 {
+		return nil, fmt.Errorf("cannot differentiate %s", pretty(n))
+	}
+
+	u := n.Args[0]
+	call := func(name string) Node { return callOf(name, []Node{u}) }
+	// sqrt(1 - u^2) appears in the derivatives of asin and acos
+	root := callOf("sqrt", []Node{sumOf(num(1), productOf(num(-1), power(u, num(2))))})
+	// outer is the derivative of the function at u
+	var outer Node
+	switch name {
+	case "sin":
+		outer = call("cos")
+	case "cos":
+		outer = productOf(num(-1), call("sin"))
+	case "tan":
+		outer = power(call("cos"), num(-2))
+	case "asin":
+		outer = power(root, num(-1))
+	case "acos":
+		outer = productOf(num(-1), power(root, num(-1)))
+	case "atan":
+		outer = power(sumOf(num(1), power(u, num(2))), num(-1))
+	case "sinh":
+		outer = call("cosh")
+	case "cosh":
+		outer = call("sinh")
+	case "tanh":
+		outer = power(call("cosh"), num(-2))
+	case "exp":
+		outer = n
+	case "ln", "log":
+		outer = power(u, num(-1))
+	case "log10":
+		outer = power(productOf(u, callOf("ln", []Node{num(10)})), num(-1))
+	case "sqrt":
+		outer = power(productOf(num(2), n), num(-1))
+	case "cbrt":
+		outer = power(productOf(num(3), power(n, num(2))), num(-1))
+	case "abs":
+		outer = productOf(u, power(n, num(-1)))
+	default:
+		return nil, fmt.Errorf("cannot differentiate %s", pretty(n))
+	}
+	du, err := e.derivative(u, x)
+	if err != nil {
+		return nil, err
+	}
+	return productOf(outer, du), nil
+}
@@ -0,0 +1,160 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestDiff(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"diff(x^3 * sin(x), x)", "3 * x^2 * sin(x) + x^3 * cos(x)"},
+		{"diff(x^3 - 6*x^2 + 11*x - 6, x)", "3 * x^2 - 12 * x + 11"},
+		{"diff(sin(x) / x, x)", "cos(x) / x - sin(x) / x^2"},
+		{"diff(1 / x, x)", "-1 / x^2"},
+		{"diff(sqrt(x), x)", "1 / (2 * sqrt(x))"},
+		{"diff(exp(2*x), x)", "2 * exp(2 * x)"},
+		{"diff(ln(x^2 + 1), x)", "2 * x / (x^2 + 1)"},
+		{"diff(x^x, x)", "x^x * (ln(x) + 1)"},
+		{"diff(2^x, x)", "2^x * ln(2)"},
+		{"diff(a*x^2 + b*x + c, x)", "2 * a * x + b"},
+		{"diff(pi * r^2, r)", "2 * pi * r"},
+		{"diff(cos(x)^2, x)", "-2 * cos(x) * sin(x)"},
+		{"diff(sqrt(1 - x^2), x)", "-x / sqrt(1 - x^2)"},
+		{"diff(tan(x), x)", "1 / cos(x)^2"},
+		{"diff(atan(x), x)", "1 / (1 + x^2)"},
+		{"diff(log(x, 2), x)", "1 / (x * ln(2))"},
+		{"diff((x + 1)^2, x)", "2 * (x + 1)"},
+		{"diff(x^3, x, 2)", "6 * x"},
+		{"diff(x^3, x, 4)", "0"},
+		{"diff(5, x)", "0"},
+		// User functions are expanded, variables bound to numbers substituted
+		{"f(t) = t^2 + 1", ""},
+		{"diff(f(x) * x, x)", "3 * x^2 + 1"},
+		{"k = 3", ""},
+		{"diff(k * x^2, x)", "6 * x"},
+		{"x = 10", ""},
+		{"diff(x^2, x)", "2 * x"},
+		// Formulas can be differentiated again, or evaluated inside functions
+		{"d = diff(y^4, y)", "4 * y^3"},
+		{"diff(d, y)", "12 * y^2"},
+		{"df(x) = diff(x^3 * sin(x), x)", ""},
+		{"df(0)", "0"},
+		// Each call doubles the expansion of the one it calls
+		{"g0(x) = x + x", ""},
+		{"g1(x) = g0(g0(g0(g0(x))))", ""},
+		{"g2(x) = g1(g1(g1(g1(x))))", ""},
+		{"g3(x) = g2(g2(g2(g2(x))))", ""},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if test.expected != "" && v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	errors := []struct {
+		src     string
+		message string
+	}{
+		{"diff(x mod 2, x)", "cannot differentiate"},
+		{"diff(floor(x), x)", "cannot differentiate floor(x)"},
+		{"diff(x, 2)", "argument 2 must be a variable name"},
+		{"diff(x^2, x, 0)", "order must be a whole number"},
+		{"diff(x^2, x, 11)", "order must be a whole number from 1 to 10"},
+		{"diff(x^x^x^x^x, x, 10)", "derivative of order 5 has more than 10000 nodes"},
+		{"diff(g3(x), x)", "expansion of g0(x) has more than 10000 nodes"},
+		{"simplify(g3(y))", "expansion of g0(x) has more than 10000 nodes"},
+		{"diff(x)", "expects 2 to 3 arguments"},
+		{"d + 1", "expected a number, got 4 * y^3"},
+	}
+	for _, test := range errors {
+		_, err := e.Evaluate(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Evaluate(%q): expected error containing %q, got %v", test.src, test.message, err)
+		}
+	}
+
+	calc := calculator.New()
+	calc.SetAngleMode(calculator.Degrees)
+	if _, err := NewEvaluator(calc, nil).Evaluate("diff(sin(x), x)"); err == nil || !strings.Contains(err.Error(), "need radians") {
+		t.Errorf("Expected error differentiating sin in degrees, got %v", err)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"simplify(x + x)", "2 * x"},
+		{"simplify(x * x^2)", "x^3"},
+		{"simplify(2*x - x - x)", "0"},
+		{"simplify(x*y - y*x)", "0"},
+		{"simplify((2*x)^2)", "4 * x^2"},
+		{"simplify((-x)^2)", "x^2"},
+		{"simplify(-(-x))", "x"},
+		{"simplify(-x^2)", "-x^2"},
+		{"simplify(x/2 + x/2)", "x"},
+		{"simplify(1 / (2*x))", "1 / (2 * x)"},
+		{"simplify(x^-2 * 3)", "3 / x^2"},
+		{"simplify(0.1 + 0.2 + y)", "0.3 + y"},
+		{"simplify(3 - 5*x)", "3 - 5 * x"},
+		{"simplify((x^2)^3)", "x^6"},
+		{"simplify(sqrt(x)^2)", "x"},
+		{"simplify(ln(exp(y)))", "y"},
+		{"simplify(exp(0) + sin(0) + ln(1))", "1"},
+		{"simplify(sqrt(16) * x)", "4 * x"},
+		{"simplify(x^0 + 0*y + 1*z)", "1 + z"},
+		{"simplify(2^10)", "1024"},
+		{"simplify(x - (y - z))", "x - y + z"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+}
+
+func TestPretty(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"(a + b) * c", "(a + b) * c"},
+		{"a - (b + c)", "a - (b + c)"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"a / (b * c)", "a / (b * c)"},
+		{"(a ^ b) ^ c", "(a^b)^c"},
+		{"a ^ b ^ c", "a^b^c"},
+		{"(-a) ^ 2", "(-a)^2"},
+		{"-(a * b)", "-a * b"},
+		{"f(a + b, c)", "f(a + b, c)"},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.src)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", test.src, err)
+		}
+		if s := pretty(node); s != test.expected {
+			t.Errorf("pretty(%q): expected %s, got %s", test.src, test.expected, s)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+// Value is the result of evaluating an expression
+type Value interface {
+	String() string
+}
+
+// Float is a double precision number, the default kind of value
+type Float float64
+
+// String formats the number with the fewest digits that represent it exactly
+func (f Float) String() string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+// BigFloat is an arbitrary precision number produced when the Calculator has a
+// non-zero precision
+type BigFloat struct {
+	X *big.Float
+}
+
+// String formats the number with as many significant digits as its mantissa holds
+func (b BigFloat) String() string {
+	return b.X.Text('g', calculator.DigitsForBits(b.X.Prec()))
+}
+
+// Rat is an exact fraction produced in rational mode
+type Rat struct {
+	X *big.Rat
+}
+
+// String formats the fraction in lowest terms, e.g. 1/3, or as an integer when the denominator is 1
+func (r Rat) String() string {
+	return r.X.RatString()
+}
+
+// Int is a fixed-width integer produced in integer (programmer) mode
+type Int struct {
+	X *big.Int
+}
+
+// String formats the integer in decimal
+func (i Int) String() string {
+	return i.X.String()
+}
+
+// Complex is a complex number produced in complex mode
+type Complex complex128
+
+// String formats the number in rectangular form such as 3+4i, omitting a zero
+// imaginary or real part
+func (z Complex) String() string {
+	return rectangular(complex128(z), func(f float64) string { return Float(f).String() })
+}
+
+// rectangular formats z as a+bi using part to format each component
+func rectangular(z complex128, part func(float64) string) string {
+	re, im := real(z), imag(z)
+	switch {
+	case im == 0:
+		return part(re)
+	case re == 0:
+		return part(im) + "i"
+	case im < 0:
+		return part(re) + "-" + part(-im) + "i"
+	default:
+		return part(re) + "+" + part(im) + "i"
+	}
+}
+
+// ToFloat converts a numeric value to float64
+func ToFloat(v Value) (float64, error) {
+	switch x := v.(type) {
+	case Float:
+		return float64(x), nil
+	case BigFloat:
+		f, _ := x.X.Float64()
+		return f, nil
+	case Rat:
+		f, _ := x.X.Float64()
+		return f, nil
+	case Int:
+		f, _ := new(big.Float).SetInt(x.X).Float64()
+		return f, nil
+	case Complex:
+		if imag(x) != 0 {
+			return 0, fmt.Errorf("expected a real number, got %s", v)
+		}
+		return real(x), nil
+	case Percent:
+		f, err := ToFloat(x.X)
+		if err != nil {
+			return 0, err
+		}
+		return f / 100, nil
+	default:
+		return 0, fmt.Errorf("expected a number, got %s", v)
+	}
+}
+
+// toComplex converts a numeric value to complex128
+func toComplex(v Value) (complex128, error) {
+	if z, ok := v.(Complex); ok {
+		return complex128(z), nil
+	}
+	f, err := ToFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	return complex(f, 0), nil
+}
+
+// toBig converts a numeric value to a big.Float at the calculator's precision
+func toBig(calc *calculator.Calculator, v Value) (*big.Float, error) {
+	switch x := v.(type) {
+	case BigFloat:
+		return x.X, nil
+	case Float:
+		return calc.BigFromFloat(float64(x))
+	case Rat:
+		return new(big.Float).SetPrec(calc.Precision()).SetMode(calc.RoundingMode()).SetRat(x.X), nil
+	case Int:
+		return new(big.Float).SetPrec(calc.Precision()).SetMode(calc.RoundingMode()).SetInt(x.X), nil
+	case Complex:
+		f, err := ToFloat(x)
+		if err != nil {
+			return nil, err
+		}
+		return calc.BigFromFloat(f)
+	case Percent:
+		f, err := toBig(calc, x.X)
+		if err != nil {
+			return nil, err
+		}
+		return calc.BigDivide(f, new(big.Float).SetPrec(calc.Precision()).SetInt64(100))
+	default:
+		return nil, fmt.Errorf("expected a number, got %s", v)
+	}
+}
+
+// toInt converts a numeric value with no fractional part to a big.Int for the
+// bitwise operators
+func toInt(v Value) (*big.Int, error) {
+	switch x := v.(type) {
+	case Int:
+		return x.X, nil
+	case Rat:
+		if x.X.IsInt() {
+			return new(big.Int).Set(x.X.Num()), nil
+		}
+	case BigFloat:
+		if x.X.IsInt() {
+			i, _ := x.X.Int(nil)
+			return i, nil
+		}
+	case Float, Complex:
+		f, err := ToFloat(x)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %s", v)
+		}
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			i, _ := big.NewFloat(f).Int(nil)
+			return i, nil
+		}
+	default:
+		return nil, fmt.Errorf("expected a number, got %s", v)
+	}
+	return nil, fmt.Errorf("expected an integer, got %s", v)
+}
+
+// isTruthy reports whether a value counts as true in a condition, i.e. is non-zero
+func isTruthy(v Value) (bool, error) {
+	switch x := v.(type) {
+	case BigFloat:
+		return x.X.Sign() != 0, nil
+	case Rat:
+		return x.X.Sign() != 0, nil
+	case Int:
+		return x.X.Sign() != 0, nil
+	case Complex:
+		return x != 0, nil
+	case Percent:
+		return isTruthy(x.X)
+	}
+	f, err := ToFloat(v)
+	if err != nil {
+		return false, err
+	}
+	return f != 0, nil
+}
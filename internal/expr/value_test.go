@@ -0,0 +1,283 @@
+package expr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jondkelley/cicd_golang_calculator/internal/calculator"
+)
+
+func TestValueString(t *testing.T) {
+	a, b := 0.1, 0.2
+	if s := Float(a + b).String(); s != "0.30000000000000004" {
+		t.Errorf("Expected shortest float64 formatting, got %s", s)
+	}
+	x, _, _ := big.ParseFloat("0.1", 10, calculator.BitsForDigits(25), big.ToNearestEven)
+	if s := (BigFloat{x}).String(); s != "0.1" {
+		t.Errorf("Expected 0.1, got %s", s)
+	}
+}
+
+func TestPrecisionMode(t *testing.T) {
+	calc := calculator.New()
+	calc.SetPrecision(calculator.BitsForDigits(40))
+	env := NewEnv()
+	e := NewEvaluator(calc, env)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"0.1 + 0.2", "0.3"},
+		{"2^64 + 1", "18446744073709551617"},
+		{"sqrt(2)", "1.41421356237309504880168872420969807857"},
+		{"pi * 2 - tau", "0"},
+		{"ln(e)", "1"},
+		{"exp(ln2)", "2"},
+		{"-(2 ^ 100)", "-1267650600228229401496703205376"},
+		{"if(2^64 + 1 - 18446744073709551617, 1, 2)", "2"},
+		{"max(1, 2)", "2"},
+	}
+
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	// max has no arbitrary precision implementation and falls back to float64
+	if v, _ := e.Evaluate("max(1, 2)"); v != Float(2) {
+		t.Errorf("Expected float64 fallback for max, got %#v", v)
+	}
+
+	// Values stored in precision mode become float64 once it is switched off
+	if _, err := e.Evaluate("third = 1 / 3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	calc.SetPrecision(0)
+	if v, err := e.Evaluate("third"); err != nil || v != Float(1.0/3) {
+		t.Errorf("Expected float64 1/3 after disabling precision, got %#v (err: %v)", v, err)
+	}
+}
+
+func TestRationalMode(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.RationalMode)
+	e := NewEvaluator(calc, nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"1/3 * 3", "1"},
+		{"1/3 + 1/6", "1/2"},
+		{"-1/4", "-1/4"},
+		{"(1/2) ^ 10", "1/1024"},
+		{"10 % 4", "2"},
+		{"if(1/3 * 3 - 1, 5, 6)", "6"},
+		{"1/3 + sqrt(4)", "2.3333333333333335"}, // float64 operands make the result inexact
+		{"4 ^ 0.5", "2"},
+	}
+
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	if v, _ := e.Evaluate("4 ^ 0.5"); v != Float(2) {
+		t.Errorf("Expected a fractional exponent to produce a float64, got %#v", v)
+	}
+}
+
+func TestIntegerMode(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.IntegerMode)
+	e := NewEvaluator(calc, nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"7 / 2", "3"},
+		{"-7 / 2", "-3"},
+		{"-7 % 2", "-1"},
+		{"0xff & 0x0f", "15"},
+		{"0b1010 | 0b0101", "15"},
+		{"6 xor 3", "5"},
+		{"~0", "-1"},
+		{"1 << 62 >> 60", "4"},
+		{"-16 >> 2", "-4"},
+		{"2 ^ 63", "-9223372036854775808"}, // wraps in a signed 64 bit word
+		{"sqrt(16) + 1", "5"},
+	}
+
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	if v, _ := e.Evaluate("3 * 4"); v.String() != "12" {
+		t.Errorf("Expected integer product 12, got %#v", v)
+	} else if _, ok := v.(Int); !ok {
+		t.Errorf("Expected an Int result, got %#v", v)
+	}
+
+	for _, src := range []string{"1.5 + 1", "1 / 0", "5 % 0", "2 ^ -1", "1 << -1", "sqrt(2) & 1"} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestIntegerModeWordSize(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.IntegerMode)
+	if err := calc.SetWordSize(calculator.WordSize{Bits: 8}); err != nil {
+		t.Fatalf("SetWordSize failed: %v", err)
+	}
+	e := NewEvaluator(calc, nil)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"255 + 1", "0"},
+		{"0 - 1", "255"},
+		{"~0", "255"},
+		{"0xf0 >> 4", "15"},
+		{"1 << 9", "0"},
+	}
+	for _, test := range tests {
+		if v, err := e.Evaluate(test.src); err != nil || v.String() != test.expected {
+			t.Errorf("Evaluate(%q) in u8: expected %s, got %v (err: %v)", test.src, test.expected, v, err)
+		}
+	}
+
+	calc.SetOverflowMode(calculator.OverflowError)
+	for _, src := range []string{"255 + 1", "0 - 1", "256", "1 << 8", "16 * 16"} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected overflow error evaluating %q in u8", src)
+		}
+	}
+	if v, err := e.Evaluate("~0"); err != nil || v.String() != "255" {
+		t.Errorf("Expected complement to ignore overflow mode, got %v (err: %v)", v, err)
+	}
+}
+
+func TestBitwiseOutsideIntegerMode(t *testing.T) {
+	e := NewEvaluator(calculator.New(), nil)
+
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"0xff & 0x0f", 15},
+		{"1 << 10", 1024},
+		{"7 // 2", 3},
+		{"-7 // 2", -3},
+		{"7.5 // 2", 3},
+	}
+	for _, test := range tests {
+		if v, err := evaluateFloat(e, test.src); err != nil || v != test.expected {
+			t.Errorf("Evaluate(%q): expected %v, got %v (err: %v)", test.src, test.expected, v, err)
+		}
+	}
+	for _, src := range []string{"1.5 & 1", "1 // 0"} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+}
+
+func TestComplexMode(t *testing.T) {
+	calc := calculator.New()
+	calc.SetNumberMode(calculator.ComplexMode)
+	env := NewEnv()
+	e := NewEvaluator(calc, env)
+
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"3+4i", "3+4i"},
+		{"(3+4i) * (1-2i)", "11-2i"},
+		{"sqrt(-9)", "3i"},
+		{"i * i", "-1"},
+		{"(1+i)^4", "-4"},
+		{"exp(pi * i) + 1", "1.2246467991473515e-16i"},
+		{"ln(-1)", "3.141592653589793i"},
+		{"abs(3-4i)", "5"},
+		{"conj(2+3i)", "2-3i"},
+		{"arg(-1)", "3.141592653589793"},
+		{"re(2-3i) + im(2-3i)", "-1"},
+		{"sqrt(16)", "4"},
+		{"7 % 4", "3"},
+		{"if(0i, 1, 2)", "2"},
+	}
+	for _, test := range tests {
+		v, err := e.Evaluate(test.src)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", test.src, err)
+			continue
+		}
+		if v.String() != test.expected {
+			t.Errorf("Evaluate(%q): expected %s, got %s", test.src, test.expected, v)
+		}
+	}
+
+	for _, src := range []string{"1 / 0i", "floor(1+i)", "(1+i) // 2", "0 ^ -1"} {
+		if _, err := e.Evaluate(src); err == nil {
+			t.Errorf("Expected error evaluating %q", src)
+		}
+	}
+
+	// A variable named i hides the imaginary unit
+	env.Set("i", Float(10))
+	if v, err := e.Evaluate("2 * i"); err != nil || v.String() != "20" {
+		t.Errorf("Expected variable i to take precedence, got %v (err: %v)", v, err)
+	}
+
+	calc.SetNumberMode(calculator.FloatMode)
+	if _, err := e.Evaluate("2i"); err == nil {
+		t.Error("Expected an imaginary literal to need complex mode")
+	}
+}
+
+func TestRenderComplex(t *testing.T) {
+	calc := calculator.New()
+	tests := []struct {
+		format   Format
+		value    Complex
+		expected string
+	}{
+		{Format{}, Complex(complex(1, -1)), "1-1i"},
+		{Format{Notation: NotationFixed, Digits: 1}, Complex(complex(1.25, 2)), "1.2+2.0i"},
+		{Format{Polar: true}, Complex(complex(0, 2)), "2∠1.5707963267948966 rad"},
+		{Format{Radix: 16}, Complex(complex(255, 0)), "0xff"},
+	}
+	for _, test := range tests {
+		if s, err := test.format.Render(calc, test.value); err != nil || s != test.expected {
+			t.Errorf("Render(%s, %s): expected %s, got %s (err: %v)", test.format, test.value, test.expected, s, err)
+		}
+	}
+	if _, err := (Format{Radix: 16}).Render(calc, Complex(1i)); err == nil {
+		t.Error("Expected error displaying an imaginary number in hex")
+	}
+}
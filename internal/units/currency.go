@@ -0,0 +1,61 @@
+package units
+
+import (
+	"sort"
+	"sync"
+)
+
+// dimCurrency is the dimension of money
+var dimCurrency = dim(0, 0, 0, 0, 0, 0, 0, 1)
+
+// currencies holds the registered currency units, keyed by code, with the
+// value of one unit of each in a common reference currency
+var currencies struct {
+	sync.RWMutex
+	values map[string]float64
+}
+
+// SetCurrencies replaces the registered currencies. values maps currency codes
+// such as EUR to the value of one unit of that currency in a common reference
+// currency, so that converting between two codes uses the ratio of their values.
+// Codes that clash with a built-in unit are ignored.
+func SetCurrencies(values map[string]float64) {
+	m := make(map[string]float64, len(values))
+	for code, v := range values {
+		if _, clash := unitIndex[code]; !clash && v > 0 {
+			m[code] = v
+		}
+	}
+	currencies.Lock()
+	currencies.values = m
+	currencies.Unlock()
+}
+
+// Currencies returns the registered currency codes in sorted order
+func Currencies() []string {
+	currencies.RLock()
+	defer currencies.RUnlock()
+	codes := make([]string, 0, len(currencies.values))
+	for code := range currencies.values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// IsMoney reports whether u measures an amount of money
+func (u Unit) IsMoney() bool {
+	return u.Dim[Currency] != 0
+}
+
+// lookupCurrency returns the registered currency with the given code. Currencies
+// take no metric prefixes.
+func lookupCurrency(code string) (Unit, bool) {
+	currencies.RLock()
+	defer currencies.RUnlock()
+	v, ok := currencies.values[code]
+	if !ok {
+		return Unit{}, false
+	}
+	return Unit{Factor: v, Dim: dimCurrency, terms: []term{{code, 1}}}, true
+}
@@ -0,0 +1,203 @@
+package units
+
+import (
+	"math"
+	"sort"
+)
+
+// definition is an entry of the unit database
+type definition struct {
+	names       []string // the first name is the one listed by Units
+	factor      float64  // size in SI base units
+	offset      float64
+	dim         Dimension
+	prefixable  bool // accepts metric prefixes such as k and m
+	description string
+}
+
+// unit returns the named unit scaled by a prefix factor
+func (d definition) unit(name string, prefix float64) Unit {
+	return Unit{Factor: d.factor * prefix, Offset: d.offset, Dim: d.dim, terms: []term{{name, 1}}}
+}
+
+// prefix is a metric prefix such as k for 1e3
+type prefix struct {
+	symbols []string
+	factor  float64
+}
+
+// prefixes are tried longest symbol first so that da is not read as d
+var prefixes = []prefix{
+	{[]string{"da"}, 1e1},
+	{[]string{"Y"}, 1e24},
+	{[]string{"Z"}, 1e21},
+	{[]string{"E"}, 1e18},
+	{[]string{"P"}, 1e15},
+	{[]string{"T"}, 1e12},
+	{[]string{"G"}, 1e9},
+	{[]string{"M"}, 1e6},
+	{[]string{"k"}, 1e3},
+	{[]string{"h"}, 1e2},
+	{[]string{"d"}, 1e-1},
+	{[]string{"c"}, 1e-2},
+	{[]string{"m"}, 1e-3},
+	{[]string{"µ", "μ", "u"}, 1e-6},
+	{[]string{"n"}, 1e-9},
+	{[]string{"p"}, 1e-12},
+	{[]string{"f"}, 1e-15},
+	{[]string{"a"}, 1e-18},
+}
+
+// dim builds a Dimension from exponents of length, mass, time, current,
+// temperature, amount and luminosity
+func dim(exponents ...int) Dimension {
+	var d Dimension
+	copy(d[:], exponents)
+	return d
+}
+
+var (
+	dimLength      = dim(1)
+	dimMass        = dim(0, 1)
+	dimTime        = dim(0, 0, 1)
+	dimCurrent     = dim(0, 0, 0, 1)
+	dimTemperature = dim(0, 0, 0, 0, 1)
+	dimAmount      = dim(0, 0, 0, 0, 0, 1)
+	dimLuminosity  = dim(0, 0, 0, 0, 0, 0, 1)
+	dimArea        = dim(2)
+	dimVolume      = dim(3)
+	dimFrequency   = dim(0, 0, -1)
+	dimVelocity    = dim(1, 0, -1)
+	dimForce       = dim(1, 1, -2)
+	dimPressure    = dim(-1, 1, -2)
+	dimEnergy      = dim(2, 1, -2)
+	dimPower       = dim(2, 1, -3)
+	dimCharge      = dim(0, 0, 1, 1)
+	dimVoltage     = dim(2, 1, -3, -1)
+	dimCapacitance = dim(-2, -1, 4, 2)
+	dimResistance  = dim(2, 1, -3, -2)
+	dimConductance = dim(-2, -1, 3, 2)
+	dimFlux        = dim(2, 1, -2, -1)
+	dimFluxDensity = dim(0, 1, -2, -1)
+	dimInductance  = dim(2, 1, -2, -2)
+)
+
+const (
+	inch  = 0.0254
+	foot  = 12 * inch
+	mile  = 5280 * foot
+	pound = 0.45359237
+	hour  = 3600.0
+	// gallon is the US liquid gallon of 231 cubic inches
+	gallon = 231 * inch * inch * inch
+)
+
+// database lists the built-in units. The symbol in is reserved for conversions,
+// so inches are written inch.
+var database = []definition{
+	// SI base units; the kilogram is the gram with a prefix
+	{[]string{"m", "meter", "metre"}, 1, 0, dimLength, true, "metre"},
+	{[]string{"g", "gram"}, 1e-3, 0, dimMass, true, "gram"},
+	{[]string{"s", "sec", "second"}, 1, 0, dimTime, true, "second"},
+	{[]string{"A", "amp", "ampere"}, 1, 0, dimCurrent, true, "ampere"},
+	{[]string{"K", "kelvin"}, 1, 0, dimTemperature, true, "kelvin"},
+	{[]string{"mol", "mole"}, 1, 0, dimAmount, true, "mole"},
+	{[]string{"cd", "candela"}, 1, 0, dimLuminosity, true, "candela"},
+
+	// SI derived units
+	{[]string{"Hz", "hertz"}, 1, 0, dimFrequency, true, "hertz, 1/s"},
+	{[]string{"N", "newton"}, 1, 0, dimForce, true, "newton, kg*m/s^2"},
+	{[]string{"Pa", "pascal"}, 1, 0, dimPressure, true, "pascal, N/m^2"},
+	{[]string{"J", "joule"}, 1, 0, dimEnergy, true, "joule, N*m"},
+	{[]string{"W", "watt"}, 1, 0, dimPower, true, "watt, J/s"},
+	{[]string{"C", "coulomb"}, 1, 0, dimCharge, true, "coulomb, A*s"},
+	{[]string{"V", "volt"}, 1, 0, dimVoltage, true, "volt, W/A"},
+	{[]string{"F", "farad"}, 1, 0, dimCapacitance, true, "farad, C/V"},
+	{[]string{"ohm", "Ω"}, 1, 0, dimResistance, true, "ohm, V/A"},
+	{[]string{"S", "siemens"}, 1, 0, dimConductance, true, "siemens, 1/ohm"},
+	{[]string{"Wb", "weber"}, 1, 0, dimFlux, true, "weber, V*s"},
+	{[]string{"T", "tesla"}, 1, 0, dimFluxDensity, true, "tesla, Wb/m^2"},
+	{[]string{"H", "henry"}, 1, 0, dimInductance, true, "henry, Wb/A"},
+	{[]string{"degC", "°C", "celsius"}, 1, 273.15, dimTemperature, false, "degree Celsius"},
+
+	// Units accepted for use with the SI
+	{[]string{"min", "minute"}, 60, 0, dimTime, false, "minute"},
+	{[]string{"h", "hr", "hour"}, hour, 0, dimTime, false, "hour"},
+	{[]string{"d", "day"}, 24 * hour, 0, dimTime, false, "day"},
+	{[]string{"wk", "week"}, 7 * 24 * hour, 0, dimTime, false, "week"},
+	{[]string{"yr", "year"}, 365.25 * 24 * hour, 0, dimTime, false, "Julian year of 365.25 days"},
+	{[]string{"L", "l", "liter", "litre"}, 1e-3, 0, dimVolume, true, "litre"},
+	{[]string{"ha", "hectare"}, 1e4, 0, dimArea, false, "hectare"},
+	{[]string{"tonne"}, 1e3, 0, dimMass, false, "metric ton"},
+	{[]string{"Wh"}, hour, 0, dimEnergy, true, "watt hour"},
+	{[]string{"eV"}, 1.602176634e-19, 0, dimEnergy, true, "electronvolt"},
+	{[]string{"cal", "calorie"}, 4.184, 0, dimEnergy, true, "thermochemical calorie"},
+	{[]string{"bar"}, 1e5, 0, dimPressure, true, "bar"},
+	{[]string{"atm"}, 101325, 0, dimPressure, false, "standard atmosphere"},
+	{[]string{"mmHg"}, 133.322387415, 0, dimPressure, false, "millimetre of mercury"},
+
+	// Imperial and US customary units
+	{[]string{"inch", "inches"}, inch, 0, dimLength, false, "inch"},
+	{[]string{"ft", "foot", "feet"}, foot, 0, dimLength, false, "foot"},
+	{[]string{"yd", "yard"}, 3 * foot, 0, dimLength, false, "yard"},
+	{[]string{"mi", "mile"}, mile, 0, dimLength, false, "international mile"},
+	{[]string{"nmi"}, 1852, 0, dimLength, false, "nautical mile"},
+	{[]string{"acre"}, 43560 * foot * foot, 0, dimArea, false, "acre"},
+	{[]string{"gal", "gallon"}, gallon, 0, dimVolume, false, "US gallon"},
+	{[]string{"qt", "quart"}, gallon / 4, 0, dimVolume, false, "US quart"},
+	{[]string{"pt", "pint"}, gallon / 8, 0, dimVolume, false, "US pint"},
+	{[]string{"cup"}, gallon / 16, 0, dimVolume, false, "US cup"},
+	{[]string{"floz"}, gallon / 128, 0, dimVolume, false, "US fluid ounce"},
+	{[]string{"lb", "lbs", "pound"}, pound, 0, dimMass, false, "avoirdupois pound"},
+	{[]string{"oz", "ounce"}, pound / 16, 0, dimMass, false, "avoirdupois ounce"},
+	{[]string{"st", "stone"}, 14 * pound, 0, dimMass, false, "stone"},
+	{[]string{"ton"}, 2000 * pound, 0, dimMass, false, "US short ton"},
+	{[]string{"mph"}, mile / hour, 0, dimVelocity, false, "mile per hour"},
+	{[]string{"kn", "knot"}, 1852 / hour, 0, dimVelocity, false, "knot"},
+	{[]string{"lbf"}, pound * 9.80665, 0, dimForce, false, "pound-force"},
+	{[]string{"psi"}, pound * 9.80665 / (inch * inch), 0, dimPressure, false, "pound-force per square inch"},
+	{[]string{"BTU"}, 1055.05585262, 0, dimEnergy, false, "international table British thermal unit"},
+	{[]string{"hp"}, 745.69987158227022, 0, dimPower, false, "mechanical horsepower"},
+	{[]string{"degF", "°F", "fahrenheit"}, 5.0 / 9, 273.15 - 32*5.0/9, dimTemperature, false, "degree Fahrenheit"},
+}
+
+// unitIndex maps every unit name to its definition
+var unitIndex = func() map[string]definition {
+	index := make(map[string]definition)
+	for _, d := range database {
+		for _, name := range d.names {
+			index[name] = d
+		}
+	}
+	return index
+}()
+
+// Info describes a built-in unit for listings
+type Info struct {
+	Names       []string
+	Unit        Unit // the unit under its first name
+	Prefixable  bool
+	Description string
+}
+
+// Units returns the built-in units and registered currencies sorted by dimension
+// and then size
+func Units() []Info {
+	infos := make([]Info, len(database))
+	for i, d := range database {
+		infos[i] = Info{Names: d.names, Unit: d.unit(d.names[0], 1), Prefixable: d.prefixable, Description: d.description}
+	}
+	for _, code := range Currencies() {
+		if u, ok := lookupCurrency(code); ok {
+			infos = append(infos, Info{Names: []string{code}, Unit: u, Description: "currency"})
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i].Unit, infos[j].Unit
+		if a.Dim != b.Dim {
+			return a.Dim.String() < b.Dim.String()
+		}
+		return math.Abs(a.Factor) < math.Abs(b.Factor)
+	})
+	return infos
+}
@@ -0,0 +1,354 @@
+// Package units implements units of measure for calculator quantities: a database
+// of SI, metric-prefixed and imperial units, dimensional analysis over the seven
+// SI base dimensions plus money, and conversion between compatible units.
+// Currencies are registered at run time from exchange rates.
+package units
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Base dimensions, in the order used by Dimension
+const (
+	Length = iota
+	Mass
+	Time
+	Current
+	Temperature
+	Amount
+	Luminosity
+	Currency
+	numBase
+)
+
+// baseSymbols are the SI units of the base dimensions, used to describe
+// dimensions; money is shown with the generic currency sign
+var baseSymbols = [numBase]string{"m", "kg", "s", "A", "K", "mol", "cd", "¤"}
+
+// Dimension holds the exponent of each base dimension; velocity is length^1 time^-1
+type Dimension [numBase]int
+
+// IsZero reports whether the dimension is that of a pure number
+func (d Dimension) IsZero() bool {
+	return d == Dimension{}
+}
+
+// Mul returns the dimension of a product
+func (d Dimension) Mul(e Dimension) Dimension {
+	for i := range d {
+		d[i] += e[i]
+	}
+	return d
+}
+
+// Pow returns the dimension raised to an integer power
+func (d Dimension) Pow(n int) Dimension {
+	for i := range d {
+		d[i] *= n
+	}
+	return d
+}
+
+// String describes the dimension in SI base units, e.g. kg*m^2/s^2
+func (d Dimension) String() string {
+	var terms []term
+	for i, p := range d {
+		if p != 0 {
+			terms = append(terms, term{baseSymbols[i], p})
+		}
+	}
+	if len(terms) == 0 {
+		return "1"
+	}
+	// Mass first, as in kg*m^2/s^2
+	sort.SliceStable(terms, func(i, j int) bool { return terms[i].name == "kg" && terms[j].name != "kg" })
+	return formatTerms(terms)
+}
+
+// term is one factor of a unit, such as s^-2
+type term struct {
+	name  string
+	power int
+}
+
+// Unit is a unit of measure, possibly compound such as km/h. Factor is the size
+// of the unit in SI base units; Offset is added after scaling for units whose zero
+// differs from the SI zero, such as degC.
+type Unit struct {
+	Factor float64
+	Offset float64
+	Dim    Dimension
+	terms  []term
+}
+
+// String returns the unit in the form accepted by Parse, e.g. kW*h or m/s^2
+func (u Unit) String() string {
+	if len(u.terms) == 0 {
+		return "1"
+	}
+	return formatTerms(u.terms)
+}
+
+// formatTerms writes the positive powers joined by * followed by each negative power after a /
+func formatTerms(terms []term) string {
+	var num, den []string
+	for _, t := range terms {
+		p := t.power
+		if p < 0 {
+			p = -p
+		}
+		s := t.name
+		if p != 1 {
+			s += "^" + strconv.Itoa(p)
+		}
+		if t.power > 0 {
+			num = append(num, s)
+		} else {
+			den = append(den, s)
+		}
+	}
+	s := strings.Join(num, "*")
+	if s == "" {
+		s = "1"
+	}
+	for _, d := range den {
+		s += "/" + d
+	}
+	return s
+}
+
+// ToSI converts a magnitude in this unit to SI base units
+func (u Unit) ToSI(x float64) float64 {
+	return x*u.Factor + u.Offset
+}
+
+// FromSI converts a magnitude in SI base units to this unit
+func (u Unit) FromSI(x float64) float64 {
+	return (x - u.Offset) / u.Factor
+}
+
+// Compatible reports whether quantities in u and v can be added or converted
+func (u Unit) Compatible(v Unit) bool {
+	return u.Dim == v.Dim
+}
+
+// Mul returns the product of two units, combining repeated factors so that
+// km*km is km^2. Offsets only apply to a unit on its own and are dropped.
+func (u Unit) Mul(v Unit) Unit {
+	terms := append([]term(nil), u.terms...)
+	for _, t := range v.terms {
+		terms = addTerm(terms, t)
+	}
+	return Unit{Factor: u.Factor * v.Factor, Dim: u.Dim.Mul(v.Dim), terms: terms}
+}
+
+// Div returns the quotient of two units
+func (u Unit) Div(v Unit) Unit {
+	return u.Mul(v.Pow(-1))
+}
+
+// Pow returns the unit raised to an integer power
+func (u Unit) Pow(n int) Unit {
+	if n == 1 {
+		return u
+	}
+	terms := make([]term, 0, len(u.terms))
+	for _, t := range u.terms {
+		if t.power*n != 0 {
+			terms = append(terms, term{t.name, t.power * n})
+		}
+	}
+	return Unit{Factor: math.Pow(u.Factor, float64(n)), Dim: u.Dim.Pow(n), terms: terms}
+}
+
+// Root returns the nth root of the unit, such as m for m^2, and false when a
+// power of the unit is not divisible by n
+func (u Unit) Root(n int) (Unit, bool) {
+	terms := make([]term, len(u.terms))
+	for i, t := range u.terms {
+		if t.power%n != 0 {
+			return Unit{}, false
+		}
+		terms[i] = term{t.name, t.power / n}
+	}
+	var dim Dimension
+	for i, p := range u.Dim {
+		if p%n != 0 {
+			return Unit{}, false
+		}
+		dim[i] = p / n
+	}
+	return Unit{Factor: math.Pow(u.Factor, 1/float64(n)), Dim: dim, terms: terms}, true
+}
+
+// addTerm multiplies terms by t, merging it with an existing factor of the same name
+func addTerm(terms []term, t term) []term {
+	for i := range terms {
+		if terms[i].name == t.name {
+			terms[i].power += t.power
+			if terms[i].power == 0 {
+				return append(terms[:i:i], terms[i+1:]...)
+			}
+			return terms
+		}
+	}
+	return append(terms, t)
+}
+
+// Lookup returns the unit called name, which may carry a metric prefix such as
+// the k in km, and whether it exists
+func Lookup(name string) (Unit, bool) {
+	if d, ok := unitIndex[name]; ok {
+		return d.unit(name, 1), true
+	}
+	if u, ok := lookupCurrency(name); ok {
+		return u, true
+	}
+	for _, p := range prefixes {
+		for _, symbol := range p.symbols {
+			rest, ok := strings.CutPrefix(name, symbol)
+			if !ok || rest == "" {
+				continue
+			}
+			if d, ok := unitIndex[rest]; ok && d.prefixable {
+				return d.unit(name, p.factor), true
+			}
+		}
+	}
+	return Unit{}, false
+}
+
+// IsUnit reports whether name is a unit, with or without a prefix
+func IsUnit(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Parse parses a unit expression such as km, m/s^2, kW*h, N m or J/(mol*K).
+// Factors are separated by *, / or spaces and may be raised to integer powers.
+func Parse(s string) (Unit, error) {
+	p := &unitParser{src: []rune(s)}
+	u, err := p.parseProduct()
+	if err != nil {
+		return Unit{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return Unit{}, fmt.Errorf("unexpected %q in unit %q", p.src[p.pos], s)
+	}
+	return u, nil
+}
+
+// unitParser is a recursive-descent parser for unit expressions
+type unitParser struct {
+	src []rune
+	pos int
+}
+
+func (p *unitParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// parseProduct parses factors separated by *, / or juxtaposition, left to right
+func (p *unitParser) parseProduct() (Unit, error) {
+	u, err := p.parsePower()
+	if err != nil {
+		return Unit{}, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] == ')' {
+			return u, nil
+		}
+		divide := false
+		switch p.src[p.pos] {
+		case '*', '·':
+			p.pos++
+		case '/':
+			divide = true
+			p.pos++
+		}
+		v, err := p.parsePower()
+		if err != nil {
+			return Unit{}, err
+		}
+		if divide {
+			u = u.Div(v)
+		} else {
+			u = u.Mul(v)
+		}
+	}
+}
+
+// parsePower parses a factor with an optional integer exponent
+func (p *unitParser) parsePower() (Unit, error) {
+	u, err := p.parseFactor()
+	if err != nil {
+		return Unit{}, err
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '^' {
+		return u, nil
+	}
+	p.pos++
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.src) && (p.src[p.pos] == '-' || p.src[p.pos] == '+') {
+		p.pos++
+	}
+	for p.pos < len(p.src) && unicode.IsDigit(p.src[p.pos]) {
+		p.pos++
+	}
+	n, err := strconv.Atoi(string(p.src[start:p.pos]))
+	if err != nil {
+		return Unit{}, fmt.Errorf("expected an integer exponent at position %d", start)
+	}
+	return u.Pow(n), nil
+}
+
+// parseFactor parses a unit name, a parenthesized unit expression or 1, as in 1/s
+func (p *unitParser) parseFactor() (Unit, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return Unit{}, fmt.Errorf("missing unit")
+	}
+	switch r := p.src[p.pos]; {
+	case r == '(':
+		p.pos++
+		u, err := p.parseProduct()
+		if err != nil {
+			return Unit{}, err
+		}
+		if p.skipSpace(); p.pos >= len(p.src) || p.src[p.pos] != ')' {
+			return Unit{}, fmt.Errorf("missing ')' in unit")
+		}
+		p.pos++
+		return u, nil
+	case r == '1':
+		p.pos++
+		return Unit{Factor: 1}, nil
+	case isNameRune(r):
+		start := p.pos
+		for p.pos < len(p.src) && isNameRune(p.src[p.pos]) {
+			p.pos++
+		}
+		name := string(p.src[start:p.pos])
+		u, ok := Lookup(name)
+		if !ok {
+			return Unit{}, fmt.Errorf("unknown unit %q", name)
+		}
+		return u, nil
+	default:
+		return Unit{}, fmt.Errorf("unexpected %q in unit", r)
+	}
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r == '°' || unicode.IsLetter(r)
+}
@@ -0,0 +1,184 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor float64
+		dim    Dimension
+	}{
+		{"m", 1, dimLength},
+		{"km", 1e3, dimLength},
+		{"kg", 1, dimMass},
+		{"µs", 1e-6, dimTime},
+		{"us", 1e-6, dimTime},
+		{"dam", 10, dimLength},
+		{"kWh", 3.6e6, dimEnergy},
+		{"mi", 1609.344, dimLength},
+		{"min", 60, dimTime},
+		{"ft", 0.3048, dimLength},
+		{"mL", 1e-6, dimVolume},
+	}
+	for _, test := range tests {
+		u, ok := Lookup(test.name)
+		if !ok {
+			t.Errorf("Lookup(%q) failed", test.name)
+			continue
+		}
+		if math.Abs(u.Factor-test.factor) > 1e-12*test.factor || u.Dim != test.dim {
+			t.Errorf("Lookup(%q): expected factor %g and dimension %s, got %g and %s", test.name, test.factor, test.dim, u.Factor, u.Dim)
+		}
+		if u.String() != test.name {
+			t.Errorf("Lookup(%q): expected name %s, got %s", test.name, test.name, u)
+		}
+	}
+
+	// Imperial units and units with an offset take no prefix
+	for _, name := range []string{"kft", "kdegC", "x", "k", "in", ""} {
+		if IsUnit(name) {
+			t.Errorf("Expected %q not to be a unit", name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src    string
+		name   string
+		factor float64
+		dim    Dimension
+	}{
+		{"km/h", "km/h", 1e3 / 3600, dimVelocity},
+		{"m/s^2", "m/s^2", 1, dim(1, 0, -2)},
+		{"kW*h", "kW*h", 3.6e6, dimEnergy},
+		{"N m", "N*m", 1, dimEnergy},
+		{"J/(mol*K)", "J/mol/K", 1, dim(2, 1, -2, 0, -1, -1)},
+		{"m*m", "m^2", 1, dimArea},
+		{"m^3/m", "m^2", 1, dimArea},
+		{"1/s", "1/s", 1, dimFrequency},
+		{"s^-1", "1/s", 1, dimFrequency},
+		{"cm^2", "cm^2", 1e-4, dimArea},
+	}
+	for _, test := range tests {
+		u, err := Parse(test.src)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.src, err)
+			continue
+		}
+		if u.String() != test.name || math.Abs(u.Factor-test.factor) > 1e-12*test.factor || u.Dim != test.dim {
+			t.Errorf("Parse(%q): expected %s (%g, %s), got %s (%g, %s)", test.src, test.name, test.factor, test.dim, u, u.Factor, u.Dim)
+		}
+	}
+
+	for _, src := range []string{"", "parsec", "m^", "m^x", "(m", "m)", "m+s"} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Expected error parsing %q", src)
+		}
+	}
+}
+
+func TestConversion(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		expected float64
+	}{
+		{1, "mi", "km", 1.609344},
+		{100, "degC", "degF", 212},
+		{32, "degF", "K", 273.15},
+		{1, "atm", "Pa", 101325},
+		{1, "gal", "L", 3.785411784},
+		{1, "kWh", "BTU", 3412.14163312794},
+	}
+	for _, test := range tests {
+		from, err := Parse(test.from)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", test.from, err)
+		}
+		to, err := Parse(test.to)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", test.to, err)
+		}
+		if !from.Compatible(to) {
+			t.Errorf("Expected %s and %s to be compatible", from, to)
+		}
+		if got := to.FromSI(from.ToSI(test.value)); math.Abs(got-test.expected) > 1e-9*math.Abs(test.expected) {
+			t.Errorf("%g %s to %s: expected %g, got %g", test.value, test.from, test.to, test.expected, got)
+		}
+	}
+}
+
+func TestDimensionString(t *testing.T) {
+	tests := []struct {
+		dim      Dimension
+		expected string
+	}{
+		{Dimension{}, "1"},
+		{dimEnergy, "kg*m^2/s^2"},
+		{dimFrequency, "1/s"},
+		{dimVoltage, "kg*m^2/s^3/A"},
+	}
+	for _, test := range tests {
+		if s := test.dim.String(); s != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, s)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	u, _ := Parse("m^2/s^4")
+	root, ok := u.Root(2)
+	if !ok || root.String() != "m/s^2" || root.Dim != dim(1, 0, -2) {
+		t.Errorf("Expected m/s^2, got %s (ok: %v)", root, ok)
+	}
+	if _, ok := u.Root(3); ok {
+		t.Error("Expected m^2/s^4 to have no cube root")
+	}
+}
+
+func TestUnits(t *testing.T) {
+	infos := Units()
+	if want := len(database) + len(Currencies()); len(infos) != want {
+		t.Fatalf("Expected %d units, got %d", want, len(infos))
+	}
+	for i := 1; i < len(infos); i++ {
+		a, b := infos[i-1].Unit, infos[i].Unit
+		if a.Dim == b.Dim && a.Factor > b.Factor {
+			t.Errorf("Expected %s before %s", b, a)
+		}
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	SetCurrencies(map[string]float64{"USD": 1, "EUR": 1.25, "m": 2, "XXX": 0})
+	defer SetCurrencies(nil)
+
+	if got := Currencies(); len(got) != 2 || got[0] != "EUR" || got[1] != "USD" {
+		t.Errorf("Expected EUR and USD, got %v", got)
+	}
+	eur, ok := Lookup("EUR")
+	if !ok || !eur.IsMoney() {
+		t.Fatalf("Expected EUR to be a currency, got %v (ok: %v)", eur, ok)
+	}
+	usd, _ := Lookup("USD")
+	if got := usd.FromSI(eur.ToSI(100)); got != 125 {
+		t.Errorf("Expected 100 EUR to be 125 USD, got %g", got)
+	}
+	if m, _ := Lookup("m"); m.IsMoney() {
+		t.Error("Expected a currency named m not to replace the metre")
+	}
+	for _, name := range []string{"kEUR", "XXX"} {
+		if IsUnit(name) {
+			t.Errorf("Expected %q not to be a unit", name)
+		}
+	}
+
+	SetCurrencies(nil)
+	if IsUnit("EUR") {
+		t.Error("Expected EUR to be removed")
+	}
+}